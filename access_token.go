@@ -0,0 +1,86 @@
+package go_tissue
+
+import (
+	"context"
+	"errors"
+	"html"
+	"net/http"
+	"net/url"
+	"regexp"
+)
+
+// AccessToken は個人用アクセストークン。Token は発行直後にだけ得られ、一覧では空になる。
+type AccessToken struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	Token string `json:"token,omitempty"`
+}
+
+var (
+	accessTokenIDPattern = regexp.MustCompile(`action="[^"]*/setting/tokens/([A-Za-z0-9]+)"`)
+	// 発行直後のページに一度だけ表示されるトークン (JWT、または "ID|文字列" 形式)
+	accessTokenValuePattern = regexp.MustCompile(`eyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+|\b\d+\|[A-Za-z0-9]{40,}`)
+)
+
+// ListAccessTokens は設定ページから個人用アクセストークンの一覧を取得する。
+func (c *Client) ListAccessTokens(ctx context.Context) ([]AccessToken, error) {
+	page, err := c.getPage(ctx, "/setting/tokens")
+	if err != nil {
+		return nil, err
+	}
+	return parseAccessTokens(page), nil
+}
+
+// CreateAccessToken は name という名前の個人用アクセストークンを発行し、トークンを含めて返す。
+func (c *Client) CreateAccessToken(ctx context.Context, name string) (*AccessToken, error) {
+	if name == "" {
+		return nil, errors.New("name is required")
+	}
+	before, err := c.ListAccessTokens(ctx)
+	if err != nil {
+		return nil, err
+	}
+	exists := map[string]bool{}
+	for _, t := range before {
+		exists[t.ID] = true
+	}
+	page, err := c.submitForm(ctx, http.MethodPost, "/setting/tokens", url.Values{"name": {name}})
+	if err != nil {
+		return nil, err
+	}
+	value := accessTokenValuePattern.FindString(page)
+	if value == "" {
+		return nil, errors.New("issued access token not found in the settings page")
+	}
+	result := &AccessToken{Name: name, Token: html.UnescapeString(value)}
+	for _, t := range parseAccessTokens(page) {
+		if !exists[t.ID] {
+			result.ID = t.ID
+			break
+		}
+	}
+	return result, nil
+}
+
+// RevokeAccessToken は個人用アクセストークンを失効させる。
+func (c *Client) RevokeAccessToken(ctx context.Context, id string) error {
+	if id == "" {
+		return errors.New("id is required")
+	}
+	_, err := c.submitForm(ctx, http.MethodDelete, "/setting/tokens/"+url.PathEscape(id), nil)
+	return err
+}
+
+func parseAccessTokens(page string) []AccessToken {
+	names := dataNames(page)
+	tokens := []AccessToken{}
+	seen := map[string]bool{}
+	for _, m := range accessTokenIDPattern.FindAllStringSubmatch(page, -1) {
+		if seen[m[1]] {
+			continue
+		}
+		seen[m[1]] = true
+		tokens = append(tokens, AccessToken{ID: m[1], Name: names[m[1]]})
+	}
+	return tokens
+}
@@ -0,0 +1,76 @@
+package go_tissue
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// serveAccessTokens は個人用アクセストークンの設定画面を f に足す。
+func serveAccessTokens(f *fakeTissue) {
+	var mu sync.Mutex
+	var tokens []string
+	f.handle("/setting/tokens", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if r.Method == http.MethodPost {
+			tokens = append(tokens, fmt.Sprintf("tok%d:%s", len(tokens)+1, r.FormValue("name")))
+			// 実際のサイトはリダイレクト先で一度だけトークンを表示する
+			fmt.Fprintf(w, `<input value="%d|%s" readonly>`, len(tokens), strings.Repeat("x", 40))
+		}
+		for _, token := range tokens {
+			id, name, _ := strings.Cut(token, ":")
+			fmt.Fprintf(w, `<form action="%s/setting/tokens/%s" method="POST"><button data-id="%s" data-name="%s">削除</button></form>`,
+				f.URL, id, id, html.EscapeString(name))
+		}
+	})
+	f.handle("/setting/tokens/", func(w http.ResponseWriter, r *http.Request) {
+		id := strings.TrimPrefix(r.URL.Path, "/setting/tokens/")
+		mu.Lock()
+		defer mu.Unlock()
+		for i, token := range tokens {
+			if strings.HasPrefix(token, id+":") && r.Method == http.MethodDelete {
+				tokens = append(tokens[:i], tokens[i+1:]...)
+				return
+			}
+		}
+		http.NotFound(w, r)
+	})
+}
+
+func TestClient_AccessTokens(t *testing.T) {
+	f := newFakeTissue(t)
+	serveAccessTokens(f)
+	client := newFakeClient(t, f, "secret")
+	ctx := context.Background()
+
+	token, err := client.CreateAccessToken(ctx, "cli")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token.ID != "tok1" || token.Name != "cli" || token.Token != "1|"+strings.Repeat("x", 40) {
+		t.Errorf("unexpected token: %+v", token)
+	}
+
+	tokens, err := client.ListAccessTokens(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tokens) != 1 || tokens[0] != (AccessToken{ID: "tok1", Name: "cli"}) {
+		t.Errorf("unexpected tokens: %+v", tokens)
+	}
+
+	if err := client.RevokeAccessToken(ctx, token.ID); err != nil {
+		t.Fatal(err)
+	}
+	if tokens, err := client.ListAccessTokens(ctx); err != nil || len(tokens) != 0 {
+		t.Errorf("token not revoked: %+v %v", tokens, err)
+	}
+	if err := client.RevokeAccessToken(ctx, token.ID); !IsNotFound(err) {
+		t.Errorf("expected not found, got %v", err)
+	}
+}
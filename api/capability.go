@@ -0,0 +1,79 @@
+package api
+
+import (
+	"context"
+
+	tissue "github.com/mohemohe/go-tissue"
+)
+
+const BackendToken = "token"
+
+// endpoints は API トークン版が実装している操作と、その確認先 (/api からの相対パス)。
+// /v1 以下は認証必須なので、未認証の問い合わせはユーザーやリソースの有無にかかわらず 401 になる。
+var endpoints = []tissue.ProbeEndpoint{
+	{Operation: tissue.OpMe, Path: "/v1/me"},
+	{Operation: tissue.OpUser, Path: "/v1/users/_"},
+	{Operation: tissue.OpUserCheckins, Path: "/v1/users/_/checkins"},
+	{Operation: tissue.OpUserLikes, Path: "/v1/users/_/likes"},
+	{Operation: tissue.OpCollectionList, Path: "/v1/users/_/collections"},
+	{Operation: tissue.OpDailyStats, Path: "/v1/users/_/stats/checkin/daily"},
+	{Operation: tissue.OpHourlyStats, Path: "/v1/users/_/stats/checkin/hourly"},
+	{Operation: tissue.OpTagStats, Path: "/v1/users/_/stats/tags"},
+	{Operation: tissue.OpLinkStats, Path: "/v1/users/_/stats/links"},
+	{Operation: tissue.OpCheckinCreate, Path: "/v1/checkins"},
+	{Operation: tissue.OpCheckinGet, Path: "/v1/checkins/0"},
+	{Operation: tissue.OpCheckinUpdate, Path: "/v1/checkins/0"},
+	{Operation: tissue.OpCheckinDelete, Path: "/v1/checkins/0"},
+	{Operation: tissue.OpCollectionCreate, Path: "/v1/collections"},
+	{Operation: tissue.OpCollectionUpdate, Path: "/v1/collections/0"},
+	{Operation: tissue.OpCollectionDelete, Path: "/v1/collections/0"},
+	{Operation: tissue.OpCollectionItemList, Path: "/v1/collections/0/items"},
+	{Operation: tissue.OpCollectionItemCreate, Path: "/v1/collections/0/items"},
+	{Operation: tissue.OpCollectionItemUpdate, Path: "/v1/collections/0/items/0"},
+	{Operation: tissue.OpCollectionItemDelete, Path: "/v1/collections/0/items/0"},
+	{Operation: tissue.OpSearchCheckins, Path: "/v1/search/checkins"},
+	{Operation: tissue.OpSearchCollections, Path: "/v1/search/collections"},
+	{Operation: tissue.OpWebhookCheckin, Path: "/webhooks/checkin/_"},
+}
+
+// SupportedOperations は API トークン版が実装している操作を返す。接続先での有無は確認しない。
+func SupportedOperations() []tissue.Operation {
+	ops := make([]tissue.Operation, len(endpoints))
+	for i, e := range endpoints {
+		ops[i] = e.Operation
+	}
+	return ops
+}
+
+// Probe は baseURL のインスタンスで API トークン版の各操作が使えるかを問い合わせる。トークンは送らない。
+func Probe(ctx context.Context, baseURL string) (*tissue.Capabilities, error) {
+	c, err := NewClient(&ClientOption{BaseURL: baseURL})
+	if err != nil {
+		return nil, err
+	}
+	return c.probe(ctx)
+}
+
+// Capabilities は接続先インスタンスでこのクライアントが使える操作を返す。結果はクライアントごとにキャッシュされる。
+func (c *Client) Capabilities(ctx context.Context) (*tissue.Capabilities, error) {
+	c.capMu.Lock()
+	defer c.capMu.Unlock()
+	if c.capabilities != nil {
+		return c.capabilities, nil
+	}
+	caps, err := c.probe(ctx)
+	if err != nil {
+		return nil, err
+	}
+	c.capabilities = caps
+	return caps, nil
+}
+
+func (c *Client) probe(ctx context.Context) (*tissue.Capabilities, error) {
+	caps, err := tissue.ProbeEndpoints(ctx, nil, BackendToken, c.baseURL.String(), endpoints)
+	if err != nil {
+		return nil, err
+	}
+	caps.BaseURL = c.option.BaseURL
+	return caps, nil
+}
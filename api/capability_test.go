@@ -0,0 +1,58 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	tissue "github.com/mohemohe/go-tissue"
+)
+
+func TestClient_Capabilities(t *testing.T) {
+	var probes atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		probes.Add(1)
+		if r.Header.Get("Authorization") != "" {
+			t.Error("probe must not send the access token")
+		}
+		// 古いインスタンスを模して、コレクション検索とリンク統計が無いものとする
+		if r.URL.Path == "/api/v1/search/collections" || strings.HasSuffix(r.URL.Path, "/stats/links") {
+			http.NotFound(w, r)
+			return
+		}
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	client, err := NewClient(&ClientOption{BaseURL: server.URL, AccessToken: "token"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	caps, err := client.Capabilities(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if caps.Backend != BackendToken || caps.BaseURL != server.URL {
+		t.Errorf("unexpected capabilities: %+v", caps)
+	}
+	if !caps.Has(tissue.OpCheckinGet) || !caps.Has(tissue.OpHourlyStats) {
+		t.Error("token API operations should be available")
+	}
+	if caps.Has(tissue.OpSearchCollections) || caps.Has(tissue.OpLinkStats) {
+		t.Error("missing endpoints should not be available")
+	}
+	if caps.Has(tissue.OpRecentTags) {
+		t.Error("recent tags is not provided by the token API")
+	}
+
+	n := probes.Load()
+	if _, err := client.Capabilities(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if probes.Load() != n {
+		t.Error("capabilities should be cached")
+	}
+}
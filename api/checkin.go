@@ -10,29 +10,51 @@ import (
 )
 
 type CreateCheckinOption struct {
-	CheckedInAt        *time.Time `json:"checked_in_at,omitempty"`
-	Tags               []string   `json:"tags,omitempty"`
-	Link               string     `json:"link,omitempty"`
-	Note               string     `json:"note,omitempty"`
-	IsPrivate          bool       `json:"is_private"`
-	IsTooSensitive     bool       `json:"is_too_sensitive"`
-	DiscardElapsedTime bool       `json:"discard_elapsed_time"`
+	CheckedInAt        *tissue.Timestamp `json:"checked_in_at,omitempty"`
+	Tags               []string          `json:"tags,omitempty"`
+	Link               string            `json:"link,omitempty"`
+	Note               string            `json:"note,omitempty"`
+	IsPrivate          bool              `json:"is_private"`
+	IsTooSensitive     bool              `json:"is_too_sensitive"`
+	DiscardElapsedTime bool              `json:"discard_elapsed_time"`
 }
 
 type UpdateCheckinOption struct {
-	CheckedInAt        *time.Time `json:"checked_in_at,omitempty"`
-	Tags               *[]string  `json:"tags,omitempty"`
-	Link               *string    `json:"link,omitempty"`
-	Note               *string    `json:"note,omitempty"`
-	IsPrivate          *bool      `json:"is_private,omitempty"`
-	IsTooSensitive     *bool      `json:"is_too_sensitive,omitempty"`
-	DiscardElapsedTime *bool      `json:"discard_elapsed_time,omitempty"`
+	CheckedInAt        *tissue.Timestamp `json:"checked_in_at,omitempty"`
+	Tags               *[]string         `json:"tags,omitempty"`
+	Link               *string           `json:"link,omitempty"`
+	Note               *string           `json:"note,omitempty"`
+	IsPrivate          *bool             `json:"is_private,omitempty"`
+	IsTooSensitive     *bool             `json:"is_too_sensitive,omitempty"`
+	DiscardElapsedTime *bool             `json:"discard_elapsed_time,omitempty"`
 }
 
 func (c *Client) CreateCheckin(ctx context.Context, option *CreateCheckinOption) (*tissue.Checkin, error) {
 	if option == nil {
 		option = &CreateCheckinOption{}
 	}
+	o := *option
+	tags, err := tissue.NormalizeTags(c.option.TagNormalizer, o.Tags)
+	if err != nil {
+		return nil, err
+	}
+	o.Tags = tags
+	if o.Link, err = tissue.CanonicalizeLink(c.option.LinkCanonicalizer, o.Link); err != nil {
+		return nil, err
+	}
+	if err := c.validate(&o); err != nil {
+		return nil, err
+	}
+	if g := c.option.DuplicateGuard; g != nil {
+		candidate := tissue.DuplicateCandidate{CheckedInAt: time.Now(), Link: o.Link, Tags: o.Tags}
+		if o.CheckedInAt != nil {
+			candidate.CheckedInAt = o.CheckedInAt.Time
+		}
+		if err := g.CheckCheckin(ctx, duplicateSource{c}, candidate); err != nil {
+			return nil, err
+		}
+	}
+	option = &o
 	result := &tissue.Checkin{}
 	if err := c.sendJSON(ctx, http.MethodPost, "/v1/checkins", option, result); err != nil {
 		return nil, err
@@ -52,6 +74,19 @@ func (c *Client) UpdateCheckin(ctx context.Context, id int64, option *UpdateChec
 	if option == nil {
 		option = &UpdateCheckinOption{}
 	}
+	o := *option
+	tags, err := tissue.NormalizeTagsPtr(c.option.TagNormalizer, o.Tags)
+	if err != nil {
+		return nil, err
+	}
+	o.Tags = tags
+	if o.Link, err = tissue.CanonicalizeLinkPtr(c.option.LinkCanonicalizer, o.Link); err != nil {
+		return nil, err
+	}
+	if err := c.validate(&o); err != nil {
+		return nil, err
+	}
+	option = &o
 	result := &tissue.Checkin{}
 	if err := c.sendJSON(ctx, http.MethodPatch, "/v1/checkins/"+strconv.FormatInt(id, 10), option, result); err != nil {
 		return nil, err
@@ -5,24 +5,38 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
-	"fmt"
 	"io"
 	"net/http"
 	"net/url"
 	"path"
-	"strings"
+	"sync"
+
+	tissue "github.com/mohemohe/go-tissue"
 )
 
 type ClientOption struct {
 	BaseURL     string
 	WebhookID   string
 	AccessToken string
+	// Filter は UserCheckins / UserLikes / SearchCheckins の結果に適用される。
+	Filter tissue.CheckinFilter
+	// TagNormalizer はチェックイン・コレクションアイテムの作成・更新で送るタグに適用される。
+	TagNormalizer tissue.TagNormalizer
+	// LinkCanonicalizer はチェックイン・コレクションアイテムの作成・更新で送るリンクに適用される。
+	LinkCanonicalizer tissue.LinkCanonicalizer
+	// DuplicateGuard はチェックイン・コレクションアイテムの作成の前に重複を調べる。
+	DuplicateGuard tissue.DuplicateGuard
+	// SkipValidation が true なら、作成・更新の前に Validate() を呼ばずにそのまま送る。
+	SkipValidation bool
 }
 
 type Client struct {
 	option     *ClientOption
 	httpClient *http.Client
 	baseURL    *url.URL
+
+	capMu        sync.Mutex
+	capabilities *tissue.Capabilities
 }
 
 func NewClient(option *ClientOption) (*Client, error) {
@@ -124,9 +138,5 @@ func (c *Client) deleteRequest(ctx context.Context, spath string) error {
 }
 
 func readErrorResponse(res *http.Response) error {
-	b, _ := io.ReadAll(res.Body)
-	if len(b) > 0 {
-		return fmt.Errorf("unexpected status %s: %s", res.Status, strings.TrimSpace(string(b)))
-	}
-	return fmt.Errorf("unexpected status %s", res.Status)
+	return tissue.NewStatusError(res)
 }
@@ -19,6 +19,12 @@ type UpdateCollectionOption struct {
 }
 
 func (c *Client) CreateCollection(ctx context.Context, option *CreateCollectionOption) (*tissue.Collection, error) {
+	if option == nil {
+		option = &CreateCollectionOption{}
+	}
+	if err := c.validate(option); err != nil {
+		return nil, err
+	}
 	result := &tissue.Collection{}
 	if err := c.sendJSON(ctx, http.MethodPost, "/v1/collections", option, result); err != nil {
 		return nil, err
@@ -35,6 +41,12 @@ func (c *Client) GetCollection(ctx context.Context, id int64) (*tissue.Collectio
 }
 
 func (c *Client) UpdateCollection(ctx context.Context, id int64, option *UpdateCollectionOption) (*tissue.Collection, error) {
+	if option == nil {
+		option = &UpdateCollectionOption{}
+	}
+	if err := c.validate(option); err != nil {
+		return nil, err
+	}
 	result := &tissue.Collection{}
 	if err := c.sendJSON(ctx, http.MethodPut, "/v1/collections/"+strconv.FormatInt(id, 10), option, result); err != nil {
 		return nil, err
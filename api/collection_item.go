@@ -30,6 +30,27 @@ func (c *Client) ListCollectionItems(ctx context.Context, collectionID int64, op
 }
 
 func (c *Client) CreateCollectionItem(ctx context.Context, collectionID int64, option *CreateCollectionItemOption) (*tissue.CollectionItem, error) {
+	if option == nil {
+		option = &CreateCollectionItemOption{}
+	}
+	o := *option
+	tags, err := tissue.NormalizeTags(c.option.TagNormalizer, o.Tags)
+	if err != nil {
+		return nil, err
+	}
+	o.Tags = tags
+	if o.Link, err = tissue.CanonicalizeLink(c.option.LinkCanonicalizer, o.Link); err != nil {
+		return nil, err
+	}
+	if err := c.validate(&o); err != nil {
+		return nil, err
+	}
+	if g := c.option.DuplicateGuard; g != nil {
+		if err := g.CheckCollectionItem(ctx, duplicateSource{c}, collectionID, o.Link); err != nil {
+			return nil, err
+		}
+	}
+	option = &o
 	result := &tissue.CollectionItem{}
 	path := "/v1/collections/" + strconv.FormatInt(collectionID, 10) + "/items"
 	if err := c.sendJSON(ctx, http.MethodPost, path, option, result); err != nil {
@@ -39,6 +60,19 @@ func (c *Client) CreateCollectionItem(ctx context.Context, collectionID int64, o
 }
 
 func (c *Client) UpdateCollectionItem(ctx context.Context, collectionID, itemID int64, option *UpdateCollectionItemOption) (*tissue.CollectionItem, error) {
+	if option == nil {
+		option = &UpdateCollectionItemOption{}
+	}
+	o := *option
+	tags, err := tissue.NormalizeTagsPtr(c.option.TagNormalizer, o.Tags)
+	if err != nil {
+		return nil, err
+	}
+	o.Tags = tags
+	if err := c.validate(&o); err != nil {
+		return nil, err
+	}
+	option = &o
 	result := &tissue.CollectionItem{}
 	path := "/v1/collections/" + strconv.FormatInt(collectionID, 10) + "/items/" + strconv.FormatInt(itemID, 10)
 	if err := c.sendJSON(ctx, http.MethodPatch, path, option, result); err != nil {
@@ -0,0 +1,34 @@
+package api
+
+import (
+	"context"
+
+	tissue "github.com/mohemohe/go-tissue"
+)
+
+// duplicateSource は Client の tissue.DuplicateSource。
+type duplicateSource struct {
+	c *Client
+}
+
+func (s duplicateSource) RecentCheckins(ctx context.Context) ([]tissue.Checkin, error) {
+	me, err := s.c.Me(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return s.c.UserCheckins(ctx, me.Name, &UserCheckinsOption{Page: 1, Unfiltered: true})
+}
+
+func (s duplicateSource) CollectionItems(ctx context.Context, collectionID int64) ([]tissue.CollectionItem, error) {
+	var result []tissue.CollectionItem
+	for page := 1; ; page++ {
+		items, err := s.c.ListCollectionItems(ctx, collectionID, &PageOption{Page: page, PerPage: 100})
+		if err != nil {
+			return nil, err
+		}
+		if len(items) == 0 {
+			return result, nil
+		}
+		result = append(result, items...)
+	}
+}
@@ -0,0 +1,441 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	tissue "github.com/mohemohe/go-tissue"
+)
+
+const BackendHybrid = "hybrid"
+
+type HybridClientOption struct {
+	BaseURL     string
+	AccessToken string
+	Email       string
+	Password    string
+	// Filter は両方のクライアントの一覧系メソッドに適用される。
+	Filter tissue.CheckinFilter
+	// TagNormalizer は両方のクライアントの作成・更新系メソッドに適用される。
+	TagNormalizer tissue.TagNormalizer
+	// LinkCanonicalizer は両方のクライアントの作成・更新系メソッドに適用される。
+	LinkCanonicalizer tissue.LinkCanonicalizer
+	// DuplicateGuard は両方のクライアントの作成系メソッドに適用される。
+	DuplicateGuard tissue.DuplicateGuard
+	// SkipValidation は両方のクライアントの作成・更新系メソッドに適用される。
+	SkipValidation bool
+}
+
+// HybridClient は API トークン版とスクレイピング版の両方を使い、両者の操作の和集合を提供する。
+// 両方が実装している操作は API トークン版を優先し、接続先にエンドポイントが無い場合だけスクレイピング版で実行する。
+type HybridClient struct {
+	token    *Client
+	scraping *tissue.Client
+}
+
+func NewHybridClient(option *HybridClientOption) (*HybridClient, error) {
+	if option == nil {
+		return nil, errors.New("option is required")
+	}
+	if option.AccessToken == "" || option.Email == "" || option.Password == "" {
+		return nil, errors.New("access token, email and password are required")
+	}
+	token, err := NewClient(&ClientOption{BaseURL: option.BaseURL, AccessToken: option.AccessToken, Filter: option.Filter, TagNormalizer: option.TagNormalizer, LinkCanonicalizer: option.LinkCanonicalizer, DuplicateGuard: option.DuplicateGuard, SkipValidation: option.SkipValidation})
+	if err != nil {
+		return nil, err
+	}
+	scraping, err := tissue.NewClient(&tissue.ClientOption{BaseURL: option.BaseURL, Email: option.Email, Password: option.Password, Filter: option.Filter, TagNormalizer: option.TagNormalizer, LinkCanonicalizer: option.LinkCanonicalizer, DuplicateGuard: option.DuplicateGuard, SkipValidation: option.SkipValidation})
+	if err != nil {
+		return nil, err
+	}
+	return &HybridClient{token: token, scraping: scraping}, nil
+}
+
+// Token は内部の API トークン版クライアントを返す。
+func (c *HybridClient) Token() *Client {
+	return c.token
+}
+
+// Scraping は内部のスクレイピング版クライアントを返す。
+func (c *HybridClient) Scraping() *tissue.Client {
+	return c.scraping
+}
+
+// fallback は API トークン版の呼び出しが、接続先に op のエンドポイントが無いために失敗したかを返す。
+// 404 でもエンドポイント自体が存在する場合 (対象のリソースが無い場合) は false。
+func (c *HybridClient) fallback(ctx context.Context, op tissue.Operation, err error) bool {
+	if !tissue.IsNotFound(err) {
+		return false
+	}
+	caps, cerr := c.token.Capabilities(ctx)
+	return cerr == nil && !caps.Has(op)
+}
+
+func errScrapingUnsupported(op tissue.Operation, what string) error {
+	return fmt.Errorf("%s: the token API endpoint is unavailable and the scraping client does not support %s", op, what)
+}
+
+// HybridSupportedOperations は HybridClient が実装している操作を返す。
+func HybridSupportedOperations() []tissue.Operation {
+	seen := map[tissue.Operation]bool{}
+	var ops []tissue.Operation
+	for _, op := range append(SupportedOperations(), tissue.SupportedOperations()...) {
+		if op != tissue.OpWebhookCheckin && !seen[op] {
+			seen[op] = true
+			ops = append(ops, op)
+		}
+	}
+	return ops
+}
+
+// Capabilities は両方のクライアントの Capabilities を合わせたものを返す。
+func (c *HybridClient) Capabilities(ctx context.Context) (*tissue.Capabilities, error) {
+	token, err := c.token.Capabilities(ctx)
+	if err != nil {
+		return nil, err
+	}
+	scraping, err := c.scraping.Capabilities(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return mergeCapabilities(token, scraping), nil
+}
+
+// ProbeHybrid は認証せずに baseURL のインスタンスを調べ、HybridClient で使える操作を返す。
+func ProbeHybrid(ctx context.Context, baseURL string) (*tissue.Capabilities, error) {
+	token, err := Probe(ctx, baseURL)
+	if err != nil {
+		return nil, err
+	}
+	scraping, err := tissue.Probe(ctx, baseURL)
+	if err != nil {
+		return nil, err
+	}
+	return mergeCapabilities(token, scraping), nil
+}
+
+func mergeCapabilities(token, scraping *tissue.Capabilities) *tissue.Capabilities {
+	var ops []tissue.Operation
+	for _, op := range HybridSupportedOperations() {
+		if token.Has(op) || scraping.Has(op) {
+			ops = append(ops, op)
+		}
+	}
+	return tissue.NewCapabilities(BackendHybrid, token.BaseURL, ops)
+}
+
+func (c *HybridClient) Me(ctx context.Context) (*tissue.Me, error) {
+	result, err := c.token.Me(ctx)
+	if err != nil && c.fallback(ctx, tissue.OpMe, err) {
+		return c.scraping.Me(ctx)
+	}
+	return result, err
+}
+
+func (c *HybridClient) GetUser(ctx context.Context, name string) (*tissue.User, error) {
+	return c.token.GetUser(ctx, name)
+}
+
+func (c *HybridClient) UserCheckins(ctx context.Context, name string, option *UserCheckinsOption) ([]tissue.Checkin, error) {
+	result, err := c.token.UserCheckins(ctx, name, option)
+	if err == nil || !c.fallback(ctx, tissue.OpUserCheckins, err) {
+		return result, err
+	}
+	o := &tissue.UserCheckinsOption{}
+	if option != nil {
+		if !option.Since.IsZero() || !option.Until.IsZero() || option.Order != "" {
+			return nil, errScrapingUnsupported(tissue.OpUserCheckins, "since/until/order")
+		}
+		o.Page, o.PerPage, o.HasLink, o.Unfiltered = option.Page, option.PerPage, option.HasLink, option.Unfiltered
+	}
+	checkins, err := c.scraping.UserCheckins(ctx, name, o)
+	if err != nil {
+		return nil, err
+	}
+	result = make([]tissue.Checkin, len(checkins))
+	for i, ch := range checkins {
+		result[i] = ch.Checkin
+	}
+	return result, nil
+}
+
+func (c *HybridClient) UserLikes(ctx context.Context, name string, option *PageOption) ([]tissue.Checkin, error) {
+	return c.token.UserLikes(ctx, name, option)
+}
+
+func (c *HybridClient) UserCollections(ctx context.Context, name string, option *PageOption) ([]tissue.Collection, error) {
+	return c.token.UserCollections(ctx, name, option)
+}
+
+// ListCollections は自分のコレクション一覧を返す。
+func (c *HybridClient) ListCollections(ctx context.Context, option *PageOption) ([]tissue.Collection, error) {
+	me, err := c.Me(ctx)
+	if err != nil {
+		return nil, err
+	}
+	result, err := c.token.UserCollections(ctx, me.Name, option)
+	if err == nil || !c.fallback(ctx, tissue.OpCollectionList, err) {
+		return result, err
+	}
+	o := &tissue.ListCollectionsOption{}
+	if option != nil {
+		o.Page, o.PerPage = option.Page, option.PerPage
+	}
+	return c.scraping.ListCollections(ctx, o)
+}
+
+func (c *HybridClient) UserDailyCheckinStats(ctx context.Context, name string, option *UserStatsPeriodOption) ([]tissue.DailyCheckinCount, error) {
+	result, err := c.token.UserDailyCheckinStats(ctx, name, option)
+	if err == nil || !c.fallback(ctx, tissue.OpDailyStats, err) {
+		return result, err
+	}
+	o := &tissue.UserDailyCheckinStatsOption{}
+	if option != nil {
+		o.Since, o.Until = option.Since, option.Until
+	}
+	return c.scraping.UserDailyCheckinStats(ctx, name, o)
+}
+
+func (c *HybridClient) UserHourlyCheckinStats(ctx context.Context, name string, option *UserStatsPeriodOption) ([]HourlyCheckinSummary, error) {
+	return c.token.UserHourlyCheckinStats(ctx, name, option)
+}
+
+func (c *HybridClient) UserTagStats(ctx context.Context, name string, option *UserStatsPeriodOption) ([]tissue.TagCount, error) {
+	result, err := c.token.UserTagStats(ctx, name, option)
+	if err == nil || !c.fallback(ctx, tissue.OpTagStats, err) {
+		return result, err
+	}
+	if option != nil && (!option.Since.IsZero() || !option.Until.IsZero()) {
+		return nil, errScrapingUnsupported(tissue.OpTagStats, "since/until")
+	}
+	return c.scraping.UserTagStats(ctx, name)
+}
+
+func (c *HybridClient) UserLinkStats(ctx context.Context, name string, option *UserStatsPeriodOption) ([]LinkCount, error) {
+	return c.token.UserLinkStats(ctx, name, option)
+}
+
+// DailyCheckinStats はサイト全体の日次統計を返す。スクレイピング版のみ。
+func (c *HybridClient) DailyCheckinStats(ctx context.Context) ([]tissue.DailyCheckinCount, error) {
+	return c.scraping.DailyCheckinStats(ctx)
+}
+
+// RecentTags は最近使用したタグを返す。スクレイピング版のみ。
+func (c *HybridClient) RecentTags(ctx context.Context) ([]string, error) {
+	return c.scraping.RecentTags(ctx)
+}
+
+// LatestInformation はサイトのお知らせを返す。スクレイピング版のみ。
+func (c *HybridClient) LatestInformation(ctx context.Context) ([]tissue.Information, error) {
+	return c.scraping.LatestInformation(ctx)
+}
+
+func (c *HybridClient) CreateCheckin(ctx context.Context, option *CreateCheckinOption) (*tissue.Checkin, error) {
+	result, err := c.token.CreateCheckin(ctx, option)
+	if err == nil || !c.fallback(ctx, tissue.OpCheckinCreate, err) {
+		return result, err
+	}
+	o := &tissue.CreateCheckinOption{}
+	if option != nil {
+		*o = tissue.CreateCheckinOption{
+			CheckedInAt:        option.CheckedInAt,
+			Tags:               option.Tags,
+			Link:               option.Link,
+			Note:               option.Note,
+			IsPrivate:          option.IsPrivate,
+			IsTooSensitive:     option.IsTooSensitive,
+			DiscardElapsedTime: option.DiscardElapsedTime,
+		}
+	}
+	return c.scraping.CreateCheckin(ctx, o)
+}
+
+func (c *HybridClient) GetCheckin(ctx context.Context, id int64) (*tissue.Checkin, error) {
+	return c.token.GetCheckin(ctx, id)
+}
+
+func (c *HybridClient) UpdateCheckin(ctx context.Context, id int64, option *UpdateCheckinOption) (*tissue.Checkin, error) {
+	return c.token.UpdateCheckin(ctx, id, option)
+}
+
+func (c *HybridClient) DeleteCheckin(ctx context.Context, id int64) error {
+	return c.token.DeleteCheckin(ctx, id)
+}
+
+// Like はチェックインにいいねする。スクレイピング版のみ。
+func (c *HybridClient) Like(ctx context.Context, checkinID int64) (*tissue.Checkin, error) {
+	return c.scraping.Like(ctx, checkinID)
+}
+
+// Unlike はチェックインのいいねを取り消す。スクレイピング版のみ。
+func (c *HybridClient) Unlike(ctx context.Context, checkinID int64) (*tissue.Checkin, error) {
+	return c.scraping.Unlike(ctx, checkinID)
+}
+
+// LikedBy はチェックインにいいねしたユーザーを返す。スクレイピング版のみ。
+func (c *HybridClient) LikedBy(ctx context.Context, checkinID int64) ([]tissue.User, error) {
+	return c.scraping.LikedBy(ctx, checkinID)
+}
+
+// ListWebhooks は Webhook の一覧を返す。スクレイピング版のみ。
+func (c *HybridClient) ListWebhooks(ctx context.Context) ([]tissue.Webhook, error) {
+	return c.scraping.ListWebhooks(ctx)
+}
+
+// CreateWebhook は Webhook を発行する。スクレイピング版のみ。
+func (c *HybridClient) CreateWebhook(ctx context.Context, name string) (*tissue.Webhook, error) {
+	return c.scraping.CreateWebhook(ctx, name)
+}
+
+// DeleteWebhook は Webhook を削除する。スクレイピング版のみ。
+func (c *HybridClient) DeleteWebhook(ctx context.Context, id string) error {
+	return c.scraping.DeleteWebhook(ctx, id)
+}
+
+// ListAccessTokens は個人用アクセストークンの一覧を返す。スクレイピング版のみ。
+func (c *HybridClient) ListAccessTokens(ctx context.Context) ([]tissue.AccessToken, error) {
+	return c.scraping.ListAccessTokens(ctx)
+}
+
+// CreateAccessToken は個人用アクセストークンを発行する。スクレイピング版のみ。
+func (c *HybridClient) CreateAccessToken(ctx context.Context, name string) (*tissue.AccessToken, error) {
+	return c.scraping.CreateAccessToken(ctx, name)
+}
+
+// RevokeAccessToken は個人用アクセストークンを失効させる。スクレイピング版のみ。
+func (c *HybridClient) RevokeAccessToken(ctx context.Context, id string) error {
+	return c.scraping.RevokeAccessToken(ctx, id)
+}
+
+// UpdateProfile はプロフィール設定を変更する。スクレイピング版のみ。
+func (c *HybridClient) UpdateProfile(ctx context.Context, option *tissue.UpdateProfileOption) (*tissue.User, error) {
+	return c.scraping.UpdateProfile(ctx, option)
+}
+
+// UpdatePrivacy はプライバシー設定を変更する。スクレイピング版のみ。
+func (c *HybridClient) UpdatePrivacy(ctx context.Context, option *tissue.UpdatePrivacyOption) (*tissue.User, error) {
+	return c.scraping.UpdatePrivacy(ctx, option)
+}
+
+// ExportCheckinsCSV はサイトのデータエクスポートを w に書き出す。スクレイピング版のみ。
+func (c *HybridClient) ExportCheckinsCSV(ctx context.Context, w io.Writer) error {
+	return c.scraping.ExportCheckinsCSV(ctx, w)
+}
+
+// ImportCheckinsCSV はサイトの CSV インポートを行う。スクレイピング版のみ。
+func (c *HybridClient) ImportCheckinsCSV(ctx context.Context, r io.Reader) (*tissue.CSVImportResult, error) {
+	return c.scraping.ImportCheckinsCSV(ctx, r)
+}
+
+// ListTagMutes はタグのミュートの一覧を返す。スクレイピング版のみ。
+func (c *HybridClient) ListTagMutes(ctx context.Context) ([]tissue.TagMute, error) {
+	return c.scraping.ListTagMutes(ctx)
+}
+
+// MuteTag はタグをミュートに登録する。スクレイピング版のみ。
+func (c *HybridClient) MuteTag(ctx context.Context, tag string) (*tissue.TagMute, error) {
+	return c.scraping.MuteTag(ctx, tag)
+}
+
+// UnmuteTag はタグのミュートを解除する。スクレイピング版のみ。
+func (c *HybridClient) UnmuteTag(ctx context.Context, id string) error {
+	return c.scraping.UnmuteTag(ctx, id)
+}
+
+func (c *HybridClient) CreateCollection(ctx context.Context, option *CreateCollectionOption) (*tissue.Collection, error) {
+	result, err := c.token.CreateCollection(ctx, option)
+	if err == nil || !c.fallback(ctx, tissue.OpCollectionCreate, err) {
+		return result, err
+	}
+	o := &tissue.CreateCollectionOption{}
+	if option != nil {
+		o.Title, o.IsPrivate = option.Title, option.IsPrivate
+	}
+	return c.scraping.CreateCollection(ctx, o)
+}
+
+func (c *HybridClient) GetCollection(ctx context.Context, id int64) (*tissue.Collection, error) {
+	return c.token.GetCollection(ctx, id)
+}
+
+func (c *HybridClient) UpdateCollection(ctx context.Context, id int64, option *UpdateCollectionOption) (*tissue.Collection, error) {
+	result, err := c.token.UpdateCollection(ctx, id, option)
+	if err == nil || !c.fallback(ctx, tissue.OpCollectionUpdate, err) {
+		return result, err
+	}
+	o := &tissue.UpdateCollectionOption{ID: id}
+	if option != nil {
+		o.Title, o.IsPrivate = option.Title, option.IsPrivate
+	}
+	return c.scraping.UpdateCollection(ctx, o)
+}
+
+func (c *HybridClient) DeleteCollection(ctx context.Context, id int64) error {
+	err := c.token.DeleteCollection(ctx, id)
+	if err == nil || !c.fallback(ctx, tissue.OpCollectionDelete, err) {
+		return err
+	}
+	return c.scraping.DeleteCollection(ctx, id)
+}
+
+func (c *HybridClient) ListCollectionItems(ctx context.Context, collectionID int64, option *PageOption) ([]tissue.CollectionItem, error) {
+	result, err := c.token.ListCollectionItems(ctx, collectionID, option)
+	if err == nil || !c.fallback(ctx, tissue.OpCollectionItemList, err) {
+		return result, err
+	}
+	o := &tissue.ListCollectionItemsOption{CollectionID: collectionID}
+	if option != nil {
+		o.Page, o.PerPage = option.Page, option.PerPage
+	}
+	return c.scraping.ListCollectionItems(ctx, o)
+}
+
+func (c *HybridClient) CreateCollectionItem(ctx context.Context, collectionID int64, option *CreateCollectionItemOption) (*tissue.CollectionItem, error) {
+	result, err := c.token.CreateCollectionItem(ctx, collectionID, option)
+	if err == nil || !c.fallback(ctx, tissue.OpCollectionItemCreate, err) {
+		return result, err
+	}
+	o := &tissue.CreateCollectionItemOption{CollectionID: collectionID}
+	if option != nil {
+		o.Link, o.Note, o.Tags = option.Link, option.Note, option.Tags
+	}
+	return c.scraping.CreateCollectionItem(ctx, o)
+}
+
+func (c *HybridClient) UpdateCollectionItem(ctx context.Context, collectionID, itemID int64, option *UpdateCollectionItemOption) (*tissue.CollectionItem, error) {
+	result, err := c.token.UpdateCollectionItem(ctx, collectionID, itemID, option)
+	if err == nil || !c.fallback(ctx, tissue.OpCollectionItemUpdate, err) {
+		return result, err
+	}
+	o := &tissue.UpdateCollectionItemOption{CollectionID: collectionID, ItemID: itemID}
+	if option != nil {
+		o.Note, o.Tags = option.Note, option.Tags
+	}
+	return c.scraping.UpdateCollectionItem(ctx, o)
+}
+
+func (c *HybridClient) DeleteCollectionItem(ctx context.Context, collectionID, itemID int64) error {
+	err := c.token.DeleteCollectionItem(ctx, collectionID, itemID)
+	if err == nil || !c.fallback(ctx, tissue.OpCollectionItemDelete, err) {
+		return err
+	}
+	return c.scraping.DeleteCollectionItem(ctx, collectionID, itemID)
+}
+
+func (c *HybridClient) SearchCheckins(ctx context.Context, option *SearchOption) ([]tissue.Checkin, error) {
+	result, err := c.token.SearchCheckins(ctx, option)
+	if err == nil || !c.fallback(ctx, tissue.OpSearchCheckins, err) {
+		return result, err
+	}
+	o := &tissue.SearchCheckinsOption{}
+	if option != nil {
+		o.Query, o.Page, o.PerPage = option.Query, option.Page, option.PerPage
+	}
+	return c.scraping.SearchCheckins(ctx, o)
+}
+
+func (c *HybridClient) SearchCollections(ctx context.Context, option *SearchOption) ([]tissue.CollectionItem, error) {
+	return c.token.SearchCollections(ctx, option)
+}
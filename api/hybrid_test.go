@@ -0,0 +1,139 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+
+	tissue "github.com/mohemohe/go-tissue"
+)
+
+// newHybridTestServer は API トークン版とスクレイピング版の両方を模したサーバーを返す。
+// チェックイン作成 (POST /api/v1/checkins) が無い古いインスタンスを想定している。
+func newHybridTestServer(t *testing.T) (*httptest.Server, map[string]int) {
+	t.Helper()
+	var mu sync.Mutex
+	hits := map[string]int{}
+	type route struct {
+		token bool
+		h     http.HandlerFunc
+	}
+	routes := map[string]route{
+		"/api/v1/users/alice/stats/checkin/hourly": {true, func(w http.ResponseWriter, r *http.Request) {
+			_ = json.NewEncoder(w).Encode([]HourlyCheckinSummary{{Hour: 1, Count: 2}})
+		}},
+		"/api/v1/users/_/stats/checkin/hourly": {true, nil},
+		"/api/v1/checkins/0":                   {true, nil},
+		"/api/v1/checkins/1": {true, func(w http.ResponseWriter, r *http.Request) {
+			http.NotFound(w, r)
+		}},
+		"/api/recent-tags": {false, func(w http.ResponseWriter, r *http.Request) {
+			_ = json.NewEncoder(w).Encode([]string{"a", "b"})
+		}},
+		"/api/checkins": {false, func(w http.ResponseWriter, r *http.Request) {
+			_ = json.NewEncoder(w).Encode(tissue.Checkin{ID: 42})
+		}},
+	}
+	mux := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/login" {
+			if r.Method == http.MethodGet {
+				http.SetCookie(w, &http.Cookie{Name: "XSRF-TOKEN", Value: url.QueryEscape("xsrf"), Path: "/"})
+				return
+			}
+			http.SetCookie(w, &http.Cookie{Name: "session", Value: "ok", Path: "/"})
+			http.Redirect(w, r, "/home", http.StatusFound)
+			return
+		}
+		rt, ok := routes[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		if rt.token && r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if ck, err := r.Cookie("session"); !rt.token && (err != nil || ck.Value != "ok") {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		mu.Lock()
+		hits[r.Method+" "+r.URL.Path]++
+		mu.Unlock()
+		rt.h(w, r)
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server, hits
+}
+
+func newTestHybridClient(t *testing.T, baseURL string) *HybridClient {
+	t.Helper()
+	client, err := NewHybridClient(&HybridClientOption{
+		BaseURL:     baseURL,
+		AccessToken: "token",
+		Email:       "alice@example.com",
+		Password:    "secret",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client
+}
+
+func TestHybridClient_Routing(t *testing.T) {
+	server, hits := newHybridTestServer(t)
+	client := newTestHybridClient(t, server.URL)
+	ctx := context.Background()
+
+	tags, err := client.RecentTags(ctx)
+	if err != nil || len(tags) != 2 {
+		t.Fatalf("RecentTags: %v %v", tags, err)
+	}
+	hourly, err := client.UserHourlyCheckinStats(ctx, "alice", nil)
+	if err != nil || len(hourly) != 1 {
+		t.Fatalf("UserHourlyCheckinStats: %v %v", hourly, err)
+	}
+
+	// 古いインスタンスでは作成 API が無いので、スクレイピング版で作成する
+	checkin, err := client.CreateCheckin(ctx, &CreateCheckinOption{Note: "hybrid"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if checkin.ID != 42 || hits["POST /api/checkins"] != 1 {
+		t.Errorf("expected fallback to scraping: %+v %v", checkin, hits)
+	}
+
+	// エンドポイントはあるがリソースが無い 404 はそのまま返す
+	if _, err := client.GetCheckin(ctx, 1); !tissue.IsNotFound(err) {
+		t.Errorf("expected not found, got %v", err)
+	}
+	if hits["GET /api/checkins/1"] != 0 {
+		t.Error("resource 404 must not fall back to scraping")
+	}
+}
+
+func TestHybridClient_Capabilities(t *testing.T) {
+	server, _ := newHybridTestServer(t)
+	client := newTestHybridClient(t, server.URL)
+
+	caps, err := client.Capabilities(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if caps.Backend != BackendHybrid {
+		t.Errorf("unexpected backend: %s", caps.Backend)
+	}
+	for _, op := range []tissue.Operation{tissue.OpRecentTags, tissue.OpHourlyStats, tissue.OpCheckinCreate, tissue.OpCheckinGet} {
+		if !caps.Has(op) {
+			t.Errorf("%s should be available", op)
+		}
+	}
+	if caps.Has(tissue.OpLinkStats) {
+		t.Error("link stats endpoint is missing on this instance")
+	}
+}
@@ -19,7 +19,7 @@ func (c *Client) SearchCheckins(ctx context.Context, option *SearchOption) ([]ti
 	if err := c.getJSON(ctx, "/v1/search/checkins", buildSearchQuery(option), &result); err != nil {
 		return nil, err
 	}
-	return result, nil
+	return tissue.FilterCheckins(c.option.Filter, result), nil
 }
 
 func (c *Client) SearchCollections(ctx context.Context, option *SearchOption) ([]tissue.CollectionItem, error) {
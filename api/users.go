@@ -16,6 +16,9 @@ type UserCheckinsOption struct {
 	Since   time.Time
 	Until   time.Time
 	Order   string
+	// Unfiltered が true なら ClientOption.Filter を適用しない。全ページを読む処理は、
+	// 取り除かれて空になったページで読み終えたと誤らないようにこれを使う。
+	Unfiltered bool
 }
 
 type PageOption struct {
@@ -57,7 +60,10 @@ func (c *Client) UserCheckins(ctx context.Context, name string, option *UserChec
 	if err := c.getJSON(ctx, "/v1/users/"+name+"/checkins", query, &result); err != nil {
 		return nil, err
 	}
-	return result, nil
+	if option != nil && option.Unfiltered {
+		return result, nil
+	}
+	return tissue.FilterCheckins(c.option.Filter, result), nil
 }
 
 func (c *Client) UserLikes(ctx context.Context, name string, option *PageOption) ([]tissue.Checkin, error) {
@@ -66,7 +72,7 @@ func (c *Client) UserLikes(ctx context.Context, name string, option *PageOption)
 	if err := c.getJSON(ctx, "/v1/users/"+name+"/likes", query, &result); err != nil {
 		return nil, err
 	}
-	return result, nil
+	return tissue.FilterCheckins(c.option.Filter, result), nil
 }
 
 func (c *Client) UserCollections(ctx context.Context, name string, option *PageOption) ([]tissue.Collection, error) {
@@ -0,0 +1,81 @@
+package api
+
+import (
+	tissue "github.com/mohemohe/go-tissue"
+)
+
+func (o *CreateCheckinOption) Validate() error {
+	v := &tissue.Validator{}
+	v.NotFuture("checked_in_at", o.CheckedInAt)
+	v.Tags("tags", o.Tags, 0)
+	v.Link("link", o.Link)
+	v.MaxLength("note", o.Note, tissue.MaxNoteLength)
+	return v.Err()
+}
+
+func (o *UpdateCheckinOption) Validate() error {
+	v := &tissue.Validator{}
+	v.NotFuture("checked_in_at", o.CheckedInAt)
+	if o.Tags != nil {
+		v.Tags("tags", *o.Tags, 0)
+	}
+	if o.Link != nil {
+		v.Link("link", *o.Link)
+	}
+	if o.Note != nil {
+		v.MaxLength("note", *o.Note, tissue.MaxNoteLength)
+	}
+	return v.Err()
+}
+
+func (o *CreateCollectionOption) Validate() error {
+	v := &tissue.Validator{}
+	v.Required("title", o.Title)
+	v.MaxLength("title", o.Title, tissue.MaxTitleLength)
+	return v.Err()
+}
+
+func (o *UpdateCollectionOption) Validate() error {
+	v := &tissue.Validator{}
+	v.Required("title", o.Title)
+	v.MaxLength("title", o.Title, tissue.MaxTitleLength)
+	return v.Err()
+}
+
+func (o *CreateCollectionItemOption) Validate() error {
+	v := &tissue.Validator{}
+	v.Required("link", o.Link)
+	v.Link("link", o.Link)
+	v.MaxLength("note", o.Note, tissue.MaxNoteLength)
+	v.Tags("tags", o.Tags, tissue.MaxCollectionItemTags)
+	return v.Err()
+}
+
+func (o *UpdateCollectionItemOption) Validate() error {
+	v := &tissue.Validator{}
+	if o.Note != nil {
+		v.MaxLength("note", *o.Note, tissue.MaxNoteLength)
+	}
+	if o.Tags != nil {
+		v.Tags("tags", *o.Tags, tissue.MaxCollectionItemTags)
+	}
+	return v.Err()
+}
+
+// Validate は Webhook のチェックインに CreateCheckinOption と同じ制約を課す。
+func (o *CheckInOption) Validate() error {
+	v := &tissue.Validator{}
+	v.NotFuture("checked_in_at", o.CheckedInAt)
+	v.Tags("tags", o.Tags, 0)
+	v.Link("link", o.Link)
+	v.MaxLength("note", o.Note, tissue.MaxNoteLength)
+	return v.Err()
+}
+
+// validate は SkipValidation でなければ o.Validate() を呼ぶ。
+func (c *Client) validate(o interface{ Validate() error }) error {
+	if c.option.SkipValidation {
+		return nil
+	}
+	return o.Validate()
+}
@@ -8,21 +8,17 @@ import (
 	"io"
 	"net/http"
 	"path"
-	"time"
+
+	tissue "github.com/mohemohe/go-tissue"
 )
 
 type CheckInOption struct {
-	DateTime     time.Time `json:"-"`
-	Tags         []string  `json:"tags"`
-	Link         string    `json:"link"`
-	Note         string    `json:"note"`
-	Private      bool      `json:"is_private"`
-	TooSensitive bool      `json:"is_too_sensitive"`
-}
-
-type webhookCheckInRequest struct {
-	CheckInOption
-	CheckedInAt string `json:"checked_in_at,omitempty"`
+	CheckedInAt  *tissue.Timestamp `json:"checked_in_at,omitempty"`
+	Tags         []string          `json:"tags"`
+	Link         string            `json:"link"`
+	Note         string            `json:"note"`
+	Private      bool              `json:"is_private"`
+	TooSensitive bool              `json:"is_too_sensitive"`
 }
 
 type webhookCheckInResponse struct {
@@ -32,10 +28,8 @@ type webhookCheckInResponse struct {
 
 type CheckIn struct {
 	CheckInOption
-	ID          uint      `json:"id"`
-	Source      string    `json:"source"`
-	CheckedInAt string    `json:"checked_in_at"`
-	DateTime    time.Time `json:"-"`
+	ID     uint   `json:"id"`
+	Source string `json:"source"`
 }
 
 func (c *Client) CheckIn(ctx context.Context, option *CheckInOption) (*CheckIn, error) {
@@ -45,15 +39,13 @@ func (c *Client) CheckIn(ctx context.Context, option *CheckInOption) (*CheckIn,
 	if option == nil {
 		option = &CheckInOption{}
 	}
+	if err := c.validate(option); err != nil {
+		return nil, err
+	}
 
 	spath := path.Join("/webhooks/checkin", c.option.WebhookID)
 
-	body := webhookCheckInRequest{CheckInOption: *option}
-	if !option.DateTime.IsZero() {
-		body.CheckedInAt = option.DateTime.Format("2006-01-02T15:04:05-0700")
-	}
-
-	b, err := json.Marshal(body)
+	b, err := json.Marshal(option)
 	if err != nil {
 		return nil, err
 	}
@@ -83,10 +75,5 @@ func (c *Client) CheckIn(ctx context.Context, option *CheckInOption) (*CheckIn,
 	if err := json.Unmarshal(respBody, &r); err != nil {
 		return nil, err
 	}
-	if r.CheckIn.CheckedInAt != "" {
-		if t, err := time.Parse("2006-01-02T15:04:05-0700", r.CheckIn.CheckedInAt); err == nil {
-			r.CheckIn.DateTime = t
-		}
-	}
 	return &r.CheckIn, nil
 }
@@ -5,6 +5,8 @@ import (
 	"os"
 	"testing"
 	"time"
+
+	tissue "github.com/mohemohe/go-tissue"
 )
 
 func TestClient_CheckIn(t *testing.T) {
@@ -25,7 +27,7 @@ func TestClient_CheckIn(t *testing.T) {
 	}
 
 	checkIn, err := client.CheckIn(context.TODO(), &CheckInOption{
-		DateTime:     time.Now(),
+		CheckedInAt:  tissue.NewTimestamp(time.Now()),
 		Tags:         []string{"test", "hoge"},
 		Link:         "https://github.com/mohemohe/go-tissue",
 		Note:         "go-tissue webhook test checkin",
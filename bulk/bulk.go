@@ -0,0 +1,164 @@
+// Package bulk は過去のチェックイン・コレクションアイテムをまとめて変更する。
+//
+// 変更内容は先に Plan* で []Change として組み立て、確認してから Runner で適用する。
+// Runner は更新の間隔を空け、適用済みのものを Progress に記録するので、中断しても同じ進捗ファイルで再開できる。
+package bulk
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	tissue "github.com/mohemohe/go-tissue"
+	"github.com/mohemohe/go-tissue/api"
+)
+
+// Client は *api.Client と *api.HybridClient が満たす、一括変更に必要な操作。
+type Client interface {
+	Reader
+	UpdateCheckin(ctx context.Context, id int64, option *api.UpdateCheckinOption) (*tissue.Checkin, error)
+	UpdateCollectionItem(ctx context.Context, collectionID, itemID int64, option *api.UpdateCollectionItemOption) (*tissue.CollectionItem, error)
+}
+
+// 変更対象の種類。
+const (
+	TargetCheckin        = "checkin"
+	TargetCollectionItem = "collection_item"
+)
+
+// DefaultPerPage は Plan* で一覧を取得するときの1ページ当たりの件数。
+const DefaultPerPage = 100
+
+// Change は1件のチェックインまたはコレクションアイテムに対する変更。
+// タグを変えるときは Before / After を、リンクを変えるときは NewLink を設定する。
+type Change struct {
+	Target       string   `json:"target"`
+	ID           int64    `json:"id"`
+	CollectionID int64    `json:"collection_id,omitempty"`
+	Link         string   `json:"link,omitempty"`
+	Before       []string `json:"before,omitempty"`
+	After        []string `json:"after,omitempty"`
+	NewLink      string   `json:"new_link,omitempty"`
+}
+
+// Key は進捗の記録に使う、変更対象を一意に表す文字列。
+func (c Change) Key() string {
+	if c.Target == TargetCollectionItem {
+		return c.Target + ":" + strconv.FormatInt(c.CollectionID, 10) + ":" + strconv.FormatInt(c.ID, 10)
+	}
+	return c.Target + ":" + strconv.FormatInt(c.ID, 10)
+}
+
+// TagMapping は変更前のタグから変更後のタグへの対応。大文字小文字を区別して完全一致で比較する。
+type TagMapping map[string]string
+
+// Rename は old を new に変える対応を返す。
+func Rename(old, new string) TagMapping {
+	return TagMapping{old: new}
+}
+
+// Merge は from のタグをすべて into に変える対応を返す。
+func Merge(from []string, into string) TagMapping {
+	m := TagMapping{}
+	for _, t := range from {
+		if t != into {
+			m[t] = into
+		}
+	}
+	return m
+}
+
+// Rewrite は tags に対応を適用し、重複を取り除いた結果と、変化があったかを返す。
+func (m TagMapping) Rewrite(tags []string) ([]string, bool) {
+	result := make([]string, 0, len(tags))
+	seen := map[string]bool{}
+	changed := false
+	for _, t := range tags {
+		if to, ok := m[t]; ok {
+			t = to
+			changed = true
+		}
+		if seen[t] {
+			continue
+		}
+		seen[t] = true
+		result = append(result, t)
+	}
+	return result, changed
+}
+
+// PlanCheckins は user のチェックインを全ページ読み、m で変わるものの変更を返す。
+// ClientOption.Filter で隠れるチェックインも対象にする。
+func PlanCheckins(ctx context.Context, c Client, user string, m TagMapping) ([]Change, error) {
+	checkins, err := AllCheckins(ctx, c, user, nil)
+	if err != nil {
+		return nil, err
+	}
+	var changes []Change
+	for _, ch := range checkins {
+		if after, changed := m.Rewrite(ch.Tags); changed {
+			changes = append(changes, Change{Target: TargetCheckin, ID: ch.ID, Link: ch.Link, Before: ch.Tags, After: after})
+		}
+	}
+	return changes, nil
+}
+
+// PlanLinks は user のチェックインを全ページ読み、canon でリンクが変わるものの変更を返す。
+// ClientOption.Filter で隠れるチェックインも対象にする。
+// 揃えられないリンク (http(s) 以外など) は変更せず、skipped にリンクとエラーを渡す。skipped は nil でもよい。
+func PlanLinks(ctx context.Context, c Client, user string, canon tissue.LinkCanonicalizer, skipped func(ch tissue.Checkin, err error)) ([]Change, error) {
+	checkins, err := AllCheckins(ctx, c, user, nil)
+	if err != nil {
+		return nil, err
+	}
+	var changes []Change
+	for _, ch := range checkins {
+		if ch.Link == "" {
+			continue
+		}
+		link, err := canon.CanonicalizeLink(ch.Link)
+		if err != nil {
+			if skipped != nil {
+				skipped(ch, err)
+			}
+			continue
+		}
+		if link != ch.Link {
+			changes = append(changes, Change{Target: TargetCheckin, ID: ch.ID, Link: ch.Link, NewLink: link})
+		}
+	}
+	return changes, nil
+}
+
+// PlanCollectionItems は user の全コレクションのアイテムを読み、m で変わるものの変更を返す。
+func PlanCollectionItems(ctx context.Context, c Client, user string, m TagMapping) ([]Change, error) {
+	collections, err := AllCollections(ctx, c, user)
+	if err != nil {
+		return nil, err
+	}
+	var changes []Change
+	for _, col := range collections {
+		items, err := AllCollectionItems(ctx, c, col.ID)
+		if err != nil {
+			return nil, err
+		}
+		for _, it := range items {
+			if after, changed := m.Rewrite(it.Tags); changed {
+				changes = append(changes, Change{Target: TargetCollectionItem, ID: it.ID, CollectionID: col.ID, Link: it.Link, Before: it.Tags, After: after})
+			}
+		}
+	}
+	return changes, nil
+}
+
+// String は "checkin 123: a,b -> c,b" の形の表示を返す。
+func (c Change) String() string {
+	target := c.Target + " " + strconv.FormatInt(c.ID, 10)
+	if c.Target == TargetCollectionItem {
+		target = c.Target + " " + strconv.FormatInt(c.CollectionID, 10) + "/" + strconv.FormatInt(c.ID, 10)
+	}
+	if c.NewLink != "" {
+		return target + ": " + c.Link + " -> " + c.NewLink
+	}
+	return target + ": " + strings.Join(c.Before, ",") + " -> " + strings.Join(c.After, ",")
+}
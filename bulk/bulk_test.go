@@ -0,0 +1,307 @@
+package bulk
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	tissue "github.com/mohemohe/go-tissue"
+	"github.com/mohemohe/go-tissue/api"
+)
+
+type fakeClient struct {
+	checkins []tissue.Checkin
+	items    map[int64][]tissue.CollectionItem
+	updates  []string
+	failAt   int   // この回数目の更新を失敗させる (1 始まり)
+	failErr  error // failAt で返すエラー
+	calls    int
+	// filter は ClientOption.Filter の代わりで、Unfiltered でなければ適用する
+	filter tissue.CheckinFilter
+}
+
+func page[T any](all []T, n, perPage int) []T {
+	start := (n - 1) * perPage
+	if start >= len(all) {
+		return nil
+	}
+	end := start + perPage
+	if end > len(all) {
+		end = len(all)
+	}
+	return all[start:end]
+}
+
+func (c *fakeClient) UserCheckins(ctx context.Context, name string, option *api.UserCheckinsOption) ([]tissue.Checkin, error) {
+	result := page(c.checkins, option.Page, option.PerPage)
+	if option.Unfiltered {
+		return result, nil
+	}
+	return tissue.FilterCheckins(c.filter, result), nil
+}
+
+type tagFilter string
+
+func (f tagFilter) AllowCheckin(c tissue.Checkin) bool {
+	for _, t := range c.Tags {
+		if t == string(f) {
+			return false
+		}
+	}
+	return true
+}
+
+func (c *fakeClient) UserCollections(ctx context.Context, name string, option *api.PageOption) ([]tissue.Collection, error) {
+	var cols []tissue.Collection
+	for id := range c.items {
+		cols = append(cols, tissue.Collection{ID: id})
+	}
+	return page(cols, option.Page, option.PerPage), nil
+}
+
+func (c *fakeClient) ListCollectionItems(ctx context.Context, collectionID int64, option *api.PageOption) ([]tissue.CollectionItem, error) {
+	return page(c.items[collectionID], option.Page, option.PerPage), nil
+}
+
+func (c *fakeClient) update(key string) error {
+	c.calls++
+	if c.calls == c.failAt {
+		return c.failErr
+	}
+	c.updates = append(c.updates, key)
+	return nil
+}
+
+func (c *fakeClient) UpdateCheckin(ctx context.Context, id int64, option *api.UpdateCheckinOption) (*tissue.Checkin, error) {
+	key := Change{Target: TargetCheckin, ID: id}.Key()
+	if option.Link != nil {
+		key += ":" + *option.Link
+	}
+	return &tissue.Checkin{ID: id}, c.update(key)
+}
+
+func (c *fakeClient) UpdateCollectionItem(ctx context.Context, collectionID, itemID int64, option *api.UpdateCollectionItemOption) (*tissue.CollectionItem, error) {
+	return &tissue.CollectionItem{ID: itemID, Tags: *option.Tags}, c.update(Change{Target: TargetCollectionItem, CollectionID: collectionID, ID: itemID}.Key())
+}
+
+func TestTagMapping_Rewrite(t *testing.T) {
+	m := Merge([]string{"a", "b", "c"}, "c")
+	got, changed := m.Rewrite([]string{"a", "x", "b", "c"})
+	if !changed || !reflect.DeepEqual(got, []string{"c", "x"}) {
+		t.Fatalf("got %v (changed=%v)", got, changed)
+	}
+	if _, changed := Rename("A", "a").Rewrite([]string{"a"}); changed {
+		t.Fatal("case-different tag should not match")
+	}
+}
+
+func TestPlan(t *testing.T) {
+	c := &fakeClient{items: map[int64][]tissue.CollectionItem{47: {{ID: 1, Tags: []string{"old"}}, {ID: 2, Tags: []string{"other"}}}}}
+	for i := 1; i <= 250; i++ {
+		tags := []string{"other"}
+		if i%100 == 0 {
+			tags = []string{"old", "new"}
+		}
+		c.checkins = append(c.checkins, tissue.Checkin{ID: int64(i), Tags: tags})
+	}
+	m := Rename("old", "new")
+	changes, err := PlanCheckins(context.Background(), c, "me", m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 2 || changes[0].ID != 100 || changes[1].ID != 200 || !reflect.DeepEqual(changes[0].After, []string{"new"}) {
+		t.Fatalf("unexpected plan: %+v", changes)
+	}
+	items, err := PlanCollectionItems(context.Background(), c, "me", m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 1 || items[0].Key() != "collection_item:47:1" {
+		t.Fatalf("unexpected plan: %+v", items)
+	}
+}
+
+func TestPlanLinks(t *testing.T) {
+	c := &fakeClient{checkins: []tissue.Checkin{
+		{ID: 1, Link: "https://example.com/?utm_source=x"},
+		{ID: 2, Link: "https://example.com/"},
+		{ID: 3},
+		{ID: 4, Link: "ftp://example.com/"},
+	}}
+	var skipped []int64
+	changes, err := PlanLinks(context.Background(), c, "me", canonFunc(func(link string) (string, error) {
+		if strings.HasPrefix(link, "ftp:") {
+			return "", errors.New("bad scheme")
+		}
+		return strings.TrimSuffix(link, "?utm_source=x"), nil
+	}), func(ch tissue.Checkin, err error) { skipped = append(skipped, ch.ID) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Change{{Target: TargetCheckin, ID: 1, Link: "https://example.com/?utm_source=x", NewLink: "https://example.com/"}}
+	if !reflect.DeepEqual(changes, want) || !reflect.DeepEqual(skipped, []int64{4}) {
+		t.Fatalf("unexpected plan: %+v skipped=%v", changes, skipped)
+	}
+	r := &Runner{Client: c, Interval: -1}
+	if _, err := r.Apply(context.Background(), changes); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(c.updates, []string{"checkin:1:https://example.com/"}) {
+		t.Fatalf("unexpected updates: %v", c.updates)
+	}
+}
+
+type canonFunc func(string) (string, error)
+
+func (f canonFunc) CanonicalizeLink(link string) (string, error) { return f(link) }
+
+func TestRunner_ResumesFromProgress(t *testing.T) {
+	changes := []Change{
+		{Target: TargetCheckin, ID: 1, After: []string{"x"}},
+		{Target: TargetCheckin, ID: 2, After: []string{"x"}},
+		{Target: TargetCollectionItem, CollectionID: 47, ID: 3, After: []string{"x"}},
+	}
+	path := filepath.Join(t.TempDir(), "progress.json")
+	c := &fakeClient{failAt: 2, failErr: errors.New("boom")}
+	progress, _ := LoadProgress(path)
+	r := &Runner{Client: c, Interval: -1, Progress: progress}
+	result, err := r.Apply(context.Background(), changes)
+	if err == nil || result.Applied != 1 {
+		t.Fatalf("expected failure after 1 change: %+v %v", result, err)
+	}
+
+	progress, err = LoadProgress(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if progress.Len() != 1 {
+		t.Fatalf("progress not saved: %d", progress.Len())
+	}
+	r.Progress = progress
+	result, err = r.Apply(context.Background(), changes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *result != (Result{Planned: 3, Applied: 2, Skipped: 1}) {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+	want := []string{"checkin:1", "checkin:2", "collection_item:47:3"}
+	if !reflect.DeepEqual(c.updates, want) {
+		t.Fatalf("updates %v, want %v", c.updates, want)
+	}
+}
+
+func TestRunner_RetriesRateLimit(t *testing.T) {
+	c := &fakeClient{failAt: 1, failErr: &tissue.StatusError{StatusCode: http.StatusTooManyRequests, Status: "429 Too Many Requests"}}
+	r := &Runner{Client: c, Interval: -1}
+	saved := minRetryWait
+	minRetryWait = 0
+	defer func() { minRetryWait = saved }()
+	result, err := r.Apply(context.Background(), []Change{{Target: TargetCheckin, ID: 1}})
+	if err != nil || result.Applied != 1 || c.calls != 2 {
+		t.Fatalf("expected retry: %+v %v calls=%d", result, err, c.calls)
+	}
+}
+
+func TestPlan_PagesPastFilteredPage(t *testing.T) {
+	// 最初のページが Filter ですべて取り除かれても、後ろのページまで読む
+	c := &fakeClient{filter: tagFilter("other")}
+	for i := 1; i <= 2*DefaultPerPage+50; i++ {
+		tags := []string{"other"}
+		if i == 2*DefaultPerPage+50 {
+			tags = []string{"old"}
+		}
+		c.checkins = append(c.checkins, tissue.Checkin{ID: int64(i), Tags: tags})
+	}
+	changes, err := PlanCheckins(context.Background(), c, "me", Rename("old", "new"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 1 || changes[0].ID != int64(2*DefaultPerPage+50) {
+		t.Fatalf("unexpected plan: %+v", changes)
+	}
+	got, err := SelectCheckins(context.Background(), c, "me", &CheckinQuery{Tags: []string{"old"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 {
+		t.Fatalf("unexpected selection: %+v", got)
+	}
+}
+
+func TestSelectCheckins(t *testing.T) {
+	yes := true
+	c := &fakeClient{checkins: []tissue.Checkin{
+		{ID: 1, Tags: []string{"A", "b"}, Link: "https://example.com/", Source: "web"},
+		{ID: 2, Tags: []string{"a"}, Link: "https://example.com/", Source: "webhook"},
+		{ID: 3, Tags: []string{"a", "B"}, Source: "web"},
+		{ID: 4, Tags: []string{"a", "b"}, Link: "https://example.com/", Source: "WEB", IsPrivate: true},
+	}}
+	got, err := SelectCheckins(context.Background(), c, "me", &CheckinQuery{Tags: []string{"a", "B"}, HasLink: &yes, Source: "web"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[0].ID != 1 || got[1].ID != 4 {
+		t.Fatalf("unexpected selection: %+v", got)
+	}
+	got, _ = SelectCheckins(context.Background(), c, "me", &CheckinQuery{IsPrivate: &yes})
+	if len(got) != 1 || got[0].ID != 4 {
+		t.Fatalf("unexpected selection: %+v", got)
+	}
+}
+
+func TestEach(t *testing.T) {
+	saved := minRetryWait
+	minRetryWait = 0
+	defer func() { minRetryWait = saved }()
+
+	var mu sync.Mutex
+	running, peak := 0, 0
+	attempts := map[int64]int{}
+	ids := []int64{1, 2, 3, 4, 5, 6, 7, 8}
+	var reported int
+	results := Each(context.Background(), ids, &EachOption{Concurrency: 3, OnResult: func(ItemResult) { reported++ }}, func(ctx context.Context, id int64) error {
+		mu.Lock()
+		running++
+		if running > peak {
+			peak = running
+		}
+		attempts[id]++
+		n := attempts[id]
+		mu.Unlock()
+		time.Sleep(5 * time.Millisecond)
+		mu.Lock()
+		running--
+		mu.Unlock()
+		switch {
+		case id == 3:
+			return errors.New("not found")
+		case id == 5 && n == 1:
+			return &tissue.StatusError{StatusCode: http.StatusTooManyRequests, Status: "429 Too Many Requests"}
+		}
+		return nil
+	})
+	if peak > 3 {
+		t.Fatalf("concurrency exceeded: %d", peak)
+	}
+	if reported != len(ids) {
+		t.Fatalf("OnResult called %d times", reported)
+	}
+	for i, r := range results {
+		if r.ID != ids[i] {
+			t.Fatalf("results out of order: %+v", results)
+		}
+		if r.OK != (r.ID != 3) {
+			t.Fatalf("unexpected result: %+v", r)
+		}
+	}
+	if attempts[5] != 2 || results[2].Error != "not found" {
+		t.Fatalf("unexpected attempts/result: %v %+v", attempts, results[2])
+	}
+}
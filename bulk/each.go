@@ -0,0 +1,90 @@
+package bulk
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const DefaultConcurrency = 4
+
+// ItemResult は Each で1件に操作を適用した結果。
+type ItemResult struct {
+	ID    int64  `json:"id"`
+	OK    bool   `json:"ok"`
+	Error string `json:"error,omitempty"`
+}
+
+type EachOption struct {
+	// Concurrency は同時に実行する数。0 なら DefaultConcurrency。
+	Concurrency int
+	// Interval は操作を開始する間隔の下限 (全体で共有)。0 なら間隔を空けない。
+	Interval time.Duration
+	// MaxRetries は 429 / 5xx が返ったときに再試行する回数。0 なら DefaultMaxRetries。
+	MaxRetries int
+	// OnResult は1件終わるたびに呼ばれる。複数の goroutine から同時には呼ばれない。
+	OnResult func(ItemResult)
+}
+
+// Each は ids のそれぞれに action を並行して適用し、ids と同じ順で結果を返す。1件の失敗では止めない。
+// ctx が取り消されたら、未着手のものはその旨のエラーとする。
+func Each(ctx context.Context, ids []int64, option *EachOption, action func(ctx context.Context, id int64) error) []ItemResult {
+	if option == nil {
+		option = &EachOption{}
+	}
+	concurrency := option.Concurrency
+	if concurrency <= 0 {
+		concurrency = DefaultConcurrency
+	}
+	results := make([]ItemResult, len(ids))
+	var (
+		mu   sync.Mutex
+		wg   sync.WaitGroup
+		next time.Time
+	)
+	// wait は前回の開始から Interval が経つまで待つ。
+	wait := func() error {
+		mu.Lock()
+		now := time.Now()
+		start := next
+		if start.Before(now) {
+			start = now
+		}
+		next = start.Add(option.Interval)
+		mu.Unlock()
+		return sleep(ctx, time.Until(start))
+	}
+	report := func(i int, err error) {
+		r := ItemResult{ID: ids[i], OK: err == nil}
+		if err != nil {
+			r.Error = err.Error()
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		results[i] = r
+		if option.OnResult != nil {
+			option.OnResult(r)
+		}
+	}
+
+	indexes := make(chan int)
+	for w := 0; w < concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				if err := wait(); err != nil {
+					report(i, err)
+					continue
+				}
+				report(i, withRetry(ctx, option.MaxRetries, option.Interval, func() error { return action(ctx, ids[i]) }))
+			}
+		}()
+	}
+	for i := range ids {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+	return results
+}
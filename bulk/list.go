@@ -0,0 +1,69 @@
+package bulk
+
+import (
+	"context"
+
+	tissue "github.com/mohemohe/go-tissue"
+	"github.com/mohemohe/go-tissue/api"
+)
+
+// Reader は *api.Client と *api.HybridClient が満たす、一覧を全ページ読むのに必要な操作。
+type Reader interface {
+	UserCheckins(ctx context.Context, name string, option *api.UserCheckinsOption) ([]tissue.Checkin, error)
+	UserCollections(ctx context.Context, name string, option *api.PageOption) ([]tissue.Collection, error)
+	ListCollectionItems(ctx context.Context, collectionID int64, option *api.PageOption) ([]tissue.CollectionItem, error)
+}
+
+// AllCheckins は user のチェックインを空のページが返るまで読む。
+// 取り除かれて空になったページで読み終えたと誤らないよう、ClientOption.Filter は適用しない。
+// opt の Page と PerPage は無視する。opt は nil でもよい。
+func AllCheckins(ctx context.Context, c Reader, user string, opt *api.UserCheckinsOption) ([]tissue.Checkin, error) {
+	o := api.UserCheckinsOption{}
+	if opt != nil {
+		o = *opt
+	}
+	o.PerPage = DefaultPerPage
+	o.Unfiltered = true
+	var result []tissue.Checkin
+	for o.Page = 1; ; o.Page++ {
+		option := o
+		checkins, err := c.UserCheckins(ctx, user, &option)
+		if err != nil {
+			return nil, err
+		}
+		if len(checkins) == 0 {
+			return result, nil
+		}
+		result = append(result, checkins...)
+	}
+}
+
+// AllCollections は user のコレクションを空のページが返るまで読む。
+func AllCollections(ctx context.Context, c Reader, user string) ([]tissue.Collection, error) {
+	var result []tissue.Collection
+	for page := 1; ; page++ {
+		collections, err := c.UserCollections(ctx, user, &api.PageOption{Page: page, PerPage: DefaultPerPage})
+		if err != nil {
+			return nil, err
+		}
+		if len(collections) == 0 {
+			return result, nil
+		}
+		result = append(result, collections...)
+	}
+}
+
+// AllCollectionItems はコレクションのアイテムを空のページが返るまで読む。
+func AllCollectionItems(ctx context.Context, c Reader, collectionID int64) ([]tissue.CollectionItem, error) {
+	var result []tissue.CollectionItem
+	for page := 1; ; page++ {
+		items, err := c.ListCollectionItems(ctx, collectionID, &api.PageOption{Page: page, PerPage: DefaultPerPage})
+		if err != nil {
+			return nil, err
+		}
+		if len(items) == 0 {
+			return result, nil
+		}
+		result = append(result, items...)
+	}
+}
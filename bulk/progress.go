@@ -0,0 +1,87 @@
+package bulk
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// Progress は適用済みの変更を JSON ファイルに記録する。nil の Progress は何も記録しない。
+type Progress struct {
+	path string
+	done map[string]bool
+}
+
+type progressFile struct {
+	Done []string `json:"done"`
+}
+
+// LoadProgress は path の進捗ファイルを読む。ファイルが無ければ空の進捗を返す。
+func LoadProgress(path string) (*Progress, error) {
+	p := &Progress{path: path, done: map[string]bool{}}
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return p, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var f progressFile
+	if err := json.Unmarshal(b, &f); err != nil {
+		return nil, err
+	}
+	for _, k := range f.Done {
+		p.done[k] = true
+	}
+	return p, nil
+}
+
+// Len は適用済みの件数を返す。
+func (p *Progress) Len() int {
+	if p == nil {
+		return 0
+	}
+	return len(p.done)
+}
+
+func (p *Progress) Done(key string) bool {
+	return p != nil && p.done[key]
+}
+
+// Mark は key を適用済みとして記録し、ファイルに書き出す。
+func (p *Progress) Mark(key string) error {
+	if p == nil {
+		return nil
+	}
+	p.done[key] = true
+	f := progressFile{Done: make([]string, 0, len(p.done))}
+	for k := range p.done {
+		f.Done = append(f.Done, k)
+	}
+	sort.Strings(f.Done)
+	b, err := json.Marshal(f)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(p.path), 0o700); err != nil {
+		return err
+	}
+	tmp := p.path + ".tmp"
+	if err := os.WriteFile(tmp, b, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, p.path)
+}
+
+// Remove は進捗ファイルを削除する。すべて適用し終えたときに使う。
+func (p *Progress) Remove() error {
+	if p == nil {
+		return nil
+	}
+	if err := os.Remove(p.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,138 @@
+package bulk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	tissue "github.com/mohemohe/go-tissue"
+	"github.com/mohemohe/go-tissue/api"
+)
+
+const (
+	DefaultInterval   = time.Second
+	DefaultMaxRetries = 3
+)
+
+// minRetryWait は再試行までの待ち時間の下限。
+var minRetryWait = time.Second
+
+// Runner は Change を順に適用する。
+type Runner struct {
+	Client Client
+	// Interval は更新の間隔。0 なら DefaultInterval、負なら待たない。
+	Interval time.Duration
+	// MaxRetries は 429 / 5xx が返ったときに再試行する回数。待ち時間は Interval (1秒未満なら1秒) から倍々に増やす。0 なら DefaultMaxRetries。
+	MaxRetries int
+	// Progress は適用済みの変更の記録。nil なら記録しない。
+	Progress *Progress
+	// OnApply は1件適用するたびに呼ばれる。
+	OnApply func(done, total int, c Change)
+}
+
+type Result struct {
+	Planned int `json:"planned"`
+	Applied int `json:"applied"`
+	// Skipped は進捗ファイルで適用済みとなっていたため飛ばした件数。
+	Skipped int `json:"skipped"`
+}
+
+// Apply は changes を適用する。失敗したらその時点で止め、それまでの結果とエラーを返す。
+func (r *Runner) Apply(ctx context.Context, changes []Change) (*Result, error) {
+	result := &Result{Planned: len(changes)}
+	interval := r.Interval
+	if interval == 0 {
+		interval = DefaultInterval
+	}
+	first := true
+	for _, c := range changes {
+		if r.Progress.Done(c.Key()) {
+			result.Skipped++
+			continue
+		}
+		if !first {
+			if err := sleep(ctx, interval); err != nil {
+				return result, err
+			}
+		}
+		first = false
+		if err := withRetry(ctx, r.MaxRetries, interval, func() error { return r.apply(ctx, c) }); err != nil {
+			return result, fmt.Errorf("%s: %w", c.Key(), err)
+		}
+		result.Applied++
+		if err := r.Progress.Mark(c.Key()); err != nil {
+			return result, err
+		}
+		if r.OnApply != nil {
+			r.OnApply(result.Applied+result.Skipped, len(changes), c)
+		}
+	}
+	return result, nil
+}
+
+// withRetry は fn が 429 / 5xx で失敗したら、wait (minRetryWait 未満なら minRetryWait) から倍々に待って retries 回まで再試行する。
+func withRetry(ctx context.Context, retries int, wait time.Duration, fn func() error) error {
+	if retries == 0 {
+		retries = DefaultMaxRetries
+	}
+	if wait < minRetryWait {
+		wait = minRetryWait
+	}
+	for attempt := 0; ; attempt++ {
+		err := fn()
+		if err == nil || attempt >= retries || !retryable(err) {
+			return err
+		}
+		if err := sleep(ctx, wait); err != nil {
+			return err
+		}
+		wait *= 2
+	}
+}
+
+func (r *Runner) apply(ctx context.Context, c Change) error {
+	var tags *[]string
+	if c.After != nil {
+		after := c.After
+		tags = &after
+	}
+	switch c.Target {
+	case TargetCheckin:
+		option := &api.UpdateCheckinOption{Tags: tags}
+		if c.NewLink != "" {
+			link := c.NewLink
+			option.Link = &link
+		}
+		_, err := r.Client.UpdateCheckin(ctx, c.ID, option)
+		return err
+	case TargetCollectionItem:
+		if c.NewLink != "" {
+			// コレクションアイテムのリンクは API で変更できない
+			return fmt.Errorf("cannot change the link of %s", c.Key())
+		}
+		_, err := r.Client.UpdateCollectionItem(ctx, c.CollectionID, c.ID, &api.UpdateCollectionItemOption{Tags: tags})
+		return err
+	}
+	return fmt.Errorf("unknown target: %s", c.Target)
+}
+
+func retryable(err error) bool {
+	var se *tissue.StatusError
+	return errors.As(err, &se) && (se.StatusCode == http.StatusTooManyRequests || se.StatusCode >= 500)
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
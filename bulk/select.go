@@ -0,0 +1,67 @@
+package bulk
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	tissue "github.com/mohemohe/go-tissue"
+	"github.com/mohemohe/go-tissue/api"
+)
+
+// CheckinQuery はチェックインを選ぶ条件。ゼロ値の条件は使わない。
+type CheckinQuery struct {
+	// Since / Until は日付の範囲 (両端を含む)。API 側で絞り込む。
+	Since time.Time
+	Until time.Time
+	// Tags はすべて含むチェックインを選ぶ。大文字小文字は区別しない。
+	Tags      []string
+	HasLink   *bool
+	IsPrivate *bool
+	// Source は登録元 (web / csv / webhook / api)。
+	Source string
+}
+
+// Match は c が q の条件 (Since / Until を除く) を満たすかを返す。
+func (q *CheckinQuery) Match(c tissue.Checkin) bool {
+	if q.HasLink != nil && (c.Link != "") != *q.HasLink {
+		return false
+	}
+	if q.IsPrivate != nil && c.IsPrivate != *q.IsPrivate {
+		return false
+	}
+	if q.Source != "" && !strings.EqualFold(c.Source, q.Source) {
+		return false
+	}
+	for _, want := range q.Tags {
+		found := false
+		for _, t := range c.Tags {
+			if strings.EqualFold(t, want) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// SelectCheckins は user のチェックインを全ページ読み、q に合うものを返す。ClientOption.Filter は適用しない。
+func SelectCheckins(ctx context.Context, c Reader, user string, q *CheckinQuery) ([]tissue.Checkin, error) {
+	if q == nil {
+		q = &CheckinQuery{}
+	}
+	checkins, err := AllCheckins(ctx, c, user, &api.UserCheckinsOption{HasLink: q.HasLink, Since: q.Since, Until: q.Until})
+	if err != nil {
+		return nil, err
+	}
+	var result []tissue.Checkin
+	for _, ch := range checkins {
+		if q.Match(ch) {
+			result = append(result, ch)
+		}
+	}
+	return result, nil
+}
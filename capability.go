@@ -0,0 +1,257 @@
+package go_tissue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"sort"
+	"sync"
+)
+
+// Operation はクライアントが提供する操作の識別子。バックエンドをまたいで共通。
+type Operation string
+
+const (
+	OpMe                   Operation = "me"
+	OpUser                 Operation = "user.get"
+	OpUserCheckins         Operation = "user.checkins"
+	OpUserLikes            Operation = "user.likes"
+	OpCheckinCreate        Operation = "checkin.create"
+	OpCheckinGet           Operation = "checkin.get"
+	OpCheckinUpdate        Operation = "checkin.update"
+	OpCheckinDelete        Operation = "checkin.delete"
+	OpLike                 Operation = "checkin.like"
+	OpUnlike               Operation = "checkin.unlike"
+	OpLikedBy              Operation = "checkin.liked_by"
+	OpCollectionList       Operation = "collection.list"
+	OpCollectionCreate     Operation = "collection.create"
+	OpCollectionUpdate     Operation = "collection.update"
+	OpCollectionDelete     Operation = "collection.delete"
+	OpCollectionItemList   Operation = "collection.item.list"
+	OpCollectionItemCreate Operation = "collection.item.create"
+	OpCollectionItemUpdate Operation = "collection.item.update"
+	OpCollectionItemDelete Operation = "collection.item.delete"
+	OpSearchCheckins       Operation = "search.checkins"
+	OpSearchCollections    Operation = "search.collections"
+	OpRecentTags           Operation = "tags.recent"
+	OpInformation          Operation = "information"
+	OpSiteDailyStats       Operation = "stats.site_daily"
+	OpDailyStats           Operation = "stats.daily"
+	OpHourlyStats          Operation = "stats.hourly"
+	OpTagStats             Operation = "stats.tags"
+	OpLinkStats            Operation = "stats.links"
+	OpWebhookCheckin       Operation = "webhook.checkin"
+	OpWebhookList          Operation = "webhook.list"
+	OpWebhookCreate        Operation = "webhook.create"
+	OpWebhookDelete        Operation = "webhook.delete"
+	OpAccessTokenList      Operation = "access_token.list"
+	OpAccessTokenCreate    Operation = "access_token.create"
+	OpAccessTokenRevoke    Operation = "access_token.revoke"
+	OpProfileUpdate        Operation = "setting.profile"
+	OpPrivacyUpdate        Operation = "setting.privacy"
+	OpCSVExport            Operation = "setting.csv_export"
+	OpCSVImport            Operation = "setting.csv_import"
+	OpTagMuteList          Operation = "tag_mute.list"
+	OpTagMuteCreate        Operation = "tag_mute.create"
+	OpTagMuteDelete        Operation = "tag_mute.delete"
+)
+
+// AllOperations は既知の全操作。
+var AllOperations = []Operation{
+	OpMe, OpUser, OpUserCheckins, OpUserLikes,
+	OpCheckinCreate, OpCheckinGet, OpCheckinUpdate, OpCheckinDelete,
+	OpLike, OpUnlike, OpLikedBy,
+	OpCollectionList, OpCollectionCreate, OpCollectionUpdate, OpCollectionDelete,
+	OpCollectionItemList, OpCollectionItemCreate, OpCollectionItemUpdate, OpCollectionItemDelete,
+	OpSearchCheckins, OpSearchCollections, OpRecentTags, OpInformation,
+	OpSiteDailyStats, OpDailyStats, OpHourlyStats, OpTagStats, OpLinkStats,
+	OpWebhookCheckin, OpWebhookList, OpWebhookCreate, OpWebhookDelete,
+	OpAccessTokenList, OpAccessTokenCreate, OpAccessTokenRevoke,
+	OpProfileUpdate, OpPrivacyUpdate, OpCSVExport, OpCSVImport,
+	OpTagMuteList, OpTagMuteCreate, OpTagMuteDelete,
+}
+
+const BackendScraping = "scraping"
+
+// Capabilities はあるバックエンドで、ある接続先インスタンスに対して利用できる操作の一覧。
+type Capabilities struct {
+	Backend    string             `json:"backend"`
+	BaseURL    string             `json:"base_url"`
+	Operations map[Operation]bool `json:"operations"`
+}
+
+// NewCapabilities は ops だけを利用可能とした Capabilities を作る。AllOperations の残りは false になる。
+func NewCapabilities(backend, baseURL string, ops []Operation) *Capabilities {
+	c := &Capabilities{Backend: backend, BaseURL: baseURL, Operations: map[Operation]bool{}}
+	for _, op := range AllOperations {
+		c.Operations[op] = false
+	}
+	for _, op := range ops {
+		c.Operations[op] = true
+	}
+	return c
+}
+
+func (c *Capabilities) Has(op Operation) bool {
+	return c != nil && c.Operations[op]
+}
+
+// Supported は利用可能な操作を名前順で返す。
+func (c *Capabilities) Supported() []Operation {
+	var ops []Operation
+	for op, ok := range c.Operations {
+		if ok {
+			ops = append(ops, op)
+		}
+	}
+	sort.Slice(ops, func(i, j int) bool { return ops[i] < ops[j] })
+	return ops
+}
+
+// ProbeEndpoint は操作の存在確認に使うエンドポイント。Path が空の操作は問い合わせずに利用可能とみなす。
+type ProbeEndpoint struct {
+	Operation Operation
+	Path      string
+}
+
+// ProbeEndpoints は未認証の GET を endpoints に送り、404 / 410 を返したものだけを利用不可とする。
+// ルートが存在すれば認証エラーや 405 が返るので、書き込みを伴う操作も副作用なく確認できる。
+func ProbeEndpoints(ctx context.Context, httpClient *http.Client, backend, baseURL string, endpoints []ProbeEndpoint) (*Capabilities, error) {
+	if httpClient == nil {
+		httpClient = probeHTTPClient
+	}
+	base, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, err
+	}
+	ops := make([]Operation, 0, len(endpoints))
+	var (
+		mu   sync.Mutex
+		wg   sync.WaitGroup
+		errs []error
+	)
+	for _, e := range endpoints {
+		if e.Path == "" {
+			mu.Lock()
+			ops = append(ops, e.Operation)
+			mu.Unlock()
+			continue
+		}
+		wg.Add(1)
+		go func(e ProbeEndpoint) {
+			defer wg.Done()
+			ok, err := probeEndpoint(ctx, httpClient, base, e.Path)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				errs = append(errs, fmt.Errorf("probe %s: %w", e.Operation, err))
+				return
+			}
+			if ok {
+				ops = append(ops, e.Operation)
+			}
+		}(e)
+	}
+	wg.Wait()
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return NewCapabilities(backend, baseURL, ops), nil
+}
+
+// probeHTTPClient は Cookie を持たず、リダイレクトも追わない。ログイン画面への転送も「存在する」と判定するため。
+var probeHTTPClient = &http.Client{
+	CheckRedirect: func(req *http.Request, via []*http.Request) error {
+		return http.ErrUseLastResponse
+	},
+}
+
+func probeEndpoint(ctx context.Context, httpClient *http.Client, base *url.URL, spath string) (bool, error) {
+	u := *base
+	u.Path = path.Join(u.Path, spath)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("User-Agent", "go-tissue")
+	req.Header.Set("Accept", "application/json")
+	res, err := httpClient.Do(req)
+	if err != nil {
+		return false, err
+	}
+	_ = res.Body.Close()
+	return res.StatusCode != http.StatusNotFound && res.StatusCode != http.StatusGone, nil
+}
+
+// scrapingEndpoints はスクレイピング版が実装している操作と、その確認先。
+// ユーザー名を含む公開エンドポイントは存在しないユーザーでも 404 になるため問い合わせない。
+var scrapingEndpoints = []ProbeEndpoint{
+	{OpMe, "/api/me"},
+	{OpUserCheckins, ""},
+	{OpCheckinCreate, "/api/checkins"},
+	{OpLike, "/api/likes"},
+	{OpUnlike, "/api/likes/0"},
+	{OpLikedBy, ""},
+	{OpCollectionList, "/api/collections"},
+	{OpCollectionCreate, "/api/collections"},
+	{OpCollectionUpdate, "/api/collections/0"},
+	{OpCollectionDelete, "/api/collections/0"},
+	{OpCollectionItemList, "/api/collections/0/items"},
+	{OpCollectionItemCreate, "/api/collections/0/items"},
+	{OpCollectionItemUpdate, "/api/collections/0/items/0"},
+	{OpCollectionItemDelete, "/api/collections/0/items/0"},
+	{OpSearchCheckins, "/api/search/checkins"},
+	{OpRecentTags, "/api/recent-tags"},
+	{OpInformation, "/api/information/latest"},
+	{OpSiteDailyStats, "/api/stats/checkin/daily"},
+	{OpDailyStats, ""},
+	{OpTagStats, ""},
+	{OpWebhookList, "/setting/webhooks"},
+	{OpWebhookCreate, "/setting/webhooks"},
+	{OpWebhookDelete, "/setting/webhooks/0"},
+	{OpAccessTokenList, "/setting/tokens"},
+	{OpAccessTokenCreate, "/setting/tokens"},
+	{OpAccessTokenRevoke, "/setting/tokens/0"},
+	{OpProfileUpdate, "/setting/profile"},
+	{OpPrivacyUpdate, "/setting/privacy"},
+	{OpCSVExport, "/setting/export/csv"},
+	{OpCSVImport, "/setting/import"},
+	{OpTagMuteList, "/setting/filter/tags"},
+	{OpTagMuteCreate, "/setting/filter/tags"},
+	{OpTagMuteDelete, "/setting/filter/tags/0"},
+}
+
+// SupportedOperations はスクレイピング版が実装している操作を返す。接続先での有無は確認しない。
+func SupportedOperations() []Operation {
+	ops := make([]Operation, len(scrapingEndpoints))
+	for i, e := range scrapingEndpoints {
+		ops[i] = e.Operation
+	}
+	return ops
+}
+
+// Probe は baseURL のインスタンスでスクレイピング版の各操作が使えるかを問い合わせる。ログインは行わない。
+func Probe(ctx context.Context, baseURL string) (*Capabilities, error) {
+	if baseURL == "" {
+		baseURL = defaultBaseURL
+	}
+	return ProbeEndpoints(ctx, nil, BackendScraping, baseURL, scrapingEndpoints)
+}
+
+// Capabilities は接続先インスタンスでこのクライアントが使える操作を返す。結果はクライアントごとにキャッシュされる。
+func (c *Client) Capabilities(ctx context.Context) (*Capabilities, error) {
+	c.capMu.Lock()
+	defer c.capMu.Unlock()
+	if c.capabilities != nil {
+		return c.capabilities, nil
+	}
+	caps, err := ProbeEndpoints(ctx, nil, BackendScraping, c.option.BaseURL, scrapingEndpoints)
+	if err != nil {
+		return nil, err
+	}
+	c.capabilities = caps
+	return caps, nil
+}
@@ -0,0 +1,65 @@
+package go_tissue
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestProbe(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		methods []string
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		methods = append(methods, r.Method)
+		mu.Unlock()
+		switch {
+		case r.URL.Path == "/api/recent-tags", r.URL.Path == "/api/information/latest":
+			http.NotFound(w, r)
+		case r.URL.Path == "/api/checkins":
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		case strings.HasPrefix(r.URL.Path, "/api/collections"):
+			http.Redirect(w, r, "/login", http.StatusFound)
+		default:
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	defer server.Close()
+
+	caps, err := Probe(context.Background(), server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, op := range []Operation{OpMe, OpCheckinCreate, OpCollectionList, OpCollectionItemDelete, OpUserCheckins, OpSiteDailyStats} {
+		if !caps.Has(op) {
+			t.Errorf("%s should be available", op)
+		}
+	}
+	for _, op := range []Operation{OpRecentTags, OpInformation, OpCheckinGet, OpHourlyStats} {
+		if caps.Has(op) {
+			t.Errorf("%s should not be available", op)
+		}
+	}
+	for _, m := range methods {
+		if m != http.MethodGet {
+			t.Errorf("probe must only send GET, got %s", m)
+		}
+	}
+}
+
+func TestIsNotFound(t *testing.T) {
+	rec := httptest.NewRecorder()
+	http.NotFound(rec, nil)
+	err := readErrorResponse(rec.Result())
+	if !IsNotFound(err) {
+		t.Errorf("expected not found: %v", err)
+	}
+	if !strings.Contains(err.Error(), "404") {
+		t.Errorf("unexpected message: %v", err)
+	}
+}
@@ -8,7 +8,7 @@ import (
 )
 
 type CreateCheckinOption struct {
-	CheckedInAt        *time.Time `json:"checked_in_at,omitempty"`
+	CheckedInAt        *Timestamp `json:"checked_in_at,omitempty"`
 	Tags               []string   `json:"tags,omitempty"`
 	Link               string     `json:"link,omitempty"`
 	Note               string     `json:"note,omitempty"`
@@ -18,7 +18,7 @@ type CreateCheckinOption struct {
 }
 
 type UpdateCheckinOption struct {
-	CheckedInAt        *time.Time `json:"checked_in_at,omitempty"`
+	CheckedInAt        *Timestamp `json:"checked_in_at,omitempty"`
 	Tags               *[]string  `json:"tags,omitempty"`
 	Link               *string    `json:"link,omitempty"`
 	Note               *string    `json:"note,omitempty"`
@@ -31,6 +31,28 @@ func (c *Client) CreateCheckin(ctx context.Context, option *CreateCheckinOption)
 	if option == nil {
 		option = &CreateCheckinOption{}
 	}
+	o := *option
+	tags, err := NormalizeTags(c.option.TagNormalizer, o.Tags)
+	if err != nil {
+		return nil, err
+	}
+	o.Tags = tags
+	if o.Link, err = CanonicalizeLink(c.option.LinkCanonicalizer, o.Link); err != nil {
+		return nil, err
+	}
+	if err := c.validate(&o); err != nil {
+		return nil, err
+	}
+	if g := c.option.DuplicateGuard; g != nil {
+		candidate := DuplicateCandidate{CheckedInAt: time.Now(), Link: o.Link, Tags: o.Tags}
+		if o.CheckedInAt != nil {
+			candidate.CheckedInAt = o.CheckedInAt.Time
+		}
+		if err := g.CheckCheckin(ctx, duplicateSource{c}, candidate); err != nil {
+			return nil, err
+		}
+	}
+	option = &o
 	result := &Checkin{}
 	if err := c.sendJSON(ctx, http.MethodPost, "/api/checkins", option, result); err != nil {
 		return nil, err
@@ -50,6 +72,19 @@ func (c *Client) UpdateCheckin(ctx context.Context, id int64, option *UpdateChec
 	if option == nil {
 		option = &UpdateCheckinOption{}
 	}
+	o := *option
+	tags, err := NormalizeTagsPtr(c.option.TagNormalizer, o.Tags)
+	if err != nil {
+		return nil, err
+	}
+	o.Tags = tags
+	if o.Link, err = CanonicalizeLinkPtr(c.option.LinkCanonicalizer, o.Link); err != nil {
+		return nil, err
+	}
+	if err := c.validate(&o); err != nil {
+		return nil, err
+	}
+	option = &o
 	result := &Checkin{}
 	if err := c.sendJSON(ctx, http.MethodPatch, "/api/checkins/"+strconv.FormatInt(id, 10), option, result); err != nil {
 		return nil, err
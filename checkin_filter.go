@@ -0,0 +1,35 @@
+package go_tissue
+
+// CheckinFilter はクライアントが返すチェックイン一覧から取り除くものを決める。
+// ClientOption.Filter に指定すると、一覧系のメソッドの結果に適用される。実装は filter パッケージにある。
+type CheckinFilter interface {
+	AllowCheckin(checkin Checkin) bool
+}
+
+// FilterCheckins は f が許可したチェックインだけを返す。f が nil ならそのまま返す。
+// 取り除いた分だけ1ページの件数は per_page より少なくなる。
+func FilterCheckins(f CheckinFilter, checkins []Checkin) []Checkin {
+	if f == nil {
+		return checkins
+	}
+	result := checkins[:0:0]
+	for _, c := range checkins {
+		if f.AllowCheckin(c) {
+			result = append(result, c)
+		}
+	}
+	return result
+}
+
+func filterUserCheckins(f CheckinFilter, checkins []UserCheckin) []UserCheckin {
+	if f == nil {
+		return checkins
+	}
+	result := checkins[:0:0]
+	for _, c := range checkins {
+		if f.AllowCheckin(c.Checkin) {
+			result = append(result, c)
+		}
+	}
+	return result
+}
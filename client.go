@@ -17,19 +17,44 @@ import (
 
 var errNilOption = errors.New("option is required")
 
+const defaultBaseURL = "https://shikorism.net"
+
 type ClientOption struct {
 	BaseURL  string
 	Email    string
 	Password string
+	// Filter は UserCheckins / SearchCheckins の結果に適用される。
+	Filter CheckinFilter
+	// TagNormalizer はチェックイン・コレクションアイテムの作成・更新で送るタグに適用される。
+	TagNormalizer TagNormalizer
+	// LinkCanonicalizer はチェックイン・コレクションアイテムの作成・更新で送るリンクに適用される。
+	LinkCanonicalizer LinkCanonicalizer
+	// DuplicateGuard はチェックイン・コレクションアイテムの作成の前に重複を調べる。
+	DuplicateGuard DuplicateGuard
+	// SkipValidation が true なら、作成・更新の前に Validate() を呼ばずにそのまま送る。
+	SkipValidation bool
 }
 
+// Client は複数の goroutine から同時に使用できる。
 type Client struct {
 	option     *ClientOption
 	httpClient *http.Client
-	baseURL    *url.URL
+	// loginClient はログインフォームの送信用で、リダイレクトを追わない。Cookie jar は httpClient と共有する。
+	loginClient *http.Client
+	baseURL     *url.URL
 
 	mu       sync.Mutex
 	loggedIn bool
+	login    *loginCall
+
+	capMu        sync.Mutex
+	capabilities *Capabilities
+}
+
+// loginCall は進行中のログイン。同時に呼ばれた ensureLoggedIn はこれの完了を待ち、結果を共有する。
+type loginCall struct {
+	done chan struct{}
+	err  error
 }
 
 func NewClient(option *ClientOption) (*Client, error) {
@@ -37,7 +62,7 @@ func NewClient(option *ClientOption) (*Client, error) {
 		return nil, errors.New("option is required")
 	}
 	if option.BaseURL == "" {
-		option.BaseURL = "https://shikorism.net"
+		option.BaseURL = defaultBaseURL
 	}
 	u, err := url.Parse(option.BaseURL)
 	if err != nil {
@@ -55,6 +80,12 @@ func NewClient(option *ClientOption) (*Client, error) {
 		httpClient: &http.Client{
 			Jar: jar,
 		},
+		loginClient: &http.Client{
+			Jar: jar,
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
 	}, nil
 }
 
@@ -67,6 +98,7 @@ func (c *Client) resolveURL(spath string, query url.Values) string {
 	return u.String()
 }
 
+// xsrfToken は jar から XSRF-TOKEN を取り出す。cookiejar.Jar は並行アクセスに対して安全。
 func (c *Client) xsrfToken() (string, error) {
 	cookies := c.httpClient.Jar.Cookies(c.baseURL)
 	for _, ck := range cookies {
@@ -81,20 +113,48 @@ func (c *Client) xsrfToken() (string, error) {
 	return "", errors.New("XSRF-TOKEN cookie not found")
 }
 
+// ensureLoggedIn は未ログインならログインする。同時に呼ばれても実際のログインは1回だけ行われ、
+// 失敗した場合は次の呼び出しで再試行する。ログインした呼び出し元の ctx が先に終わった場合、
+// 待っていた側は自分の ctx がまだ有効なら改めてログインする。
 func (c *Client) ensureLoggedIn(ctx context.Context) error {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-	if c.loggedIn {
-		return nil
-	}
-	if err := c.login(ctx); err != nil {
-		return err
+	for {
+		c.mu.Lock()
+		if c.loggedIn {
+			c.mu.Unlock()
+			return nil
+		}
+		if call := c.login; call != nil {
+			c.mu.Unlock()
+			select {
+			case <-call.done:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+			if isContextError(call.err) && ctx.Err() == nil {
+				continue
+			}
+			return call.err
+		}
+		call := &loginCall{done: make(chan struct{})}
+		c.login = call
+		c.mu.Unlock()
+
+		call.err = c.doLogin(ctx)
+
+		c.mu.Lock()
+		c.loggedIn = call.err == nil
+		c.login = nil
+		c.mu.Unlock()
+		close(call.done)
+		return call.err
 	}
-	c.loggedIn = true
-	return nil
 }
 
-func (c *Client) login(ctx context.Context) error {
+func isContextError(err error) bool {
+	return errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)
+}
+
+func (c *Client) doLogin(ctx context.Context) error {
 	loginURL := c.resolveURL("/login", nil)
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, loginURL, nil)
@@ -127,13 +187,7 @@ func (c *Client) login(ctx context.Context) error {
 	postReq.Header.Set("X-XSRF-TOKEN", token)
 	postReq.Header.Set("Accept", "text/html,application/xhtml+xml")
 
-	origCheckRedirect := c.httpClient.CheckRedirect
-	c.httpClient.CheckRedirect = func(req *http.Request, via []*http.Request) error {
-		return http.ErrUseLastResponse
-	}
-	defer func() { c.httpClient.CheckRedirect = origCheckRedirect }()
-
-	postRes, err := c.httpClient.Do(postReq)
+	postRes, err := c.loginClient.Do(postReq)
 	if err != nil {
 		return err
 	}
@@ -221,10 +275,32 @@ func (c *Client) deleteRequest(ctx context.Context, spath string) error {
 	return nil
 }
 
-func readErrorResponse(res *http.Response) error {
-	b, _ := io.ReadAll(res.Body)
-	if len(b) > 0 {
-		return fmt.Errorf("unexpected status %s: %s", res.Status, strings.TrimSpace(string(b)))
+// StatusError は 2xx 以外の応答を表す。
+type StatusError struct {
+	StatusCode int
+	Status     string
+	Body       string
+}
+
+func (e *StatusError) Error() string {
+	if e.Body != "" {
+		return fmt.Sprintf("unexpected status %s: %s", e.Status, e.Body)
 	}
-	return fmt.Errorf("unexpected status %s", res.Status)
+	return fmt.Sprintf("unexpected status %s", e.Status)
+}
+
+// IsNotFound は err が 404 応答によるものかを返す。
+func IsNotFound(err error) bool {
+	var se *StatusError
+	return errors.As(err, &se) && se.StatusCode == http.StatusNotFound
+}
+
+// NewStatusError は res の本文を読み、StatusError を作る。
+func NewStatusError(res *http.Response) error {
+	b, _ := io.ReadAll(res.Body)
+	return &StatusError{StatusCode: res.StatusCode, Status: res.Status, Body: strings.TrimSpace(string(b))}
+}
+
+func readErrorResponse(res *http.Response) error {
+	return NewStatusError(res)
 }
@@ -0,0 +1,248 @@
+package go_tissue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fakeTissue はログインと一部の API だけを模したローカルサーバー。
+// 機能ごとのテストは handle で必要なエンドポイントを足す。
+type fakeTissue struct {
+	*httptest.Server
+	mux        *http.ServeMux
+	password   string
+	loginDelay time.Duration
+	logins     atomic.Int32
+	checkins   atomic.Int32
+
+	// mu は user を守る。user は /api/me が返す。
+	mu   sync.Mutex
+	user User
+}
+
+func newFakeTissue(t *testing.T) *fakeTissue {
+	t.Helper()
+	f := &fakeTissue{mux: http.NewServeMux(), password: "secret", user: User{ID: 1, Name: "alice", DisplayName: "Alice"}}
+	f.mux.HandleFunc("/login", f.handleLogin)
+	f.handle("/api/me", func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		_ = json.NewEncoder(w).Encode(Me{User: f.user})
+	})
+	f.handle("/api/checkins", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.Header.Get("X-XSRF-TOKEN") != "xsrf/token" {
+			http.Error(w, "CSRF token mismatch", 419)
+			return
+		}
+		id := f.checkins.Add(1)
+		_ = json.NewEncoder(w).Encode(Checkin{ID: int64(id)})
+	})
+	f.handle("/api/moved", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/api/me", http.StatusFound)
+	})
+	f.Server = httptest.NewServer(f.mux)
+	t.Cleanup(f.Close)
+	return f
+}
+
+func (f *fakeTissue) handleLogin(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		http.SetCookie(w, &http.Cookie{Name: "XSRF-TOKEN", Value: url.QueryEscape("xsrf/token"), Path: "/"})
+	case http.MethodPost:
+		f.logins.Add(1)
+		time.Sleep(f.loginDelay)
+		if r.Header.Get("X-XSRF-TOKEN") != "xsrf/token" {
+			http.Error(w, "CSRF token mismatch", 419)
+			return
+		}
+		if r.FormValue("password") != f.password {
+			// Laravel は認証失敗時にログイン画面へ戻す
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		http.SetCookie(w, &http.Cookie{Name: "session", Value: "ok", Path: "/"})
+		http.Redirect(w, r, "/home", http.StatusFound)
+	}
+}
+
+// handle はログイン済みのセッションだけが使えるエンドポイントを足す。
+func (f *fakeTissue) handle(pattern string, h http.HandlerFunc) {
+	f.mux.HandleFunc(pattern, f.authorized(h))
+}
+
+func (f *fakeTissue) authorized(h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if ck, err := r.Cookie("session"); err != nil || ck.Value != "ok" {
+			http.Error(w, "Unauthenticated.", http.StatusUnauthorized)
+			return
+		}
+		h(w, r)
+	}
+}
+
+func newFakeClient(t *testing.T, f *fakeTissue, password string) *Client {
+	t.Helper()
+	client, err := NewClient(&ClientOption{BaseURL: f.URL, Email: "alice@example.com", Password: password})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client
+}
+
+func TestClient_ConcurrentLogin(t *testing.T) {
+	f := newFakeTissue(t)
+	f.loginDelay = 50 * time.Millisecond
+	client := newFakeClient(t, f, f.password)
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 64)
+	for i := 0; i < 32; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			if _, err := client.Me(context.Background()); err != nil {
+				errs <- err
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			if _, err := client.CreateCheckin(context.Background(), &CreateCheckinOption{Note: "race"}); err != nil {
+				errs <- err
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+	if n := f.logins.Load(); n != 1 {
+		t.Errorf("expected 1 login, got %d", n)
+	}
+	if n := f.checkins.Load(); n != 32 {
+		t.Errorf("expected 32 checkins, got %d", n)
+	}
+}
+
+func TestClient_LoginFailureIsShared(t *testing.T) {
+	f := newFakeTissue(t)
+	f.loginDelay = 50 * time.Millisecond
+	client := newFakeClient(t, f, "wrong")
+
+	var wg sync.WaitGroup
+	var failed atomic.Int32
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := client.Me(context.Background()); err != nil {
+				failed.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+	if n := failed.Load(); n != 16 {
+		t.Errorf("expected all calls to fail, %d failed", n)
+	}
+	if n := f.logins.Load(); n != 1 {
+		t.Errorf("expected 1 login, got %d", n)
+	}
+
+	// 失敗したログインは次の呼び出しで再試行される
+	client.option.Password = f.password
+	if _, err := client.Me(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if n := f.logins.Load(); n != 2 {
+		t.Errorf("expected retry login, got %d logins", n)
+	}
+}
+
+func TestClient_WaiterHonorsContext(t *testing.T) {
+	f := newFakeTissue(t)
+	f.loginDelay = 300 * time.Millisecond
+	client := newFakeClient(t, f, f.password)
+
+	leaderDone := make(chan error, 1)
+	go func() {
+		_, err := client.Me(context.Background())
+		leaderDone <- err
+	}()
+	for f.logins.Load() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := client.Me(ctx); err == nil {
+		t.Error("expected context error while waiting for login")
+	}
+	if err := <-leaderDone; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestClient_WaiterRetriesAfterLeaderCanceled(t *testing.T) {
+	f := newFakeTissue(t)
+	f.loginDelay = 200 * time.Millisecond
+	client := newFakeClient(t, f, f.password)
+
+	leaderCtx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	leaderDone := make(chan error, 1)
+	go func() {
+		_, err := client.Me(leaderCtx)
+		leaderDone <- err
+	}()
+	for f.logins.Load() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	followerDone := make(chan error, 1)
+	go func() {
+		_, err := client.Me(context.Background())
+		followerDone <- err
+	}()
+	time.Sleep(20 * time.Millisecond)
+	cancel()
+
+	if err := <-leaderDone; !errors.Is(err, context.Canceled) {
+		t.Errorf("leader: expected context.Canceled, got %v", err)
+	}
+	if err := <-followerDone; err != nil {
+		t.Fatalf("follower: %v", err)
+	}
+}
+
+func TestClient_FollowsRedirectsDuringLogin(t *testing.T) {
+	f := newFakeTissue(t)
+	f.loginDelay = 20 * time.Millisecond
+	client := newFakeClient(t, f, f.password)
+
+	// ログイン中も通常のリクエストはリダイレクトを追う
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			me := &Me{}
+			if err := client.getJSON(context.Background(), "/api/moved", nil, me); err != nil {
+				t.Error(err)
+				return
+			}
+			if me.Name != "alice" {
+				t.Errorf("unexpected user: %+v", me.User)
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -1,8 +1,15 @@
 package go_tissue
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
 	"os"
+	"reflect"
+	"strings"
 	"testing"
 	"time"
 
@@ -255,3 +262,150 @@ func TestClient_CollectionLifecycle(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+// upperTags はテスト用の TagNormalizer。タグを大文字にする。
+type upperTags struct{}
+
+func (upperTags) NormalizeTags(tags []string) ([]string, error) {
+	result := make([]string, len(tags))
+	for i, t := range tags {
+		result[i] = strings.ToUpper(t)
+	}
+	return result, nil
+}
+
+// captureSent は f が受けた書き込みのリクエストボディを記録し、最後のものを返す関数を返す。
+func captureSent(f *fakeTissue) func() []byte {
+	var sent []byte
+	inner := f.Config.Handler
+	f.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.URL.Path != "/login" {
+			sent, _ = io.ReadAll(r.Body)
+			r.Body = io.NopCloser(bytes.NewReader(sent))
+		}
+		inner.ServeHTTP(w, r)
+	})
+	return func() []byte { return sent }
+}
+
+// trimQuery はテスト用の LinkCanonicalizer。クエリを取り除き、https 以外は errScheme にする。
+type trimQuery struct{}
+
+var errScheme = errors.New("unsupported scheme")
+
+func (trimQuery) CanonicalizeLink(link string) (string, error) {
+	if !strings.HasPrefix(link, "https://") {
+		return "", errScheme
+	}
+	link, _, _ = strings.Cut(link, "?")
+	return link, nil
+}
+
+// dupGuard はテスト用の DuplicateGuard。正規化した後のリンクが dupLink なら errDuplicate で作成を拒む。
+type dupGuard struct{}
+
+const dupLink = "https://example.com/dup"
+
+var errDuplicate = errors.New("duplicate")
+
+func (dupGuard) CheckCheckin(ctx context.Context, src DuplicateSource, c DuplicateCandidate) error {
+	if c.Link == dupLink {
+		return errDuplicate
+	}
+	return nil
+}
+
+func (dupGuard) CheckCollectionItem(ctx context.Context, src DuplicateSource, collectionID int64, link string) error {
+	if link == dupLink {
+		return errDuplicate
+	}
+	return nil
+}
+
+// TestClient_HookPipeline は ClientOption のフックが作成・更新で送る値に適用されることを調べる。
+func TestClient_HookPipeline(t *testing.T) {
+	f := newFakeTissue(t)
+	sent := captureSent(f)
+	echo := func(w http.ResponseWriter, r *http.Request) { _, _ = w.Write([]byte("{}")) }
+	f.handle("/api/checkins/", echo)
+	f.handle("/api/collections/", echo)
+	client, err := NewClient(&ClientOption{BaseURL: f.URL, Email: "alice@example.com", Password: "secret", TagNormalizer: upperTags{}, LinkCanonicalizer: trimQuery{}, DuplicateGuard: dupGuard{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	tags := []string{"asmr", "voice"}
+	link, badLink, dup := "https://example.com/?utm_source=x", "ftp://example.com/", dupLink+"?utm_source=x"
+
+	cases := []struct {
+		name     string
+		call     func() error
+		wantTags []string
+		wantLink string
+		wantErr  error
+	}{
+		{"CreateCheckin", func() error {
+			_, err := client.CreateCheckin(ctx, &CreateCheckinOption{Link: link, Tags: tags})
+			return err
+		}, []string{"ASMR", "VOICE"}, "https://example.com/", nil},
+		{"UpdateCheckin", func() error {
+			_, err := client.UpdateCheckin(ctx, 1, &UpdateCheckinOption{Link: &link, Tags: &tags})
+			return err
+		}, []string{"ASMR", "VOICE"}, "https://example.com/", nil},
+		{"CreateCollectionItem", func() error {
+			_, err := client.CreateCollectionItem(ctx, &CreateCollectionItemOption{CollectionID: 1, Link: link, Tags: tags})
+			return err
+		}, []string{"ASMR", "VOICE"}, "https://example.com/", nil},
+		{"UpdateCollectionItem", func() error {
+			_, err := client.UpdateCollectionItem(ctx, &UpdateCollectionItemOption{CollectionID: 1, ItemID: 2, Tags: &tags})
+			return err
+		}, []string{"ASMR", "VOICE"}, "", nil},
+		{"UpdateCheckin/bad link", func() error {
+			_, err := client.UpdateCheckin(ctx, 1, &UpdateCheckinOption{Link: &badLink})
+			return err
+		}, nil, "", errScheme},
+		{"CreateCheckin/duplicate", func() error {
+			_, err := client.CreateCheckin(ctx, &CreateCheckinOption{Link: dup})
+			return err
+		}, nil, "", errDuplicate},
+		{"CreateCollectionItem/duplicate", func() error {
+			_, err := client.CreateCollectionItem(ctx, &CreateCollectionItemOption{CollectionID: 1, Link: dup})
+			return err
+		}, nil, "", errDuplicate},
+	}
+	for _, c := range cases {
+		before := sent()
+		err := c.call()
+		if c.wantErr != nil {
+			if !errors.Is(err, c.wantErr) {
+				t.Errorf("%s: expected %v, got %v", c.name, c.wantErr, err)
+			}
+			if !bytes.Equal(sent(), before) {
+				t.Errorf("%s: request was sent", c.name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", c.name, err)
+			continue
+		}
+		var body struct {
+			Tags []string `json:"tags"`
+			Link string   `json:"link"`
+		}
+		if err := json.Unmarshal(sent(), &body); err != nil {
+			t.Errorf("%s: %v", c.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(body.Tags, c.wantTags) {
+			t.Errorf("%s: sent tags %v, want %v", c.name, body.Tags, c.wantTags)
+		}
+		if body.Link != c.wantLink {
+			t.Errorf("%s: sent link %q, want %q", c.name, body.Link, c.wantLink)
+		}
+	}
+	// 呼び出し側のオプションは書き換えない
+	if !reflect.DeepEqual(tags, []string{"asmr", "voice"}) || link != "https://example.com/?utm_source=x" {
+		t.Errorf("option was modified: %v %s", tags, link)
+	}
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/mohemohe/go-tissue/bulk"
+)
+
+// bulkFlags は一括変更コマンドに共通のフラグ。
+type bulkFlags struct {
+	dryRun   *bool
+	interval *time.Duration
+	progress *string
+}
+
+func addBulkFlags(fs *flag.FlagSet) *bulkFlags {
+	return &bulkFlags{
+		dryRun:   fs.Bool("dry-run", false, "変更せずに変更内容だけを表示する"),
+		interval: fs.Duration("interval", bulk.DefaultInterval, "更新の間隔"),
+		progress: fs.String("progress", "", "進捗ファイル (既定: キャッシュディレクトリにコマンドごとに作る)"),
+	}
+}
+
+// runBulk は --dry-run なら changes を表示し、そうでなければ適用して結果を表示する。
+// 途中で失敗したら進捗ファイルを残すので、同じコマンドを再実行すると適用済みのものを飛ばして再開する。
+func runBulk(ctx context.Context, cli *clientBundle, f *bulkFlags, command string, args []string, changes []bulk.Change) {
+	if changes == nil {
+		changes = []bulk.Change{}
+	}
+	if *f.dryRun {
+		printResult(changes)
+		return
+	}
+	path := *f.progress
+	if path == "" {
+		path = defaultProgressPath(cli.config.Name, command, args)
+	}
+	progress, err := bulk.LoadProgress(path)
+	if err != nil {
+		die("failed to load progress %s: %v", path, err)
+	}
+	if progress.Len() > 0 {
+		fmt.Fprintf(os.Stderr, "resuming: %d change(s) already applied (%s)\n", progress.Len(), path)
+	}
+	r := &bulk.Runner{
+		Client:   cli.api,
+		Interval: *f.interval,
+		Progress: progress,
+		OnApply: func(done, total int, c bulk.Change) {
+			fmt.Fprintf(os.Stderr, "[%d/%d] %s\n", done, total, c)
+		},
+	}
+	result, err := r.Apply(ctx, changes)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "applied %d of %d; progress saved to %s (re-run the same command to resume)\n", result.Applied, result.Planned, path)
+		cli.fail(err)
+	}
+	if err := progress.Remove(); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to remove progress file: %v\n", err)
+	}
+	printResult(result)
+}
+
+// defaultProgressPath はプロファイル・コマンド・引数ごとに決まる進捗ファイルのパスを返す。
+func defaultProgressPath(profile, command string, args []string) string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+	sum := sha256.Sum256([]byte(profile + "\x00" + command + "\x00" + strings.Join(args, "\x00")))
+	name := strings.ReplaceAll(command, " ", "-") + "-" + hex.EncodeToString(sum[:8]) + ".json"
+	return filepath.Join(dir, "tissue", "progress", name)
+}
@@ -0,0 +1,220 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	tissue "github.com/mohemohe/go-tissue"
+	"github.com/mohemohe/go-tissue/api"
+)
+
+// authMethods は認証方式と、その方式で実装されている操作 (接続先での有無は問わない)。
+var authMethods = []struct {
+	name string
+	ops  func() []tissue.Operation
+}{
+	{authMethodToken, api.SupportedOperations},
+	{authMethodAccount, tissue.SupportedOperations},
+	{authMethodHybrid, api.HybridSupportedOperations},
+}
+
+func methodSupports(method string, op tissue.Operation) bool {
+	for _, m := range authMethods {
+		if m.name == method {
+			for _, o := range m.ops() {
+				if o == op {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+func methodsSupporting(op tissue.Operation) []string {
+	var names []string
+	for _, m := range authMethods {
+		if methodSupports(m.name, op) {
+			names = append(names, m.name)
+		}
+	}
+	return names
+}
+
+// require は現在の認証方式で op が実装されていなければ、使える認証方式を添えて終了する。
+// 以降の fail は op を対象としてエラーを説明する。
+func (b *clientBundle) require(op tissue.Operation) {
+	b.op = op
+	if methodSupports(b.config.AuthMethod, op) {
+		return
+	}
+	methods := methodsSupporting(op)
+	if len(methods) == 0 {
+		die("%s is not available with auth method %s", op, b.config.AuthMethod)
+	}
+	die("%s is not available with auth method %s (available with: %s)\n"+
+		"switch with `tissue configure --method %s`, or choose such a profile with --profile",
+		op, b.config.AuthMethod, strings.Join(methods, ", "), methods[0])
+}
+
+// requireAPI は API トークン版のクライアント (b.api) を使うコマンドで、それを持たない認証方式なら終了する。
+func (b *clientBundle) requireAPI(command string) {
+	if b.api != nil {
+		return
+	}
+	die("%s is not available with auth method %s (available with: %s, %s)\n"+
+		"switch with `tissue configure --method %s`, or choose such a profile with --profile",
+		command, b.config.AuthMethod, authMethodToken, authMethodHybrid, authMethodToken)
+}
+
+// fail は require で指定した操作の失敗として終了する。
+func (b *clientBundle) fail(err error) {
+	b.failWith(b.op, err)
+}
+
+// failWith は err で終了する。404 の場合は接続先が op を提供しているかを確認し、
+// 提供していなければ古い Tissue である可能性を伝える。
+func (b *clientBundle) failWith(op tissue.Operation, err error) {
+	if op != "" && tissue.IsNotFound(err) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if caps, perr := b.capabilities(ctx); perr == nil && !caps.Has(op) {
+			die("%s is not provided by %s (the instance may run an older Tissue): %v", op, caps.BaseURL, err)
+		}
+	}
+	if ve, ok := tissue.AsValidationError(err); ok && len(ve.Violations) > 0 {
+		fmt.Fprintln(os.Stderr, ve.Message)
+		for _, v := range ve.Violations {
+			if v.Field != "" {
+				fmt.Fprintf(os.Stderr, "  %s: %s\n", v.Field, v.Message)
+			} else {
+				fmt.Fprintf(os.Stderr, "  %s\n", v.Message)
+			}
+		}
+		os.Exit(1)
+	}
+	die("%v", err)
+}
+
+func (b *clientBundle) capabilities(ctx context.Context) (*tissue.Capabilities, error) {
+	switch b.config.AuthMethod {
+	case authMethodToken, authMethodHybrid:
+		return b.api.Capabilities(ctx)
+	case authMethodAccount:
+		return b.scraping.Capabilities(ctx)
+	}
+	return nil, fmt.Errorf("unknown auth method: %s", b.config.AuthMethod)
+}
+
+var (
+	helpMethodCache *string
+	// helpHidden は printCommand が1行以上を省略したかどうか。
+	helpHidden bool
+)
+
+// helpMethod はヘルプでコマンドを絞り込むための認証方式。設定が無ければ空で、すべて表示する。
+func helpMethod() string {
+	if helpMethodCache == nil {
+		m := ""
+		if cfg, _, err := resolveConfig(); err == nil {
+			m = cfg.AuthMethod
+		}
+		helpMethodCache = &m
+	}
+	return *helpMethodCache
+}
+
+// printCommand はヘルプの1行を表示する。現在の認証方式で ops のいずれも使えなければ表示しない。
+func printCommand(line string, ops ...tissue.Operation) {
+	method := helpMethod()
+	if method != "" && len(ops) > 0 {
+		supported := false
+		for _, op := range ops {
+			supported = supported || methodSupports(method, op)
+		}
+		if !supported {
+			helpHidden = true
+			return
+		}
+	}
+	fmt.Fprintln(os.Stderr, line)
+}
+
+// printAPICommand は requireAPI を使うコマンドのヘルプの1行を表示する。account では表示しない。
+func printAPICommand(line string, ops ...tissue.Operation) {
+	if helpMethod() == authMethodAccount {
+		helpHidden = true
+		return
+	}
+	printCommand(line, ops...)
+}
+
+// printHiddenNote は printCommand が省略した行があれば、その旨を表示する。
+func printHiddenNote() {
+	if helpHidden {
+		fmt.Fprintf(os.Stderr, "  (認証方式 %s で使えないコマンドは表示していません。tissue capabilities で確認できます)\n", helpMethod())
+	}
+}
+
+func cmdCapabilities(args []string) {
+	fs := newFlagSet("capabilities")
+	setUsage(fs, "tissue capabilities [--probe URL]")
+	probeURL := fs.String("probe", "", "設定を使わず、指定した URL のインスタンスを両方の認証方式について調べる")
+	_ = fs.Parse(args)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if *probeURL != "" {
+		token, err := api.Probe(ctx, *probeURL)
+		if err != nil {
+			die("%v", err)
+		}
+		account, err := tissue.Probe(ctx, *probeURL)
+		if err != nil {
+			die("%v", err)
+		}
+		rows := make([]probeRow, len(tissue.AllOperations))
+		for i, op := range tissue.AllOperations {
+			rows[i] = probeRow{Operation: op, Token: token.Has(op), Account: account.Has(op)}
+		}
+		printResult(rows)
+		return
+	}
+
+	// 問い合わせに認証は不要なので、秘密情報は解決しない
+	cfg := mustResolveConfig()
+	var caps *tissue.Capabilities
+	var err error
+	switch cfg.AuthMethod {
+	case authMethodToken:
+		caps, err = api.Probe(ctx, cfg.BaseURL)
+	case authMethodAccount:
+		caps, err = tissue.Probe(ctx, cfg.BaseURL)
+	case authMethodHybrid:
+		caps, err = api.ProbeHybrid(ctx, cfg.BaseURL)
+	default:
+		die("unknown auth_method: %q", cfg.AuthMethod)
+	}
+	if err != nil {
+		die("%v", err)
+	}
+	rows := make([]capabilityRow, len(tissue.AllOperations))
+	for i, op := range tissue.AllOperations {
+		rows[i] = capabilityRow{Operation: op, Available: caps.Has(op)}
+	}
+	printResult(rows)
+}
+
+type capabilityRow struct {
+	Operation tissue.Operation `json:"operation"`
+	Available bool             `json:"available"`
+}
+
+type probeRow struct {
+	Operation tissue.Operation `json:"operation"`
+	Token     bool             `json:"token"`
+	Account   bool             `json:"account"`
+}
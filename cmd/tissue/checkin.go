@@ -2,15 +2,14 @@ package main
 
 import (
 	"context"
-	"flag"
 	"fmt"
 	"os"
 	"strconv"
 	"strings"
-	"time"
 
 	tissue "github.com/mohemohe/go-tissue"
 	"github.com/mohemohe/go-tissue/api"
+	"github.com/mohemohe/go-tissue/suggest"
 )
 
 func cmdCheckin(args []string) {
@@ -30,6 +29,10 @@ func cmdCheckin(args []string) {
 		cmdCheckinUpdate(rest)
 	case "delete":
 		cmdCheckinDelete(rest)
+	case "bulk-update":
+		cmdCheckinBulkUpdate(rest)
+	case "bulk-delete":
+		cmdCheckinBulkDelete(rest)
 	case "-h", "--help", "help":
 		usageCheckin()
 	default:
@@ -39,24 +42,33 @@ func cmdCheckin(args []string) {
 
 func usageCheckin() {
 	fmt.Fprintln(os.Stderr, "usage: tissue checkin <subcommand>")
-	fmt.Fprintln(os.Stderr, "  add     チェックインを作成")
-	fmt.Fprintln(os.Stderr, "  list    ユーザーのチェックイン一覧")
-	fmt.Fprintln(os.Stderr, "  get     チェックイン詳細")
-	fmt.Fprintln(os.Stderr, "  update  チェックイン更新")
-	fmt.Fprintln(os.Stderr, "  delete  チェックイン削除")
+	printCommand("  add     チェックインを作成", tissue.OpCheckinCreate)
+	printCommand("  list    ユーザーのチェックイン一覧", tissue.OpUserCheckins)
+	printCommand("  get     チェックイン詳細", tissue.OpCheckinGet)
+	printCommand("  update  チェックイン更新", tissue.OpCheckinUpdate)
+	printCommand("  delete  チェックイン削除", tissue.OpCheckinDelete)
+	printCommand("  bulk-update  条件か標準入力の ID で選んだチェックインを一括更新", tissue.OpCheckinUpdate)
+	printCommand("  bulk-delete  条件か標準入力の ID で選んだチェックインを一括削除", tissue.OpCheckinDelete)
+	printHiddenNote()
 }
 
 func cmdCheckinAdd(args []string) {
-	fs := flag.NewFlagSet("checkin add", flag.ExitOnError)
+	fs := newFlagSet("checkin add")
 	tagList := fs.String("tags", "", "カンマ区切りのタグ")
 	link := fs.String("link", "", "オカズリンク")
 	note := fs.String("note", "", "ノート")
 	private := fs.Bool("private", false, "非公開フラグ")
 	sensitive := fs.Bool("sensitive", false, "過激フラグ")
 	discard := fs.Bool("discard-elapsed-time", false, "経過時間を記録しない")
-	at := fs.String("at", "", "チェックイン日時 (RFC3339)")
+	at := fs.String("at", "", "チェックイン日時 (now / -30m / yesterday 23:10 / 2024-05-01 23:10 / RFC3339 など)")
+	preview := fs.Bool("preview", false, "投稿前にリンク先のタイトルを標準エラー出力に表示する")
+	interactive := fs.Bool("suggest-tags", false, "履歴とリンク先からタグの候補を表示し、選んだものを追加する")
+	auto := fs.Bool("auto-tags", false, "確度の高いタグの候補を自動で追加する")
 	_ = fs.Parse(args)
 
+	if *preview && *link == "" {
+		die("--preview requires --link")
+	}
 	var tags []string
 	if *tagList != "" {
 		for _, t := range strings.Split(*tagList, ",") {
@@ -65,19 +77,29 @@ func cmdCheckinAdd(args []string) {
 			}
 		}
 	}
-	var checkedAt *time.Time
+	var checkedAt *tissue.Timestamp
 	if *at != "" {
-		t, err := time.Parse(time.RFC3339, *at)
-		if err != nil {
-			die("invalid --at: %v", err)
-		}
-		checkedAt = &t
+		checkedAt = tissue.NewTimestamp(mustParseInstant("--at", *at))
 	}
 
 	cli := buildClient()
+	cli.require(tissue.OpCheckinCreate)
 	ctx := context.Background()
+	if *preview {
+		previewLink(ctx, *link)
+	}
+	if *interactive || *auto {
+		suggestions := cli.suggestTags(ctx, *link, *note, suggest.DefaultLimit)
+		if *auto {
+			tags = autoTags(tags, suggestions)
+		}
+		if *interactive {
+			tags = promptTags(tags, suggestions)
+		}
+		cli.op = tissue.OpCheckinCreate
+	}
 	switch cli.config.AuthMethod {
-	case authMethodToken:
+	case authMethodToken, authMethodHybrid:
 		result, err := cli.api.CreateCheckin(ctx, &api.CreateCheckinOption{
 			CheckedInAt:        checkedAt,
 			Tags:               tags,
@@ -88,9 +110,9 @@ func cmdCheckinAdd(args []string) {
 			DiscardElapsedTime: *discard,
 		})
 		if err != nil {
-			die("%v", err)
+			cli.fail(err)
 		}
-		printJSON(result)
+		printResult(result)
 	case authMethodAccount:
 		result, err := cli.scraping.CreateCheckin(ctx, &tissue.CreateCheckinOption{
 			CheckedInAt:        checkedAt,
@@ -102,50 +124,59 @@ func cmdCheckinAdd(args []string) {
 			DiscardElapsedTime: *discard,
 		})
 		if err != nil {
-			die("%v", err)
+			cli.fail(err)
 		}
-		printJSON(result)
+		printResult(result)
 	default:
 		die("unknown method: %s", cli.config.AuthMethod)
 	}
 }
 
 func cmdCheckinList(args []string) {
-	fs := flag.NewFlagSet("checkin list", flag.ExitOnError)
+	fs := newFlagSet("checkin list")
 	page := fs.Int("page", 1, "ページ")
 	perPage := fs.Int("per-page", 20, "1ページ当たり件数")
+	since := fs.String("since", "", "この日時以降 (2024-05-01 / last week / -3d など)")
+	until := fs.String("until", "", "この日時以前 (期間を指す表現ならその終わりまで)")
 	_ = fs.Parse(args)
+	period := mustParseRange(*since, *until)
 
 	cli := buildClient()
+	cli.require(tissue.OpUserCheckins)
 	ctx := context.Background()
 	name := cli.meName(ctx)
 
 	switch cli.config.AuthMethod {
-	case authMethodToken:
+	case authMethodToken, authMethodHybrid:
 		result, err := cli.api.UserCheckins(ctx, name, &api.UserCheckinsOption{
 			Page:    *page,
 			PerPage: *perPage,
+			Since:   period.since,
+			Until:   period.until,
 		})
 		if err != nil {
-			die("%v", err)
+			cli.fail(err)
 		}
-		printJSON(result)
+		printResult(filterCheckinsByTime(result, period))
 	case authMethodAccount:
+		if !period.isZero() {
+			die("--since/--until are not available for method %s", cli.config.AuthMethod)
+		}
 		result, err := cli.scraping.UserCheckins(ctx, name, &tissue.UserCheckinsOption{
 			Page:    *page,
 			PerPage: *perPage,
 		})
 		if err != nil {
-			die("%v", err)
+			cli.fail(err)
 		}
-		printJSON(result)
+		printResult(result)
 	default:
 		die("list is not available for method %s", cli.config.AuthMethod)
 	}
 }
 
 func cmdCheckinGet(args []string) {
-	fs := flag.NewFlagSet("checkin get", flag.ExitOnError)
+	fs := newFlagSet("checkin get")
 	setUsage(fs, "tissue checkin get <id>")
 	pos := parseMixed(fs, args)
 	if len(pos) < 1 {
@@ -156,27 +187,22 @@ func cmdCheckinGet(args []string) {
 		die("invalid id: %v", err)
 	}
 	cli := buildClient()
+	cli.require(tissue.OpCheckinGet)
 	ctx := context.Background()
 	switch cli.config.AuthMethod {
-	case authMethodToken:
+	case authMethodToken, authMethodHybrid:
 		result, err := cli.api.GetCheckin(ctx, id)
 		if err != nil {
-			die("%v", err)
+			cli.fail(err)
 		}
-		printJSON(result)
-	case authMethodAccount:
-		result, err := cli.scraping.GetCheckin(ctx, id)
-		if err != nil {
-			die("%v", err)
-		}
-		printJSON(result)
+		printResult(result)
 	default:
 		die("get is not available for method %s", cli.config.AuthMethod)
 	}
 }
 
 func cmdCheckinUpdate(args []string) {
-	fs := flag.NewFlagSet("checkin update", flag.ExitOnError)
+	fs := newFlagSet("checkin update")
 	setUsage(fs, "tissue checkin update <id> [options]")
 	note := fs.String("note", "", "ノート")
 	link := fs.String("link", "", "オカズリンク")
@@ -185,7 +211,7 @@ func cmdCheckinUpdate(args []string) {
 	private := fs.String("private", "", "非公開フラグ (true/false)")
 	sensitive := fs.String("sensitive", "", "過激フラグ (true/false)")
 	discard := fs.String("discard-elapsed-time", "", "経過時間を記録しない (true/false)")
-	at := fs.String("at", "", "チェックイン日時 (RFC3339)")
+	at := fs.String("at", "", "チェックイン日時 (now / -30m / yesterday 23:10 / 2024-05-01 23:10 / RFC3339 など)")
 	pos := parseMixed(fs, args)
 	if len(pos) < 1 {
 		die("usage: tissue checkin update <id> [options]")
@@ -198,7 +224,7 @@ func cmdCheckinUpdate(args []string) {
 	var notePtr, linkPtr *string
 	var tagsPtr *[]string
 	var privatePtr, sensitivePtr, discardPtr *bool
-	var atPtr *time.Time
+	var atPtr *tissue.Timestamp
 
 	if *note != "" {
 		notePtr = note
@@ -227,17 +253,14 @@ func cmdCheckinUpdate(args []string) {
 		discardPtr = &b
 	}
 	if *at != "" {
-		t, err := time.Parse(time.RFC3339, *at)
-		if err != nil {
-			die("invalid --at: %v", err)
-		}
-		atPtr = &t
+		atPtr = tissue.NewTimestamp(mustParseInstant("--at", *at))
 	}
 
 	cli := buildClient()
+	cli.require(tissue.OpCheckinUpdate)
 	ctx := context.Background()
 	switch cli.config.AuthMethod {
-	case authMethodToken:
+	case authMethodToken, authMethodHybrid:
 		result, err := cli.api.UpdateCheckin(ctx, id, &api.UpdateCheckinOption{
 			CheckedInAt:        atPtr,
 			Tags:               tagsPtr,
@@ -248,30 +271,16 @@ func cmdCheckinUpdate(args []string) {
 			DiscardElapsedTime: discardPtr,
 		})
 		if err != nil {
-			die("%v", err)
+			cli.fail(err)
 		}
-		printJSON(result)
-	case authMethodAccount:
-		result, err := cli.scraping.UpdateCheckin(ctx, id, &tissue.UpdateCheckinOption{
-			CheckedInAt:        atPtr,
-			Tags:               tagsPtr,
-			Link:               linkPtr,
-			Note:               notePtr,
-			IsPrivate:          privatePtr,
-			IsTooSensitive:     sensitivePtr,
-			DiscardElapsedTime: discardPtr,
-		})
-		if err != nil {
-			die("%v", err)
-		}
-		printJSON(result)
+		printResult(result)
 	default:
 		die("update is not available for method %s", cli.config.AuthMethod)
 	}
 }
 
 func cmdCheckinDelete(args []string) {
-	fs := flag.NewFlagSet("checkin delete", flag.ExitOnError)
+	fs := newFlagSet("checkin delete")
 	setUsage(fs, "tissue checkin delete <id>")
 	pos := parseMixed(fs, args)
 	if len(pos) < 1 {
@@ -282,15 +291,12 @@ func cmdCheckinDelete(args []string) {
 		die("invalid id: %v", err)
 	}
 	cli := buildClient()
+	cli.require(tissue.OpCheckinDelete)
 	ctx := context.Background()
 	switch cli.config.AuthMethod {
-	case authMethodToken:
+	case authMethodToken, authMethodHybrid:
 		if err := cli.api.DeleteCheckin(ctx, id); err != nil {
-			die("%v", err)
-		}
-	case authMethodAccount:
-		if err := cli.scraping.DeleteCheckin(ctx, id); err != nil {
-			die("%v", err)
+			cli.fail(err)
 		}
 	default:
 		die("delete is not available for method %s", cli.config.AuthMethod)
@@ -298,6 +304,20 @@ func cmdCheckinDelete(args []string) {
 	fmt.Fprintln(os.Stderr, "deleted.")
 }
 
+// filterCheckinsByTime は日付単位で絞り込まれた一覧を、時刻まで含めて r の範囲に絞る。
+func filterCheckinsByTime(checkins []tissue.Checkin, r timeRange) []tissue.Checkin {
+	if r.isZero() {
+		return checkins
+	}
+	result := []tissue.Checkin{}
+	for _, c := range checkins {
+		if r.contains(c.CheckedInAt.Time) {
+			result = append(result, c)
+		}
+	}
+	return result
+}
+
 func parseOptionalBool(s string) (bool, bool) {
 	if s == "" {
 		return false, false
@@ -0,0 +1,332 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	tissue "github.com/mohemohe/go-tissue"
+	"github.com/mohemohe/go-tissue/api"
+	"github.com/mohemohe/go-tissue/bulk"
+)
+
+// checkinSelection は bulk-update / bulk-delete の対象を選ぶフラグ。
+type checkinSelection struct {
+	since       *string
+	until       *string
+	tags        *string
+	hasLink     *string
+	private     *string
+	source      *string
+	all         *bool
+	stdin       *bool
+	yes         *bool
+	dryRun      *bool
+	concurrency *int
+	interval    *time.Duration
+}
+
+func addSelectionFlags(fs *flag.FlagSet) *checkinSelection {
+	return &checkinSelection{
+		since:       fs.String("since", "", "この日時以降 (2024-05-01 / last week / -3d など)"),
+		until:       fs.String("until", "", "この日時以前 (期間を指す表現ならその終わりまで)"),
+		tags:        fs.String("tag", "", "すべて含むタグ (カンマ区切り)"),
+		hasLink:     fs.String("has-link", "", "リンクの有無 (true/false)"),
+		private:     fs.String("private", "", "非公開かどうか (true/false)"),
+		source:      fs.String("source", "", "登録元 (web / csv / webhook / api)"),
+		all:         fs.Bool("all", false, "条件を付けずに全チェックインを対象にする"),
+		stdin:       fs.Bool("stdin", false, "対象の ID を標準入力から読む (1行1件の ID / NDJSON / JSON 配列)"),
+		yes:         fs.Bool("yes", false, "確認せずに実行する"),
+		dryRun:      fs.Bool("dry-run", false, "対象を表示するだけで実行しない"),
+		concurrency: fs.Int("concurrency", bulk.DefaultConcurrency, "同時に実行する数"),
+		interval:    fs.Duration("interval", 0, "各操作を開始する間隔"),
+	}
+}
+
+func (s *checkinSelection) query() (*bulk.CheckinQuery, bool) {
+	q := &bulk.CheckinQuery{Source: *s.source, Tags: splitTags(*s.tags)}
+	period := mustParseRange(*s.since, *s.until)
+	q.Since, q.Until = period.since, period.until
+	if b, ok := parseOptionalBool(*s.hasLink); ok {
+		q.HasLink = &b
+	}
+	if b, ok := parseOptionalBool(*s.private); ok {
+		q.IsPrivate = &b
+	}
+	filtered := !q.Since.IsZero() || !q.Until.IsZero() || len(q.Tags) > 0 || q.HasLink != nil || q.IsPrivate != nil || q.Source != ""
+	return q, filtered
+}
+
+// validate は選び方の指定を確認し、追加で必要な操作を返す。ビルド前に呼ぶ。
+func (s *checkinSelection) validate() []tissue.Operation {
+	_, filtered := s.query()
+	switch {
+	case *s.stdin && (filtered || *s.all):
+		die("--stdin cannot be combined with filters or --all")
+	case *s.stdin:
+		if !*s.yes && !*s.dryRun {
+			die("--yes is required with --stdin (stdin is used for the IDs)")
+		}
+		if mustResolveConfig().needsPassphraseInput() {
+			die("--stdin cannot be used while the secret file passphrase is read from the terminal; set TISSUE_SECRET_PASSPHRASE")
+		}
+		return nil
+	case !filtered && !*s.all:
+		die("specify filters (--since/--until/--tag/--has-link/--private/--source), --all or --stdin")
+	}
+	return []tissue.Operation{tissue.OpUserCheckins}
+}
+
+// selectCheckins は対象のチェックインを返す。--stdin のときは ID だけが入ったものを返す。
+func (s *checkinSelection) selectCheckins(ctx context.Context, cli *clientBundle) []tissue.Checkin {
+	if *s.stdin {
+		ids, err := parseIDs(stdinReader)
+		if err != nil {
+			die("failed to read IDs from stdin: %v", err)
+		}
+		checkins := make([]tissue.Checkin, len(ids))
+		for i, id := range ids {
+			checkins[i] = tissue.Checkin{ID: id}
+		}
+		return checkins
+	}
+	q, _ := s.query()
+	cli.op = tissue.OpUserCheckins
+	checkins, err := bulk.SelectCheckins(ctx, cli.api, cli.meName(ctx), q)
+	if err != nil {
+		cli.fail(err)
+	}
+	return filterCheckinsByTime(checkins, timeRange{since: q.Since, until: q.Until})
+}
+
+// confirm は対象の概要を標準エラー出力に表示し、--yes が無ければ確認する。false なら中止。
+func (s *checkinSelection) confirm(action string, checkins []tissue.Checkin) bool {
+	fmt.Fprintf(os.Stderr, "%s %d checkin(s):\n", action, len(checkins))
+	const shown = 10
+	for i, c := range checkins {
+		if i == shown {
+			fmt.Fprintf(os.Stderr, "  ... and %d more\n", len(checkins)-shown)
+			break
+		}
+		if c.CheckedInAt.IsZero() {
+			fmt.Fprintf(os.Stderr, "  %d\n", c.ID)
+			continue
+		}
+		fmt.Fprintf(os.Stderr, "  %d  %s  %s  %s\n", c.ID, formatLocalTime(c.CheckedInAt.Time), strings.Join(c.Tags, ","), c.Link)
+	}
+	return *s.yes || confirm("continue?")
+}
+
+// confirm は prompt を表示して標準入力から y/N を読む。
+func confirm(prompt string) bool {
+	fmt.Fprint(os.Stderr, prompt+" [y/N]: ")
+	answer := strings.ToLower(readLine())
+	return answer == "y" || answer == "yes"
+}
+
+// readLine は標準入力から1行読み、前後の空白を除いて返す。
+func readLine() string {
+	line, _ := readInput()
+	return line
+}
+
+// readInput は readLine と同じく1行読む。入力が終わっていて何も読めなければ false。
+func readInput() (string, bool) {
+	line, err := stdinReader.ReadString('\n')
+	if err != nil && line == "" {
+		return "", false
+	}
+	return strings.TrimSpace(line), true
+}
+
+// run は checkins のそれぞれに action を適用し、1件ごとの結果を表示する。失敗があれば終了コード 1。
+func (s *checkinSelection) run(ctx context.Context, checkins []tissue.Checkin, action func(ctx context.Context, id int64) error) {
+	ids := make([]int64, len(checkins))
+	for i, c := range checkins {
+		ids[i] = c.ID
+	}
+	results := bulk.Each(ctx, ids, &bulk.EachOption{
+		Concurrency: *s.concurrency,
+		Interval:    *s.interval,
+		OnResult: func(r bulk.ItemResult) {
+			if r.OK {
+				fmt.Fprintf(os.Stderr, "ok %d\n", r.ID)
+			} else {
+				fmt.Fprintf(os.Stderr, "failed %d: %s\n", r.ID, r.Error)
+			}
+		},
+	}, action)
+	reportEach(results)
+}
+
+func cmdCheckinBulkUpdate(args []string) {
+	fs := newFlagSet("checkin bulk-update")
+	sel := addSelectionFlags(fs)
+	note := fs.String("set-note", "", "ノートを変更")
+	link := fs.String("set-link", "", "リンクを変更")
+	setTags := fs.String("set-tags", "", "タグを置き換える (カンマ区切り、空文字でクリア)")
+	addTags := fs.String("add-tags", "", "タグを追加 (カンマ区切り)")
+	removeTags := fs.String("remove-tags", "", "タグを削除 (カンマ区切り)")
+	private := fs.String("set-private", "", "非公開フラグを変更 (true/false)")
+	sensitive := fs.String("set-sensitive", "", "過激フラグを変更 (true/false)")
+	discard := fs.String("set-discard-elapsed-time", "", "経過時間を記録しないかを変更 (true/false)")
+	_ = fs.Parse(args)
+
+	// 空文字でのクリアを指定なしと区別するため、明示されたフラグだけを変更する
+	given := map[string]bool{}
+	fs.Visit(func(f *flag.Flag) { given[f.Name] = true })
+	option := api.UpdateCheckinOption{}
+	if given["set-note"] {
+		option.Note = note
+	}
+	if given["set-link"] {
+		option.Link = link
+	}
+	if given["set-tags"] {
+		tags := splitTags(*setTags)
+		option.Tags = &tags
+	}
+	if b, ok := parseOptionalBool(*private); ok {
+		option.IsPrivate = &b
+	}
+	if b, ok := parseOptionalBool(*sensitive); ok {
+		option.IsTooSensitive = &b
+	}
+	if b, ok := parseOptionalBool(*discard); ok {
+		option.DiscardElapsedTime = &b
+	}
+	add, remove := splitTags(*addTags), splitTags(*removeTags)
+	if option.Tags != nil && (len(add) > 0 || len(remove) > 0) {
+		die("--set-tags cannot be combined with --add-tags/--remove-tags")
+	}
+	if option == (api.UpdateCheckinOption{}) && len(add) == 0 && len(remove) == 0 {
+		die("no changes specified (--set-*, --add-tags or --remove-tags)")
+	}
+
+	ops := append(sel.validate(), tissue.OpCheckinUpdate)
+	// 標準入力で ID だけを受け取ったときは、タグの追加・削除のために現在のタグを取得する
+	fetch := *sel.stdin && (len(add) > 0 || len(remove) > 0)
+	if fetch {
+		ops = append(ops, tissue.OpCheckinGet)
+	}
+	cli := buildClient()
+	for _, op := range ops {
+		cli.require(op)
+	}
+	ctx := context.Background()
+	checkins := sel.selectCheckins(ctx, cli)
+	if *sel.dryRun {
+		printResult(checkins)
+		return
+	}
+	if !sel.confirm("update", checkins) {
+		die("aborted.")
+	}
+	known := map[int64]tissue.Checkin{}
+	for _, c := range checkins {
+		known[c.ID] = c
+	}
+	sel.run(ctx, checkins, func(ctx context.Context, id int64) error {
+		o := option
+		if len(add) > 0 || len(remove) > 0 {
+			current := known[id]
+			if fetch {
+				c, err := cli.api.GetCheckin(ctx, id)
+				if err != nil {
+					return err
+				}
+				current = *c
+			}
+			tags := editTags(current.Tags, add, remove)
+			o.Tags = &tags
+		}
+		_, err := cli.api.UpdateCheckin(ctx, id, &o)
+		return err
+	})
+}
+
+func cmdCheckinBulkDelete(args []string) {
+	fs := newFlagSet("checkin bulk-delete")
+	sel := addSelectionFlags(fs)
+	_ = fs.Parse(args)
+
+	ops := append(sel.validate(), tissue.OpCheckinDelete)
+	cli := buildClient()
+	for _, op := range ops {
+		cli.require(op)
+	}
+	ctx := context.Background()
+	checkins := sel.selectCheckins(ctx, cli)
+	if *sel.dryRun {
+		printResult(checkins)
+		return
+	}
+	if !sel.confirm("delete", checkins) {
+		die("aborted.")
+	}
+	sel.run(ctx, checkins, cli.api.DeleteCheckin)
+}
+
+// editTags は tags に add を加え、remove を取り除いた結果を返す。大文字小文字は区別しない。
+func editTags(tags, add, remove []string) []string {
+	result := []string{}
+	for _, t := range tags {
+		if !containsTag(remove, t) {
+			result = append(result, t)
+		}
+	}
+	return mergeTags(result, add)
+}
+
+func splitTags(s string) []string {
+	var tags []string
+	for _, t := range strings.Split(s, ",") {
+		if trimmed := strings.TrimSpace(t); trimmed != "" {
+			tags = append(tags, trimmed)
+		}
+	}
+	return tags
+}
+
+// parseIDs は1行1件の ID、{"id": ...} の NDJSON、または JSON 配列 (ID か {"id": ...} の配列) から ID を読む。
+func parseIDs(r io.Reader) ([]int64, error) {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	b = bytes.TrimSpace(b)
+	var raw []json.RawMessage
+	if bytes.HasPrefix(b, []byte("[")) {
+		if err := json.Unmarshal(b, &raw); err != nil {
+			return nil, err
+		}
+	} else {
+		for _, line := range bytes.Split(b, []byte("\n")) {
+			if line = bytes.TrimSpace(line); len(line) > 0 {
+				raw = append(raw, line)
+			}
+		}
+	}
+	ids := make([]int64, 0, len(raw))
+	for _, m := range raw {
+		var obj struct {
+			ID *int64 `json:"id"`
+		}
+		if id, err := strconv.ParseInt(string(m), 10, 64); err == nil {
+			ids = append(ids, id)
+		} else if json.Unmarshal(m, &obj) == nil && obj.ID != nil {
+			ids = append(ids, *obj.ID)
+		} else {
+			return nil, errors.New("invalid id: " + string(m))
+		}
+	}
+	return ids, nil
+}
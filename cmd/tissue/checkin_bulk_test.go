@@ -0,0 +1,43 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseIDs(t *testing.T) {
+	cases := []struct {
+		name  string
+		input string
+		want  []int64
+	}{
+		{"lines", "1\n\n2\n 3 \n", []int64{1, 2, 3}},
+		{"ndjson", "{\"id\":10,\"note\":\"x\"}\n{\"id\":11}\n", []int64{10, 11}},
+		{"array", "[1, 2]", []int64{1, 2}},
+		{"object array", "[{\"id\":5},{\"id\":6}]", []int64{5, 6}},
+		{"empty", "", []int64{}},
+	}
+	for _, c := range cases {
+		got, err := parseIDs(strings.NewReader(c.input))
+		if err != nil {
+			t.Errorf("%s: %v", c.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, c.want) {
+			t.Errorf("%s: got %v, want %v", c.name, got, c.want)
+		}
+	}
+	for _, input := range []string{"abc", "{\"note\":\"x\"}", "[1,"} {
+		if _, err := parseIDs(strings.NewReader(input)); err == nil {
+			t.Errorf("%q: expected error", input)
+		}
+	}
+}
+
+func TestEditTags(t *testing.T) {
+	got := editTags([]string{"A", "b", "C"}, []string{"d", "a"}, []string{"B"})
+	if want := []string{"A", "C", "d"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
@@ -2,40 +2,129 @@ package main
 
 import (
 	"context"
+	"time"
 
 	tissue "github.com/mohemohe/go-tissue"
 	"github.com/mohemohe/go-tissue/api"
+	"github.com/mohemohe/go-tissue/dedupe"
+	"github.com/mohemohe/go-tissue/linkcanon"
+	"github.com/mohemohe/go-tissue/tagrule"
 )
 
+// tokenClient は API トークン版の操作。hybrid では *api.HybridClient がスクレイピング版への切り替えも含めて担う。
+type tokenClient interface {
+	Capabilities(ctx context.Context) (*tissue.Capabilities, error)
+	Me(ctx context.Context) (*tissue.Me, error)
+	UserCheckins(ctx context.Context, name string, option *api.UserCheckinsOption) ([]tissue.Checkin, error)
+	UserCollections(ctx context.Context, name string, option *api.PageOption) ([]tissue.Collection, error)
+	UserDailyCheckinStats(ctx context.Context, name string, option *api.UserStatsPeriodOption) ([]tissue.DailyCheckinCount, error)
+	UserHourlyCheckinStats(ctx context.Context, name string, option *api.UserStatsPeriodOption) ([]api.HourlyCheckinSummary, error)
+	UserTagStats(ctx context.Context, name string, option *api.UserStatsPeriodOption) ([]tissue.TagCount, error)
+	UserLinkStats(ctx context.Context, name string, option *api.UserStatsPeriodOption) ([]api.LinkCount, error)
+	RecentTags(ctx context.Context) ([]string, error)
+	CreateCheckin(ctx context.Context, option *api.CreateCheckinOption) (*tissue.Checkin, error)
+	GetCheckin(ctx context.Context, id int64) (*tissue.Checkin, error)
+	UpdateCheckin(ctx context.Context, id int64, option *api.UpdateCheckinOption) (*tissue.Checkin, error)
+	DeleteCheckin(ctx context.Context, id int64) error
+	CreateCollection(ctx context.Context, option *api.CreateCollectionOption) (*tissue.Collection, error)
+	UpdateCollection(ctx context.Context, id int64, option *api.UpdateCollectionOption) (*tissue.Collection, error)
+	DeleteCollection(ctx context.Context, id int64) error
+	ListCollectionItems(ctx context.Context, collectionID int64, option *api.PageOption) ([]tissue.CollectionItem, error)
+	CreateCollectionItem(ctx context.Context, collectionID int64, option *api.CreateCollectionItemOption) (*tissue.CollectionItem, error)
+	UpdateCollectionItem(ctx context.Context, collectionID, itemID int64, option *api.UpdateCollectionItemOption) (*tissue.CollectionItem, error)
+	DeleteCollectionItem(ctx context.Context, collectionID, itemID int64) error
+	SearchCheckins(ctx context.Context, option *api.SearchOption) ([]tissue.Checkin, error)
+}
+
 type clientBundle struct {
 	config   *Config
 	scraping *tissue.Client
-	api      *api.Client
+	api      tokenClient
+	// hybrid は auth_method が hybrid のときだけ設定される。api / scraping も併せて設定される。
+	hybrid *api.HybridClient
+	// tagRules はタグの正規化ルール。設定されていなければ nil。
+	tagRules *tagrule.Rules
+	// linkRules はリンクの正規化ルール。設定されていなければ nil。
+	linkRules *linkcanon.Canonicalizer
+	// op は require で指定された、これから行う操作。
+	op tissue.Operation
 }
 
 func buildClient() *clientBundle {
-	cfg := mustLoadConfig()
+	cfg := mustResolveConfig()
 	b := &clientBundle{config: cfg}
+	var normalizer tissue.TagNormalizer
+	if cfg.TagRules != "" {
+		b.tagRules = mustLoadTagRules(cfg.TagRules)
+		normalizer = b.tagRules
+	}
+	var canonicalizer tissue.LinkCanonicalizer
+	if cfg.LinkRules != "" {
+		b.linkRules = mustLoadLinkRules(cfg.LinkRules)
+		canonicalizer = b.linkRules
+	}
+	var guard tissue.DuplicateGuard
+	if window := mustParseDuplicateWindow(cfg); window > 0 {
+		guard = &dedupe.Guard{Detector: *b.detector(window)}
+	}
 	switch cfg.AuthMethod {
 	case authMethodToken:
+		token, err := cfg.accessToken()
+		if err != nil {
+			die("failed to resolve access token: %v", err)
+		}
 		c, err := api.NewClient(&api.ClientOption{
-			BaseURL:     cfg.BaseURL,
-			AccessToken: cfg.AccessToken,
+			BaseURL:           cfg.BaseURL,
+			AccessToken:       token,
+			TagNormalizer:     normalizer,
+			LinkCanonicalizer: canonicalizer,
+			DuplicateGuard:    guard,
 		})
 		if err != nil {
 			die("failed to create api client: %v", err)
 		}
 		b.api = c
 	case authMethodAccount:
+		password, err := cfg.password()
+		if err != nil {
+			die("failed to resolve password: %v", err)
+		}
 		c, err := tissue.NewClient(&tissue.ClientOption{
-			BaseURL:  cfg.BaseURL,
-			Email:    cfg.Email,
-			Password: cfg.Password,
+			BaseURL:           cfg.BaseURL,
+			Email:             cfg.Email,
+			Password:          password,
+			TagNormalizer:     normalizer,
+			LinkCanonicalizer: canonicalizer,
+			DuplicateGuard:    guard,
 		})
 		if err != nil {
 			die("failed to create client: %v", err)
 		}
 		b.scraping = c
+	case authMethodHybrid:
+		token, err := cfg.accessToken()
+		if err != nil {
+			die("failed to resolve access token: %v", err)
+		}
+		password, err := cfg.password()
+		if err != nil {
+			die("failed to resolve password: %v", err)
+		}
+		c, err := api.NewHybridClient(&api.HybridClientOption{
+			BaseURL:           cfg.BaseURL,
+			AccessToken:       token,
+			Email:             cfg.Email,
+			Password:          password,
+			TagNormalizer:     normalizer,
+			LinkCanonicalizer: canonicalizer,
+			DuplicateGuard:    guard,
+		})
+		if err != nil {
+			die("failed to create hybrid client: %v", err)
+		}
+		b.hybrid = c
+		b.api = c
+		b.scraping = c.Scraping()
 	default:
 		die("unknown auth_method: %q (run `tissue configure`)", cfg.AuthMethod)
 	}
@@ -44,19 +133,60 @@ func buildClient() *clientBundle {
 
 func (b *clientBundle) meName(ctx context.Context) string {
 	switch b.config.AuthMethod {
-	case authMethodToken:
+	case authMethodToken, authMethodHybrid:
 		me, err := b.api.Me(ctx)
 		if err != nil {
-			die("%v", err)
+			b.failWith(tissue.OpMe, err)
 		}
 		return me.Name
 	case authMethodAccount:
 		me, err := b.scraping.Me(ctx)
 		if err != nil {
-			die("%v", err)
+			b.failWith(tissue.OpMe, err)
 		}
 		return me.Name
 	}
 	die("cannot resolve username for method %s", b.config.AuthMethod)
 	return ""
 }
+
+// mustParseDuplicateWindow は duplicate_window を読む。設定されていなければ 0。
+func mustParseDuplicateWindow(cfg *Config) time.Duration {
+	if cfg.DuplicateWindow == "" {
+		return 0
+	}
+	window, err := time.ParseDuration(cfg.DuplicateWindow)
+	if err != nil {
+		die("invalid duplicate_window: %v", err)
+	}
+	return window
+}
+
+// detector は重複の判定に使う Detector を返す。リンクは link_rules (未設定なら組み込みのルール) で揃えて比べる。
+func (b *clientBundle) detector(window time.Duration) *dedupe.Detector {
+	d := &dedupe.Detector{Window: window, Canonicalizer: linkcanon.Default()}
+	if b.linkRules != nil {
+		d.Canonicalizer = b.linkRules
+	}
+	return d
+}
+
+// mustLoadLinkRules は path のルールを読む。path が default なら組み込みのルールを返す。
+func mustLoadLinkRules(path string) *linkcanon.Canonicalizer {
+	if path == linkRulesDefault {
+		return linkcanon.Default()
+	}
+	rules, err := linkcanon.LoadFile(path)
+	if err != nil {
+		die("failed to load link rules: %v", err)
+	}
+	return rules
+}
+
+func mustLoadTagRules(path string) *tagrule.Rules {
+	rules, err := tagrule.LoadFile(path)
+	if err != nil {
+		die("failed to load tag rules: %v", err)
+	}
+	return rules
+}
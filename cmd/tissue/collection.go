@@ -2,7 +2,6 @@ package main
 
 import (
 	"context"
-	"flag"
 	"fmt"
 	"os"
 	"strconv"
@@ -38,46 +37,54 @@ func cmdCollection(args []string) {
 
 func usageCollection() {
 	fmt.Fprintln(os.Stderr, "usage: tissue collection <subcommand>")
-	fmt.Fprintln(os.Stderr, "  list    コレクション一覧")
-	fmt.Fprintln(os.Stderr, "  create  コレクション作成")
-	fmt.Fprintln(os.Stderr, "  update  コレクション更新")
-	fmt.Fprintln(os.Stderr, "  delete  コレクション削除")
-	fmt.Fprintln(os.Stderr, "  item    コレクションアイテム操作 (list/add/update/delete)")
+	printCommand("  list    コレクション一覧", tissue.OpCollectionList)
+	printCommand("  create  コレクション作成", tissue.OpCollectionCreate)
+	printCommand("  update  コレクション更新", tissue.OpCollectionUpdate)
+	printCommand("  delete  コレクション削除", tissue.OpCollectionDelete)
+	printCommand("  item    コレクションアイテム操作 (list/add/update/delete)", tissue.OpCollectionItemList)
+	printHiddenNote()
 }
 
 func cmdCollectionList(args []string) {
-	fs := flag.NewFlagSet("collection list", flag.ExitOnError)
+	fs := newFlagSet("collection list")
 	page := fs.Int("page", 1, "ページ")
 	perPage := fs.Int("per-page", 20, "1ページ当たり件数")
 	_ = fs.Parse(args)
 
 	cli := buildClient()
+	cli.require(tissue.OpCollectionList)
 	ctx := context.Background()
 
 	switch cli.config.AuthMethod {
+	case authMethodHybrid:
+		result, err := cli.hybrid.ListCollections(ctx, &api.PageOption{Page: *page, PerPage: *perPage})
+		if err != nil {
+			cli.fail(err)
+		}
+		printResult(result)
 	case authMethodToken:
 		name := cli.meName(ctx)
 		result, err := cli.api.UserCollections(ctx, name, &api.PageOption{Page: *page, PerPage: *perPage})
 		if err != nil {
-			die("%v", err)
+			cli.fail(err)
 		}
-		printJSON(result)
+		printResult(result)
 	case authMethodAccount:
 		result, err := cli.scraping.ListCollections(ctx, &tissue.ListCollectionsOption{
 			Page:    *page,
 			PerPage: *perPage,
 		})
 		if err != nil {
-			die("%v", err)
+			cli.fail(err)
 		}
-		printJSON(result)
+		printResult(result)
 	default:
 		die("list is not available for method %s", cli.config.AuthMethod)
 	}
 }
 
 func cmdCollectionCreate(args []string) {
-	fs := flag.NewFlagSet("collection create", flag.ExitOnError)
+	fs := newFlagSet("collection create")
 	title := fs.String("title", "", "タイトル")
 	private := fs.Bool("private", false, "非公開フラグ")
 	_ = fs.Parse(args)
@@ -85,33 +92,34 @@ func cmdCollectionCreate(args []string) {
 		die("--title is required")
 	}
 	cli := buildClient()
+	cli.require(tissue.OpCollectionCreate)
 	ctx := context.Background()
 	switch cli.config.AuthMethod {
-	case authMethodToken:
+	case authMethodToken, authMethodHybrid:
 		result, err := cli.api.CreateCollection(ctx, &api.CreateCollectionOption{
 			Title:     *title,
 			IsPrivate: *private,
 		})
 		if err != nil {
-			die("%v", err)
+			cli.fail(err)
 		}
-		printJSON(result)
+		printResult(result)
 	case authMethodAccount:
 		result, err := cli.scraping.CreateCollection(ctx, &tissue.CreateCollectionOption{
 			Title:     *title,
 			IsPrivate: *private,
 		})
 		if err != nil {
-			die("%v", err)
+			cli.fail(err)
 		}
-		printJSON(result)
+		printResult(result)
 	default:
 		die("create is not available for method %s", cli.config.AuthMethod)
 	}
 }
 
 func cmdCollectionUpdate(args []string) {
-	fs := flag.NewFlagSet("collection update", flag.ExitOnError)
+	fs := newFlagSet("collection update")
 	setUsage(fs, "tissue collection update <id> [options]")
 	title := fs.String("title", "", "タイトル")
 	private := fs.Bool("private", false, "非公開フラグ")
@@ -127,17 +135,18 @@ func cmdCollectionUpdate(args []string) {
 		die("--title is required")
 	}
 	cli := buildClient()
+	cli.require(tissue.OpCollectionUpdate)
 	ctx := context.Background()
 	switch cli.config.AuthMethod {
-	case authMethodToken:
+	case authMethodToken, authMethodHybrid:
 		result, err := cli.api.UpdateCollection(ctx, id, &api.UpdateCollectionOption{
 			Title:     *title,
 			IsPrivate: *private,
 		})
 		if err != nil {
-			die("%v", err)
+			cli.fail(err)
 		}
-		printJSON(result)
+		printResult(result)
 	case authMethodAccount:
 		result, err := cli.scraping.UpdateCollection(ctx, &tissue.UpdateCollectionOption{
 			ID:        id,
@@ -145,16 +154,16 @@ func cmdCollectionUpdate(args []string) {
 			IsPrivate: *private,
 		})
 		if err != nil {
-			die("%v", err)
+			cli.fail(err)
 		}
-		printJSON(result)
+		printResult(result)
 	default:
 		die("update is not available for method %s", cli.config.AuthMethod)
 	}
 }
 
 func cmdCollectionDelete(args []string) {
-	fs := flag.NewFlagSet("collection delete", flag.ExitOnError)
+	fs := newFlagSet("collection delete")
 	setUsage(fs, "tissue collection delete <id>")
 	pos := parseMixed(fs, args)
 	if len(pos) < 1 {
@@ -165,15 +174,16 @@ func cmdCollectionDelete(args []string) {
 		die("invalid id: %v", err)
 	}
 	cli := buildClient()
+	cli.require(tissue.OpCollectionDelete)
 	ctx := context.Background()
 	switch cli.config.AuthMethod {
-	case authMethodToken:
+	case authMethodToken, authMethodHybrid:
 		if err := cli.api.DeleteCollection(ctx, id); err != nil {
-			die("%v", err)
+			cli.fail(err)
 		}
 	case authMethodAccount:
 		if err := cli.scraping.DeleteCollection(ctx, id); err != nil {
-			die("%v", err)
+			cli.fail(err)
 		}
 	default:
 		die("delete is not available for method %s", cli.config.AuthMethod)
@@ -205,14 +215,15 @@ func cmdCollectionItem(args []string) {
 
 func usageCollectionItem() {
 	fmt.Fprintln(os.Stderr, "usage: tissue collection item <subcommand>")
-	fmt.Fprintln(os.Stderr, "  list    コレクション内アイテム一覧")
-	fmt.Fprintln(os.Stderr, "  add     アイテムを追加")
-	fmt.Fprintln(os.Stderr, "  update  アイテムを更新")
-	fmt.Fprintln(os.Stderr, "  delete  アイテムを削除")
+	printCommand("  list    コレクション内アイテム一覧", tissue.OpCollectionItemList)
+	printCommand("  add     アイテムを追加", tissue.OpCollectionItemCreate)
+	printCommand("  update  アイテムを更新", tissue.OpCollectionItemUpdate)
+	printCommand("  delete  アイテムを削除", tissue.OpCollectionItemDelete)
+	printHiddenNote()
 }
 
 func cmdCollectionItemList(args []string) {
-	fs := flag.NewFlagSet("collection item list", flag.ExitOnError)
+	fs := newFlagSet("collection item list")
 	setUsage(fs, "tissue collection item list <collection-id> [options]")
 	page := fs.Int("page", 1, "ページ")
 	perPage := fs.Int("per-page", 20, "1ページ当たり件数")
@@ -226,14 +237,15 @@ func cmdCollectionItemList(args []string) {
 	}
 
 	cli := buildClient()
+	cli.require(tissue.OpCollectionItemList)
 	ctx := context.Background()
 	switch cli.config.AuthMethod {
-	case authMethodToken:
+	case authMethodToken, authMethodHybrid:
 		result, err := cli.api.ListCollectionItems(ctx, cid, &api.PageOption{Page: *page, PerPage: *perPage})
 		if err != nil {
-			die("%v", err)
+			cli.fail(err)
 		}
-		printJSON(result)
+		printResult(result)
 	case authMethodAccount:
 		result, err := cli.scraping.ListCollectionItems(ctx, &tissue.ListCollectionItemsOption{
 			CollectionID: cid,
@@ -241,16 +253,16 @@ func cmdCollectionItemList(args []string) {
 			PerPage:      *perPage,
 		})
 		if err != nil {
-			die("%v", err)
+			cli.fail(err)
 		}
-		printJSON(result)
+		printResult(result)
 	default:
 		die("item list is not available for method %s", cli.config.AuthMethod)
 	}
 }
 
 func cmdCollectionItemAdd(args []string) {
-	fs := flag.NewFlagSet("collection item add", flag.ExitOnError)
+	fs := newFlagSet("collection item add")
 	setUsage(fs, "tissue collection item add <collection-id> --link <url> [options]")
 	link := fs.String("link", "", "オカズリンク (必須)")
 	note := fs.String("note", "", "ノート")
@@ -276,18 +288,19 @@ func cmdCollectionItemAdd(args []string) {
 	}
 
 	cli := buildClient()
+	cli.require(tissue.OpCollectionItemCreate)
 	ctx := context.Background()
 	switch cli.config.AuthMethod {
-	case authMethodToken:
+	case authMethodToken, authMethodHybrid:
 		result, err := cli.api.CreateCollectionItem(ctx, cid, &api.CreateCollectionItemOption{
 			Link: *link,
 			Note: *note,
 			Tags: tags,
 		})
 		if err != nil {
-			die("%v", err)
+			cli.fail(err)
 		}
-		printJSON(result)
+		printResult(result)
 	case authMethodAccount:
 		result, err := cli.scraping.CreateCollectionItem(ctx, &tissue.CreateCollectionItemOption{
 			CollectionID: cid,
@@ -296,16 +309,16 @@ func cmdCollectionItemAdd(args []string) {
 			Tags:         tags,
 		})
 		if err != nil {
-			die("%v", err)
+			cli.fail(err)
 		}
-		printJSON(result)
+		printResult(result)
 	default:
 		die("item add is not available for method %s", cli.config.AuthMethod)
 	}
 }
 
 func cmdCollectionItemUpdate(args []string) {
-	fs := flag.NewFlagSet("collection item update", flag.ExitOnError)
+	fs := newFlagSet("collection item update")
 	setUsage(fs, "tissue collection item update <collection-id> <item-id> [options]")
 	note := fs.String("note", "", "ノート")
 	noteSet := fs.Bool("set-note", false, "--note の値でノートを上書き (空値許可)")
@@ -325,6 +338,7 @@ func cmdCollectionItemUpdate(args []string) {
 	}
 
 	cli := buildClient()
+	cli.require(tissue.OpCollectionItemUpdate)
 	ctx := context.Background()
 
 	var notePtr *string
@@ -345,15 +359,15 @@ func cmdCollectionItemUpdate(args []string) {
 	}
 
 	switch cli.config.AuthMethod {
-	case authMethodToken:
+	case authMethodToken, authMethodHybrid:
 		result, err := cli.api.UpdateCollectionItem(ctx, cid, iid, &api.UpdateCollectionItemOption{
 			Note: notePtr,
 			Tags: tagsPtr,
 		})
 		if err != nil {
-			die("%v", err)
+			cli.fail(err)
 		}
-		printJSON(result)
+		printResult(result)
 	case authMethodAccount:
 		result, err := cli.scraping.UpdateCollectionItem(ctx, &tissue.UpdateCollectionItemOption{
 			CollectionID: cid,
@@ -362,16 +376,16 @@ func cmdCollectionItemUpdate(args []string) {
 			Tags:         tagsPtr,
 		})
 		if err != nil {
-			die("%v", err)
+			cli.fail(err)
 		}
-		printJSON(result)
+		printResult(result)
 	default:
 		die("item update is not available for method %s", cli.config.AuthMethod)
 	}
 }
 
 func cmdCollectionItemDelete(args []string) {
-	fs := flag.NewFlagSet("collection item delete", flag.ExitOnError)
+	fs := newFlagSet("collection item delete")
 	setUsage(fs, "tissue collection item delete <collection-id> <item-id>")
 	pos := parseMixed(fs, args)
 	if len(pos) < 2 {
@@ -386,15 +400,16 @@ func cmdCollectionItemDelete(args []string) {
 		die("invalid item id: %v", err)
 	}
 	cli := buildClient()
+	cli.require(tissue.OpCollectionItemDelete)
 	ctx := context.Background()
 	switch cli.config.AuthMethod {
-	case authMethodToken:
+	case authMethodToken, authMethodHybrid:
 		if err := cli.api.DeleteCollectionItem(ctx, cid, iid); err != nil {
-			die("%v", err)
+			cli.fail(err)
 		}
 	case authMethodAccount:
 		if err := cli.scraping.DeleteCollectionItem(ctx, cid, iid); err != nil {
-			die("%v", err)
+			cli.fail(err)
 		}
 	default:
 		die("item delete is not available for method %s", cli.config.AuthMethod)
@@ -2,21 +2,57 @@ package main
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 )
 
 const (
 	authMethodToken   = "token"
 	authMethodAccount = "account"
+	// authMethodHybrid はトークンとアカウントの両方を使い、操作ごとに使えるほうを選ぶ。
+	authMethodHybrid = "hybrid"
 )
 
+const defaultProfileName = "default"
+
 type Config struct {
+	Name        string `json:"-"`
 	BaseURL     string `json:"base_url,omitempty"`
 	AuthMethod  string `json:"auth_method"`
 	AccessToken string `json:"access_token,omitempty"`
 	Email       string `json:"email,omitempty"`
 	Password    string `json:"password,omitempty"`
+
+	// SecretBackend は秘密情報の保存先: plain (既定, 設定ファイルに平文) / keyring / file / command。
+	SecretBackend  string `json:"secret_backend,omitempty"`
+	AccessTokenRef string `json:"access_token_ref,omitempty"`
+	PasswordRef    string `json:"password_ref,omitempty"`
+	AccessTokenCmd string `json:"access_token_cmd,omitempty"`
+	PasswordCmd    string `json:"password_cmd,omitempty"`
+	AccessTokenEnv string `json:"access_token_env,omitempty"`
+	PasswordEnv    string `json:"password_env,omitempty"`
+
+	// TagRules はタグの正規化ルール (tagrule の JSON) のパス。書き込み時と tags lint で使う。
+	TagRules string `json:"tag_rules,omitempty"`
+	// LinkRules はリンクの正規化ルール (linkcanon の JSON) のパス。default なら組み込みのルールだけを使う。書き込み時と links normalize で使う。
+	LinkRules string `json:"link_rules,omitempty"`
+	// DuplicateWindow を設定すると、チェックインとコレクションアイテムの作成前に重複を調べる。
+	// この間隔 (time.ParseDuration の形式) 以内に同じリンク・タグのチェックインがあれば作成しない。0 なら調べない。
+	DuplicateWindow string `json:"duplicate_window,omitempty"`
+	// PrivacyRules は privacy audit / apply の既定の条件 (privacy の JSON) のパス。
+	PrivacyRules string `json:"privacy_rules,omitempty"`
+	// Timezone は --at / --since / --until の日付の境界と、タイムゾーンの無い日時に使うタイムゾーン (Asia/Tokyo など)。
+	// 未設定なら環境のローカルタイム。
+	Timezone string `json:"timezone,omitempty"`
+}
+
+// ConfigFile は設定ファイル全体。名前付きプロファイルと既定プロファイル名を保持する。
+type ConfigFile struct {
+	DefaultProfile string             `json:"default_profile"`
+	Profiles       map[string]*Config `json:"profiles"`
 }
 
 func configPath() (string, error) {
@@ -27,7 +63,9 @@ func configPath() (string, error) {
 	return filepath.Join(dir, "tissue", "config.json"), nil
 }
 
-func loadConfig() (*Config, error) {
+// loadConfigFile は設定ファイルを読み込む。プロファイル導入前の単一設定形式であれば
+// "default" プロファイルに移行して書き戻す。
+func loadConfigFile() (*ConfigFile, error) {
 	p, err := configPath()
 	if err != nil {
 		return nil, err
@@ -36,14 +74,31 @@ func loadConfig() (*Config, error) {
 	if err != nil {
 		return nil, err
 	}
-	cfg := &Config{}
-	if err := json.Unmarshal(b, cfg); err != nil {
+	file := &ConfigFile{}
+	if err := json.Unmarshal(b, file); err != nil {
 		return nil, err
 	}
-	return cfg, nil
+	if file.Profiles == nil {
+		legacy := &Config{}
+		if err := json.Unmarshal(b, legacy); err != nil {
+			return nil, err
+		}
+		file.Profiles = map[string]*Config{}
+		if legacy.AuthMethod != "" {
+			file.DefaultProfile = defaultProfileName
+			file.Profiles[defaultProfileName] = legacy
+			if err := saveConfigFile(file); err != nil {
+				return nil, fmt.Errorf("migrate config: %w", err)
+			}
+		}
+	}
+	for name, cfg := range file.Profiles {
+		cfg.Name = name
+	}
+	return file, nil
 }
 
-func saveConfig(cfg *Config) error {
+func saveConfigFile(file *ConfigFile) error {
 	p, err := configPath()
 	if err != nil {
 		return err
@@ -51,17 +106,64 @@ func saveConfig(cfg *Config) error {
 	if err := os.MkdirAll(filepath.Dir(p), 0700); err != nil {
 		return err
 	}
-	b, err := json.MarshalIndent(cfg, "", "  ")
+	b, err := json.MarshalIndent(file, "", "  ")
 	if err != nil {
 		return err
 	}
 	return os.WriteFile(p, b, 0600)
 }
 
-func mustLoadConfig() *Config {
-	cfg, err := loadConfig()
+// selectedProfile は --profile、TISSUE_PROFILE、設定ファイルの既定プロファイルの順で使用するプロファイル名を決める。
+func selectedProfile(file *ConfigFile) string {
+	if globals.profile != "" {
+		return globals.profile
+	}
+	if env := os.Getenv("TISSUE_PROFILE"); env != "" {
+		return env
+	}
+	if file != nil && file.DefaultProfile != "" {
+		return file.DefaultProfile
+	}
+	return defaultProfileName
+}
+
+func (f *ConfigFile) profileNames() []string {
+	names := make([]string, 0, len(f.Profiles))
+	for name := range f.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func loadConfig() (*Config, error) {
+	file, err := loadConfigFile()
 	if err != nil {
-		die("config not loaded (run `tissue configure` first): %v", err)
+		return nil, err
+	}
+	name := selectedProfile(file)
+	cfg, ok := file.Profiles[name]
+	if !ok {
+		return nil, fmt.Errorf("profile %q not found", name)
+	}
+	return cfg, nil
+}
+
+// saveConfig は cfg を cfg.Name のプロファイルとして保存する。最初に作られたプロファイルは既定になる。
+func saveConfig(cfg *Config) error {
+	file, err := loadConfigFile()
+	if errors.Is(err, os.ErrNotExist) {
+		file, err = &ConfigFile{Profiles: map[string]*Config{}}, nil
+	}
+	if err != nil {
+		return err
+	}
+	if cfg.Name == "" {
+		cfg.Name = selectedProfile(file)
+	}
+	file.Profiles[cfg.Name] = cfg
+	if file.DefaultProfile == "" || file.Profiles[file.DefaultProfile] == nil {
+		file.DefaultProfile = cfg.Name
 	}
-	return cfg
+	return saveConfigFile(file)
 }
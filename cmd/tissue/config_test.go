@@ -0,0 +1,133 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func setupConfigDir(t *testing.T, content string) {
+	t.Helper()
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	t.Setenv("TISSUE_PROFILE", "")
+	for _, env := range []string{"TISSUE_BASE_URL", "TISSUE_AUTH_METHOD", "TISSUE_ACCESS_TOKEN", "TISSUE_EMAIL", "TISSUE_PASSWORD", "TISSUE_TAG_RULES"} {
+		t.Setenv(env, "")
+	}
+	saved := globals
+	t.Cleanup(func() { globals = saved })
+	globals = globalOptions{}
+	if content == "" {
+		return
+	}
+	p := filepath.Join(dir, "tissue", "config.json")
+	if err := os.MkdirAll(filepath.Dir(p), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(p, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadConfigFile_MigratesLegacy(t *testing.T) {
+	setupConfigDir(t, `{"base_url":"https://example.com","auth_method":"token","access_token":"tok"}`)
+
+	file, err := loadConfigFile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if file.DefaultProfile != defaultProfileName {
+		t.Errorf("unexpected default profile: %q", file.DefaultProfile)
+	}
+	cfg := file.Profiles[defaultProfileName]
+	if cfg == nil || cfg.AccessToken != "tok" || cfg.BaseURL != "https://example.com" {
+		t.Fatalf("unexpected profile: %+v", cfg)
+	}
+
+	reloaded, err := loadConfigFile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reloaded.Profiles[defaultProfileName].AccessToken != "tok" {
+		t.Error("migrated config was not written back")
+	}
+}
+
+func TestResolveConfig_Layers(t *testing.T) {
+	setupConfigDir(t, `{"default_profile":"main","profiles":{
+		"main":{"base_url":"https://main.example.com","auth_method":"token","access_token":"file-token"},
+		"bot":{"auth_method":"account","email":"bot@example.com","password":"pw"}}}`)
+
+	cfg, sources, err := resolveConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.AccessToken != "file-token" || sources["access_token"] != "profile main" {
+		t.Errorf("profile value not used: %q from %q", cfg.AccessToken, sources["access_token"])
+	}
+
+	t.Setenv("TISSUE_ACCESS_TOKEN", "env-token")
+	t.Setenv("TISSUE_BASE_URL", "https://env.example.com")
+	globals.baseURL = "https://flag.example.com"
+	cfg, sources, err = resolveConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.AccessToken != "env-token" || sources["access_token"] != "env TISSUE_ACCESS_TOKEN" {
+		t.Errorf("env did not override profile: %q from %q", cfg.AccessToken, sources["access_token"])
+	}
+	if cfg.BaseURL != "https://flag.example.com" || sources["base_url"] != "flag --base-url" {
+		t.Errorf("flag did not override env: %q from %q", cfg.BaseURL, sources["base_url"])
+	}
+
+	globals.profile = "bot"
+	cfg, _, err = resolveConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Name != "bot" || cfg.Email != "bot@example.com" {
+		t.Errorf("--profile not applied: %+v", cfg)
+	}
+
+	globals.profile = "missing"
+	if _, _, err := resolveConfig(); err == nil {
+		t.Error("expected error for missing profile")
+	}
+}
+
+func TestResolveConfig_WithoutFile(t *testing.T) {
+	setupConfigDir(t, "")
+	if _, _, err := resolveConfig(); err == nil {
+		t.Error("expected error without config")
+	}
+	t.Setenv("TISSUE_ACCESS_TOKEN", "env-token")
+	cfg, sources, err := resolveConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.AuthMethod != authMethodToken || sources["auth_method"] != sourceInfer {
+		t.Errorf("auth method not inferred: %q from %q", cfg.AuthMethod, sources["auth_method"])
+	}
+
+	t.Setenv("TISSUE_TAG_RULES", "/path/to/tag-rules.json")
+	cfg, sources, err = resolveConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.TagRules != "/path/to/tag-rules.json" || sources["tag_rules"] != "env TISSUE_TAG_RULES" {
+		t.Errorf("tag rules not resolved: %q from %q", cfg.TagRules, sources["tag_rules"])
+	}
+}
+
+func TestResolveConfig_InfersHybrid(t *testing.T) {
+	setupConfigDir(t, "")
+	t.Setenv("TISSUE_ACCESS_TOKEN", "env-token")
+	t.Setenv("TISSUE_EMAIL", "alice@example.com")
+	cfg, _, err := resolveConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.AuthMethod != authMethodHybrid {
+		t.Errorf("expected hybrid, got %q", cfg.AuthMethod)
+	}
+}
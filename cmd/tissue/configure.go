@@ -2,33 +2,50 @@ package main
 
 import (
 	"bufio"
+	"context"
 	"flag"
 	"fmt"
 	"os"
 	"strings"
+	"time"
+
+	tissue "github.com/mohemohe/go-tissue"
 )
 
 func cmdConfigure(args []string) {
 	fs := flag.NewFlagSet("configure", flag.ExitOnError)
-	method := fs.String("method", "", "認証方式: token / account")
+	method := fs.String("method", "", "認証方式: token / account / hybrid")
 	baseURL := fs.String("base-url", "", "Tissue base URL (例: https://shikorism.net)")
-	accessToken := fs.String("access-token", "", "個人用アクセストークン (method=token)")
-	email := fs.String("email", "", "Email (method=account)")
-	password := fs.String("password", "", "Password (method=account)")
+	accessToken := fs.String("access-token", "", "個人用アクセストークン (method=token / hybrid)")
+	email := fs.String("email", "", "Email (method=account / hybrid)")
+	password := fs.String("password", "", "Password (method=account / hybrid)")
+	backend := fs.String("secret-backend", "", "秘密情報の保存先: plain / keyring / file / env / command")
+	secretSource := fs.String("secret-source", "", "secret-backend=env なら環境変数名、command なら秘密情報を出力するコマンド (例: \"pass show tissue\")")
+	passwordSource := fs.String("password-source", "", "method=hybrid のとき Password の --secret-source (--secret-source はアクセストークンに使う)")
+	issueToken := fs.Bool("issue-token", false, "method=account のとき、ログインして個人用アクセストークンを発行し token 方式のプロファイルとして保存")
+	tokenName := fs.String("token-name", "tissue-cli", "--issue-token で発行するトークンの名前")
+	tokenProfile := fs.String("token-profile", "", "--issue-token で保存するプロファイル名 (既定: <プロファイル名>-token)")
 	noPrompt := fs.Bool("no-prompt", false, "対話プロンプトを抑制し、指定されたフラグのみで保存")
+	globals.register(fs)
 	_ = fs.Parse(args)
+	// コマンド名より前に指定されたグローバルオプションも受け付ける
+	*method = defaultOr(*method, globals.authMethod)
+	*baseURL = defaultOr(*baseURL, globals.baseURL)
+	*accessToken = defaultOr(*accessToken, globals.accessToken)
+	*email = defaultOr(*email, globals.email)
+	*password = defaultOr(*password, globals.password)
 
 	cfg, _ := loadConfig()
 	if cfg == nil {
-		cfg = &Config{}
+		cfg = &Config{Name: selectedProfile(nil)}
 	}
 
-	reader := bufio.NewReader(os.Stdin)
+	reader := stdinReader
 
 	if *method != "" {
 		cfg.AuthMethod = *method
 	} else if !*noPrompt {
-		cfg.AuthMethod = promptWithDefault(reader, "認証方式 (token/account)", defaultOr(cfg.AuthMethod, "token"))
+		cfg.AuthMethod = promptWithDefault(reader, "認証方式 (token/account/hybrid)", defaultOr(cfg.AuthMethod, "token"))
 	} else if cfg.AuthMethod == "" {
 		cfg.AuthMethod = "token"
 	}
@@ -39,38 +56,178 @@ func cmdConfigure(args []string) {
 		cfg.BaseURL = promptWithDefault(reader, "Base URL", defaultOr(cfg.BaseURL, "https://shikorism.net"))
 	}
 
+	prev := *cfg
+	prevBackend := prev.SecretBackend
+	if *backend != "" {
+		cfg.SecretBackend = *backend
+	} else if !*noPrompt {
+		cfg.SecretBackend = promptWithDefault(reader, "秘密情報の保存先 (plain/keyring/file/env/command)", defaultOr(cfg.SecretBackend, secretBackendPlain))
+	}
+	if cfg.SecretBackend == secretBackendPlain {
+		cfg.SecretBackend = ""
+	}
+	switch cfg.SecretBackend {
+	case "", secretBackendKeyring, secretBackendFile, secretBackendEnv, secretBackendCommand:
+	default:
+		die("unknown secret backend: %q (want plain/keyring/file/env/command)", cfg.SecretBackend)
+	}
+
+	if *issueToken {
+		if cfg.AuthMethod != authMethodAccount {
+			die("--issue-token requires --method account")
+		}
+		if cfg.SecretBackend == secretBackendEnv || cfg.SecretBackend == secretBackendCommand {
+			die("--issue-token cannot store the issued token with secret backend %s (use plain, keyring or file)", cfg.SecretBackend)
+		}
+	}
+
 	switch cfg.AuthMethod {
 	case authMethodToken:
-		if *accessToken != "" {
-			cfg.AccessToken = *accessToken
-		} else if !*noPrompt {
-			cfg.AccessToken = promptWithDefault(reader, "個人用アクセストークン", cfg.AccessToken)
-		}
+		configureSecret(reader, cfg, secretAccessToken, "個人用アクセストークン", *accessToken, *secretSource, *noPrompt, prevBackend)
 		cfg.Email = ""
-		cfg.Password = ""
+		clearSecret(cfg, secretPassword)
 	case authMethodAccount:
 		if *email != "" {
 			cfg.Email = *email
 		} else if !*noPrompt {
 			cfg.Email = promptWithDefault(reader, "Email", cfg.Email)
 		}
-		if *password != "" {
-			cfg.Password = *password
+		configureSecret(reader, cfg, secretPassword, "Password", *password, *secretSource, *noPrompt, prevBackend)
+		clearSecret(cfg, secretAccessToken)
+	case authMethodHybrid:
+		configureSecret(reader, cfg, secretAccessToken, "個人用アクセストークン", *accessToken, *secretSource, *noPrompt, prevBackend)
+		if *email != "" {
+			cfg.Email = *email
 		} else if !*noPrompt {
-			cfg.Password = promptWithDefault(reader, "Password (平文保存されます)", cfg.Password)
+			cfg.Email = promptWithDefault(reader, "Email", cfg.Email)
 		}
-		cfg.AccessToken = ""
+		configureSecret(reader, cfg, secretPassword, "Password", *password, *passwordSource, *noPrompt, prevBackend)
 	default:
-		die("unknown auth method: %q (want token/account)", cfg.AuthMethod)
+		die("unknown auth method: %q (want token/account/hybrid)", cfg.AuthMethod)
 	}
 
 	if err := saveConfig(cfg); err != nil {
 		die("failed to save config: %v", err)
 	}
+	if err := cleanupSecrets(&prev, cfg); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: failed to delete old secrets: %v\n", err)
+	}
 	p, _ := configPath()
-	fmt.Fprintf(os.Stderr, "saved: %s\n", p)
+	fmt.Fprintf(os.Stderr, "saved: %s (profile: %s)\n", p, cfg.Name)
+
+	if *issueToken {
+		issueTokenProfile(cfg, *tokenName, defaultOr(*tokenProfile, cfg.Name+"-token"))
+	}
+}
+
+// issueTokenProfile は account でログインして個人用アクセストークンを発行し、name の token プロファイルとして保存する。
+// 秘密情報の保存先は account と同じものを使う。
+func issueTokenProfile(account *Config, tokenName, name string) {
+	password, err := account.password()
+	if err != nil {
+		die("failed to resolve password: %v", err)
+	}
+	client, err := tissue.NewClient(&tissue.ClientOption{
+		BaseURL:  account.BaseURL,
+		Email:    account.Email,
+		Password: password,
+	})
+	if err != nil {
+		die("failed to create client: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	token, err := client.CreateAccessToken(ctx, tokenName)
+	if err != nil {
+		die("failed to issue access token: %v", err)
+	}
+
+	prev := &Config{}
+	if file, err := loadConfigFile(); err == nil && file.Profiles[name] != nil {
+		prev = file.Profiles[name]
+	}
+	cfg := &Config{
+		Name:          name,
+		BaseURL:       account.BaseURL,
+		AuthMethod:    authMethodToken,
+		SecretBackend: account.SecretBackend,
+	}
+	if cfg.SecretBackend == "" {
+		cfg.setPlainSecret(secretAccessToken, token.Token)
+	} else if err := storeSecret(cfg, secretAccessToken, token.Token); err != nil {
+		die("failed to store secret: %v (issued token %q remains; revoke it from the settings page)", err, token.Name)
+	}
+	if err := saveConfig(cfg); err != nil {
+		die("failed to save config: %v", err)
+	}
+	if err := cleanupSecrets(prev, cfg); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: failed to delete old secrets: %v\n", err)
+	}
+	fmt.Fprintf(os.Stderr, "issued access token %q and saved profile: %s (use with --profile %s)\n", token.Name, name, name)
 }
 
+// configureSecret は cfg.SecretBackend に応じて秘密情報 (または env / command の参照) を設定する。
+// 値が与えられなければ既存の値を維持し、保存先が変わった場合は既存の値を新しい保存先へ移す。
+func configureSecret(reader *bufio.Reader, cfg *Config, kind, label, value, source string, noPrompt bool, prevBackend string) {
+	switch cfg.SecretBackend {
+	case secretBackendEnv, secretBackendCommand:
+		current := cfg.secretSource(kind)
+		if source == "" && !noPrompt {
+			prompt := label + " を格納した環境変数名"
+			if cfg.SecretBackend == secretBackendCommand {
+				prompt = label + " を出力するコマンド"
+			}
+			source = promptWithDefault(reader, prompt, current)
+		}
+		if source == "" {
+			source = current
+		}
+		if source == "" {
+			die("--secret-source is required for secret backend %s", cfg.SecretBackend)
+		}
+		clearSecret(cfg, kind)
+		cfg.setSecretSource(kind, source)
+		return
+	}
+
+	changed := cfg.SecretBackend != prevBackend
+	movable := prevBackend != secretBackendEnv && prevBackend != secretBackendCommand
+	exists := cfg.hasSecret(kind) && (!changed || movable)
+	if value == "" && !noPrompt {
+		prompt := label
+		if cfg.SecretBackend == "" {
+			prompt += " (平文保存されます)"
+		}
+		value = promptSecret(reader, prompt, exists)
+	}
+	if value == "" {
+		if !changed {
+			return
+		}
+		if !exists {
+			die("%s is required", label)
+		}
+		old := *cfg
+		old.SecretBackend = prevBackend
+		v, err := old.secret(kind)
+		if err != nil {
+			die("failed to read current %s: %v", label, err)
+		}
+		value = v
+	}
+	clearSecret(cfg, kind)
+	if cfg.SecretBackend == "" {
+		cfg.setPlainSecret(kind, value)
+		return
+	}
+	if err := storeSecret(cfg, kind, value); err != nil {
+		die("failed to store secret: %v", err)
+	}
+}
+
+// stdinReader は標準入力を読むすべてのプロンプトで共有する。個別に bufio.Reader を作ると先読み分が失われる。
+var stdinReader = bufio.NewReader(os.Stdin)
+
 func defaultOr(val, def string) string {
 	if val != "" {
 		return val
@@ -94,3 +251,17 @@ func promptWithDefault(reader *bufio.Reader, label, def string) string {
 	}
 	return line
 }
+
+// promptSecret は既存の値を表示せずに入力を求める。空入力は "" (既存の値を維持) を返す。
+func promptSecret(reader *bufio.Reader, label string, exists bool) string {
+	if exists {
+		fmt.Fprintf(os.Stderr, "%s [設定済み, 空 Enter で維持]: ", label)
+	} else {
+		fmt.Fprintf(os.Stderr, "%s: ", label)
+	}
+	line, err := reader.ReadString('\n')
+	if err != nil && line == "" {
+		return ""
+	}
+	return strings.TrimSpace(line)
+}
@@ -0,0 +1,258 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	tissue "github.com/mohemohe/go-tissue"
+	"github.com/mohemohe/go-tissue/bulk"
+	"github.com/mohemohe/go-tissue/dedupe"
+)
+
+func cmdDedupe(args []string) {
+	if len(args) == 0 {
+		usageDedupe()
+		os.Exit(1)
+	}
+	sub, rest := args[0], args[1:]
+	switch sub {
+	case "checkins":
+		cmdDedupeCheckins(rest)
+	case "collections":
+		cmdDedupeCollections(rest)
+	case "-h", "--help", "help":
+		usageDedupe()
+	default:
+		die("unknown dedupe subcommand: %s", sub)
+	}
+}
+
+func usageDedupe() {
+	fmt.Fprintln(os.Stderr, "usage: tissue dedupe <subcommand>")
+	printCommand("  checkins     短い間隔で作られた同じリンク・タグのチェックインを探して削除", tissue.OpUserCheckins, tissue.OpCheckinDelete)
+	printCommand("  collections  同じコレクションにある同じリンクのアイテムを探して削除", tissue.OpCollectionItemList, tissue.OpCollectionItemDelete)
+	printHiddenNote()
+}
+
+// dedupeFlags は checkins / collections に共通のフラグ。
+type dedupeFlags struct {
+	keep        *string
+	dryRun      *bool
+	yes         *bool
+	concurrency *int
+	interval    *time.Duration
+}
+
+func addDedupeFlags(fs *flag.FlagSet) *dedupeFlags {
+	return &dedupeFlags{
+		keep:        fs.String("keep", "", "残すもの: oldest / newest (省略すると組ごとに選ぶ)"),
+		dryRun:      fs.Bool("dry-run", false, "重複の組を表示するだけで削除しない"),
+		yes:         fs.Bool("yes", false, "削除の前に確認しない"),
+		concurrency: fs.Int("concurrency", bulk.DefaultConcurrency, "同時に削除する数"),
+		interval:    fs.Duration("interval", 0, "各削除を開始する間隔"),
+	}
+}
+
+// parseKeep は --keep を読む。省略なら対話的に選ぶので空を返す。
+func (f *dedupeFlags) parseKeep() dedupe.Keep {
+	if *f.keep == "" {
+		return ""
+	}
+	keep, err := dedupe.ParseKeep(*f.keep)
+	if err != nil {
+		die("%v", err)
+	}
+	return keep
+}
+
+// choose は組の各要素を表示し、残すものを選ばせる。--keep があればそれに従う。-1 ならその組を飛ばす。
+// 対話では番号の入力を必須とし、標準入力が終わっていれば中止する。
+func choose(keep dedupe.Keep, title string, lines []string) int {
+	switch keep {
+	case dedupe.KeepOldest:
+		return 0
+	case dedupe.KeepNewest:
+		return len(lines) - 1
+	}
+	fmt.Fprintln(os.Stderr, title)
+	for i, l := range lines {
+		fmt.Fprintf(os.Stderr, "  %d) %s\n", i+1, l)
+	}
+	for {
+		fmt.Fprintf(os.Stderr, "残す番号 (1-%d、s で飛ばす、q で中止): ", len(lines))
+		input, ok := readInput()
+		if !ok {
+			fmt.Fprintln(os.Stderr)
+			die("aborted (no input; use --keep with --yes for non-interactive runs).")
+		}
+		switch input {
+		case "s":
+			return -1
+		case "q":
+			die("aborted.")
+		default:
+			if n, err := strconv.Atoi(input); err == nil && n >= 1 && n <= len(lines) {
+				return n - 1
+			}
+		}
+	}
+}
+
+func cmdDedupeCheckins(args []string) {
+	fs := newFlagSet("dedupe checkins")
+	f := addDedupeFlags(fs)
+	window := fs.Duration("window", 0, "この間隔以内の同じリンク・タグのチェックインを重複とみなす (既定: duplicate_window、未設定なら 1m)")
+	_ = fs.Parse(args)
+	keep := f.parseKeep()
+
+	cli := buildClient()
+	ops := []tissue.Operation{tissue.OpUserCheckins}
+	if !*f.dryRun {
+		ops = append(ops, tissue.OpCheckinDelete)
+	}
+	for _, op := range ops {
+		cli.require(op)
+	}
+	if cli.api == nil {
+		die("dedupe is not available for method %s", cli.config.AuthMethod)
+	}
+	if *window == 0 {
+		*window = mustParseDuplicateWindow(cli.config)
+	}
+	ctx := context.Background()
+	cli.op = tissue.OpUserCheckins
+	groups, err := cli.detector(*window).ScanCheckins(ctx, cli.api, cli.meName(ctx))
+	if err != nil {
+		cli.fail(err)
+	}
+	fmt.Fprintf(os.Stderr, "%d duplicate group(s)\n", len(groups))
+	if *f.dryRun || len(groups) == 0 {
+		printResult(groups)
+		return
+	}
+
+	var remove []tissue.Checkin
+	for i, g := range groups {
+		lines := make([]string, len(g.Checkins))
+		for j, c := range g.Checkins {
+			lines[j] = fmt.Sprintf("%d  %s  %s", c.ID, formatLocalTime(c.CheckedInAt.Time), c.Note)
+		}
+		k := choose(keep, fmt.Sprintf("[%d/%d] %s  %s", i+1, len(groups), g.Link, strings.Join(g.Tags, ",")), lines)
+		for j, c := range g.Checkins {
+			if k >= 0 && j != k {
+				remove = append(remove, c)
+			}
+		}
+	}
+	if len(remove) == 0 {
+		return
+	}
+	if !*f.yes && !confirm(fmt.Sprintf("delete %d checkin(s)?", len(remove))) {
+		die("aborted.")
+	}
+	ids := make([]int64, len(remove))
+	for i, c := range remove {
+		ids[i] = c.ID
+	}
+	reportEach(bulk.Each(ctx, ids, f.eachOption("checkin"), cli.api.DeleteCheckin))
+}
+
+func cmdDedupeCollections(args []string) {
+	fs := newFlagSet("dedupe collections")
+	f := addDedupeFlags(fs)
+	_ = fs.Parse(args)
+	keep := f.parseKeep()
+
+	cli := buildClient()
+	ops := []tissue.Operation{tissue.OpCollectionList, tissue.OpCollectionItemList}
+	if !*f.dryRun {
+		ops = append(ops, tissue.OpCollectionItemDelete)
+	}
+	for _, op := range ops {
+		cli.require(op)
+	}
+	if cli.api == nil {
+		die("dedupe is not available for method %s", cli.config.AuthMethod)
+	}
+	ctx := context.Background()
+	cli.op = tissue.OpCollectionItemList
+	groups, err := cli.detector(0).ScanCollections(ctx, cli.api, cli.meName(ctx))
+	if err != nil {
+		cli.fail(err)
+	}
+	fmt.Fprintf(os.Stderr, "%d duplicate group(s)\n", len(groups))
+	if *f.dryRun || len(groups) == 0 {
+		printResult(groups)
+		return
+	}
+
+	// アイテムの削除にはコレクションの ID も要るので、コレクションごとにまとめる
+	remove := map[int64][]int64{}
+	var collections []int64
+	for i, g := range groups {
+		lines := make([]string, len(g.Items))
+		for j, it := range g.Items {
+			lines[j] = fmt.Sprintf("%d  %s  %s  %s", it.ID, it.Link, strings.Join(it.Tags, ","), it.Note)
+		}
+		k := choose(keep, fmt.Sprintf("[%d/%d] collection %d  %s", i+1, len(groups), g.CollectionID, g.Link), lines)
+		for j, it := range g.Items {
+			if k >= 0 && j != k {
+				if _, ok := remove[g.CollectionID]; !ok {
+					collections = append(collections, g.CollectionID)
+				}
+				remove[g.CollectionID] = append(remove[g.CollectionID], it.ID)
+			}
+		}
+	}
+	if len(collections) == 0 {
+		return
+	}
+	total := 0
+	for _, ids := range remove {
+		total += len(ids)
+	}
+	if !*f.yes && !confirm(fmt.Sprintf("delete %d collection item(s)?", total)) {
+		die("aborted.")
+	}
+	var results []bulk.ItemResult
+	for _, cid := range collections {
+		cid := cid
+		results = append(results, bulk.Each(ctx, remove[cid], f.eachOption(fmt.Sprintf("collection item %d/", cid)), func(ctx context.Context, id int64) error {
+			return cli.api.DeleteCollectionItem(ctx, cid, id)
+		})...)
+	}
+	reportEach(results)
+}
+
+// eachOption は削除の並行数と、1件ごとの結果の表示を設定する。label は ID の前に付ける。
+func (f *dedupeFlags) eachOption(label string) *bulk.EachOption {
+	if !strings.HasSuffix(label, "/") {
+		label += " "
+	}
+	return &bulk.EachOption{
+		Concurrency: *f.concurrency,
+		Interval:    *f.interval,
+		OnResult: func(r bulk.ItemResult) {
+			if r.OK {
+				fmt.Fprintf(os.Stderr, "deleted %s%d\n", label, r.ID)
+			} else {
+				fmt.Fprintf(os.Stderr, "failed %s%d: %s\n", label, r.ID, r.Error)
+			}
+		},
+	}
+}
+
+// reportEach は結果を表示し、失敗があれば終了コード 1 で終わる。
+func reportEach(results []bulk.ItemResult) {
+	printResult(results)
+	for _, r := range results {
+		if !r.OK {
+			os.Exit(1)
+		}
+	}
+}
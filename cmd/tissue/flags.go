@@ -6,6 +6,60 @@ import (
 	"os"
 )
 
+// globalOptions はすべてのサブコマンドで共通に受け付けるオプション。
+type globalOptions struct {
+	output   string
+	template string
+	columns  string
+	profile  string
+
+	// 以下はプロファイルの値を上書きする。
+	baseURL     string
+	authMethod  string
+	accessToken string
+	email       string
+	password    string
+	tagRules    string
+	linkRules   string
+	dupWindow   string
+	timezone    string
+}
+
+var globals globalOptions
+
+// register は fs にグローバルオプションを登録する。既定値は登録時点の値なので、
+// コマンド名の前で指定された値はサブコマンドの FlagSet でも引き継がれる。
+// サブコマンドが同名のフラグを定義済みの場合はそちらを優先する。
+func (g *globalOptions) register(fs *flag.FlagSet) {
+	stringVar(fs, &g.output, "output", "出力形式: json / table / yaml / tsv / template")
+	stringVar(fs, &g.template, "template", "Go テンプレート (例: '{{.ID}} {{.Link}}')")
+	stringVar(fs, &g.columns, "columns", "table / tsv で表示する列 (カンマ区切り)")
+	stringVar(fs, &g.profile, "profile", "使用する設定プロファイル (既定: $TISSUE_PROFILE または default_profile)")
+	stringVar(fs, &g.baseURL, "base-url", "Tissue base URL (TISSUE_BASE_URL, プロファイルより優先)")
+	stringVar(fs, &g.authMethod, "auth-method", "認証方式: token / account / hybrid (TISSUE_AUTH_METHOD, プロファイルより優先)")
+	stringVar(fs, &g.accessToken, "token", "個人用アクセストークン (TISSUE_ACCESS_TOKEN, プロファイルより優先)")
+	stringVar(fs, &g.email, "email", "Email (TISSUE_EMAIL, プロファイルより優先)")
+	stringVar(fs, &g.password, "password", "Password (TISSUE_PASSWORD, プロファイルより優先)")
+	stringVar(fs, &g.tagRules, "tag-rules", "タグの正規化ルールのファイル (TISSUE_TAG_RULES, プロファイルより優先)")
+	stringVar(fs, &g.dupWindow, "duplicate-window", "作成前の重複チェックの間隔 (例: 1m、0 で無効) (TISSUE_DUPLICATE_WINDOW, プロファイルより優先)")
+	stringVar(fs, &g.timezone, "timezone", "--at / --since / --until に使うタイムゾーン (例: Asia/Tokyo) (TISSUE_TIMEZONE, プロファイルより優先)")
+	stringVar(fs, &g.linkRules, "link-rules", "リンクの正規化ルールのファイル、または組み込みのルールだけを使う default (TISSUE_LINK_RULES, プロファイルより優先)")
+}
+
+func stringVar(fs *flag.FlagSet, p *string, name, usage string) {
+	if fs.Lookup(name) != nil {
+		return
+	}
+	fs.StringVar(p, name, *p, usage)
+}
+
+// newFlagSet はグローバルオプションを登録済みのサブコマンド用 FlagSet を返す。
+func newFlagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+	globals.register(fs)
+	return fs
+}
+
 // setUsage は flag.FlagSet の --help 出力に位置引数を含む usage 行を付け足す。
 func setUsage(fs *flag.FlagSet, usage string) {
 	fs.Usage = func() {
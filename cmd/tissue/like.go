@@ -0,0 +1,55 @@
+package main
+
+import (
+	"context"
+	"strconv"
+
+	tissue "github.com/mohemohe/go-tissue"
+)
+
+func cmdLike(args []string) {
+	id := parseLikeArgs("like", args)
+	cli := buildClient()
+	cli.require(tissue.OpLike)
+	result, err := cli.scraping.Like(context.Background(), id)
+	if err != nil {
+		cli.fail(err)
+	}
+	printResult(result)
+}
+
+func cmdUnlike(args []string) {
+	id := parseLikeArgs("unlike", args)
+	cli := buildClient()
+	cli.require(tissue.OpUnlike)
+	result, err := cli.scraping.Unlike(context.Background(), id)
+	if err != nil {
+		cli.fail(err)
+	}
+	printResult(result)
+}
+
+func cmdLikedBy(args []string) {
+	id := parseLikeArgs("liked-by", args)
+	cli := buildClient()
+	cli.require(tissue.OpLikedBy)
+	result, err := cli.scraping.LikedBy(context.Background(), id)
+	if err != nil {
+		cli.fail(err)
+	}
+	printResult(result)
+}
+
+func parseLikeArgs(name string, args []string) int64 {
+	fs := newFlagSet(name)
+	setUsage(fs, "tissue "+name+" <checkin id>")
+	pos := parseMixed(fs, args)
+	if len(pos) < 1 {
+		die("usage: tissue %s <checkin id>", name)
+	}
+	id, err := strconv.ParseInt(pos[0], 10, 64)
+	if err != nil {
+		die("invalid id: %v", err)
+	}
+	return id
+}
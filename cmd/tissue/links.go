@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	tissue "github.com/mohemohe/go-tissue"
+	"github.com/mohemohe/go-tissue/bulk"
+	"github.com/mohemohe/go-tissue/linkcanon"
+)
+
+// linkRulesDefault は link_rules に指定すると組み込みのルールだけを使う値。
+const linkRulesDefault = "default"
+
+func cmdLinks(args []string) {
+	if len(args) == 0 {
+		usageLinks()
+		os.Exit(1)
+	}
+	sub, rest := args[0], args[1:]
+	switch sub {
+	case "normalize":
+		cmdLinksNormalize(rest)
+	case "-h", "--help", "help":
+		usageLinks()
+	default:
+		die("unknown links subcommand: %s", sub)
+	}
+}
+
+func usageLinks() {
+	fmt.Fprintln(os.Stderr, "usage: tissue links <subcommand>")
+	printCommand("  normalize  過去のチェックインのリンクを正規化ルールで書き換える", tissue.OpUserCheckins, tissue.OpCheckinUpdate)
+	printHiddenNote()
+}
+
+func cmdLinksNormalize(args []string) {
+	fs := newFlagSet("links normalize")
+	setUsage(fs, "tissue links normalize [--dry-run]")
+	f := addBulkFlags(fs)
+	_ = fs.Parse(args)
+
+	cli := buildClient()
+	for _, op := range []tissue.Operation{tissue.OpCheckinUpdate, tissue.OpUserCheckins} {
+		cli.require(op)
+	}
+	// ルールが設定されていなければ組み込みのルールを使う
+	canon := cli.linkRules
+	if canon == nil {
+		canon = linkcanon.Default()
+	}
+	ctx := context.Background()
+	cli.op = tissue.OpUserCheckins
+	changes, err := bulk.PlanLinks(ctx, cli.api, cli.meName(ctx), canon, func(ch tissue.Checkin, err error) {
+		fmt.Fprintf(os.Stderr, "skip checkin %d: %v\n", ch.ID, err)
+	})
+	if err != nil {
+		cli.fail(err)
+	}
+	cli.op = tissue.OpCheckinUpdate
+	runBulk(ctx, cli, f, "links normalize", args, changes)
+}
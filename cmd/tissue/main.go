@@ -1,18 +1,24 @@
 package main
 
 import (
-	"encoding/json"
+	"flag"
 	"fmt"
 	"os"
+
+	tissue "github.com/mohemohe/go-tissue"
 )
 
 func main() {
-	if len(os.Args) < 2 {
+	fs := flag.NewFlagSet("tissue", flag.ExitOnError)
+	fs.Usage = usage
+	globals.register(fs)
+	_ = fs.Parse(os.Args[1:])
+	if fs.NArg() < 1 {
 		usage()
 		os.Exit(1)
 	}
-	name := os.Args[1]
-	args := os.Args[2:]
+	name := fs.Arg(0)
+	args := fs.Args()[1:]
 	switch name {
 	case "configure":
 		cmdConfigure(args)
@@ -20,12 +26,40 @@ func main() {
 		cmdMe(args)
 	case "checkin":
 		cmdCheckin(args)
+	case "like":
+		cmdLike(args)
+	case "unlike":
+		cmdUnlike(args)
+	case "liked-by":
+		cmdLikedBy(args)
 	case "collection":
 		cmdCollection(args)
+	case "webhook":
+		cmdWebhook(args)
+	case "site-export":
+		cmdSiteExport(args)
+	case "site-import":
+		cmdSiteImport(args)
+	case "mute":
+		cmdMute(args)
 	case "search":
 		cmdSearch(args)
 	case "tags":
 		cmdTags(args)
+	case "dedupe":
+		cmdDedupe(args)
+	case "links":
+		cmdLinks(args)
+	case "privacy":
+		cmdPrivacy(args)
+	case "stats":
+		cmdStats(args)
+	case "profile":
+		cmdProfile(args)
+	case "config":
+		cmdConfig(args)
+	case "capabilities":
+		cmdCapabilities(args)
 	case "-h", "--help", "help":
 		usage()
 	default:
@@ -36,26 +70,41 @@ func main() {
 }
 
 func usage() {
-	fmt.Fprintln(os.Stderr, "usage: tissue <command> [options]")
+	fmt.Fprintln(os.Stderr, "usage: tissue [global options] <command> [options]")
 	fmt.Fprintln(os.Stderr, "")
 	fmt.Fprintln(os.Stderr, "commands:")
 	fmt.Fprintln(os.Stderr, "  configure   認証情報の設定")
-	fmt.Fprintln(os.Stderr, "  me          自分のユーザー情報を表示")
-	fmt.Fprintln(os.Stderr, "  checkin     チェックイン操作 (add/list/get/update/delete)")
-	fmt.Fprintln(os.Stderr, "  collection  コレクション操作 (list/create/update/delete/item ...)")
-	fmt.Fprintln(os.Stderr, "  search      チェックインを検索")
-	fmt.Fprintln(os.Stderr, "  tags        最近使用したタグ")
+	fmt.Fprintln(os.Stderr, "  profile     設定プロファイルの管理 (list/use/remove/show)")
+	fmt.Fprintln(os.Stderr, "  config      設定の確認 (show [--resolved])")
+	fmt.Fprintln(os.Stderr, "  capabilities 認証方式・接続先で使える操作を表示 (--probe URL で任意のインスタンスを調査)")
+	printCommand("  me          自分のユーザー情報を表示", tissue.OpMe)
+	printCommand("  checkin     チェックイン操作 (add/list/get/update/delete/bulk-update/bulk-delete)", tissue.OpCheckinCreate, tissue.OpUserCheckins)
+	printCommand("  like        チェックインにいいね (like <id>)", tissue.OpLike)
+	printCommand("  unlike      いいねを取り消す (unlike <id>)", tissue.OpUnlike)
+	printCommand("  liked-by    チェックインにいいねしたユーザー (liked-by <id>)", tissue.OpLikedBy)
+	printCommand("  collection  コレクション操作 (list/create/update/delete/item ...)", tissue.OpCollectionList)
+	printCommand("  webhook     チェックイン用 Webhook の管理 (list/create/delete)", tissue.OpWebhookList)
+	printCommand("  site-export サイトのデータエクスポート (CSV) をダウンロード", tissue.OpCSVExport)
+	printCommand("  site-import サイトの CSV インポートでチェックインを一括登録", tissue.OpCSVImport)
+	printCommand("  mute        タグのミュート設定 (list/add/remove)", tissue.OpTagMuteList)
+	printCommand("  search      チェックインを検索", tissue.OpSearchCheckins)
+	printCommand("  tags        最近使用したタグ (suggest/lint/rename/merge でタグの候補・検査・一括変更)", tissue.OpRecentTags, tissue.OpUserCheckins)
+	printCommand("  dedupe      重複したチェックイン・コレクションアイテムの検出と削除 (checkins/collections)", tissue.OpUserCheckins, tissue.OpCollectionItemList)
+	printCommand("  links       過去のチェックインのリンクを正規化 (normalize)", tissue.OpUserCheckins, tissue.OpCheckinUpdate)
+	printAPICommand("  privacy     公開エントリの監査と一括非公開化 (audit/apply)", tissue.OpUserCheckins, tissue.OpCollectionList)
+	printCommand("  stats       チェックイン統計 (JSON / SVG / PNG)", tissue.OpDailyStats, tissue.OpTagStats, tissue.OpHourlyStats)
+	printHiddenNote()
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "global options (各サブコマンドの後ろにも指定可):")
+	fmt.Fprintln(os.Stderr, "  --output json|table|yaml|tsv|template  出力形式 (既定: json)")
+	fmt.Fprintln(os.Stderr, "  --template '{{.ID}} {{.Link}}'         Go テンプレートで1要素ずつ出力")
+	fmt.Fprintln(os.Stderr, "  --columns id,link,tags                 table / tsv で表示する列")
+	fmt.Fprintln(os.Stderr, "  --profile name                         使用する設定プロファイル (TISSUE_PROFILE でも指定可)")
+	fmt.Fprintln(os.Stderr, "  --base-url / --auth-method / --token / --email / --password / --tag-rules / --link-rules / --duplicate-window / --timezone")
+	fmt.Fprintln(os.Stderr, "                                         プロファイルの値を上書き (TISSUE_BASE_URL 等の環境変数でも可)")
 }
 
 func die(format string, args ...interface{}) {
 	fmt.Fprintf(os.Stderr, format+"\n", args...)
 	os.Exit(1)
 }
-
-func printJSON(v interface{}) {
-	enc := json.NewEncoder(os.Stdout)
-	enc.SetIndent("", "  ")
-	if err := enc.Encode(v); err != nil {
-		die("failed to encode: %v", err)
-	}
-}
@@ -2,28 +2,30 @@ package main
 
 import (
 	"context"
-	"flag"
+
+	tissue "github.com/mohemohe/go-tissue"
 )
 
 func cmdMe(args []string) {
-	fs := flag.NewFlagSet("me", flag.ExitOnError)
+	fs := newFlagSet("me")
 	_ = fs.Parse(args)
 
 	cli := buildClient()
+	cli.require(tissue.OpMe)
 	ctx := context.Background()
 	switch cli.config.AuthMethod {
-	case authMethodToken:
+	case authMethodToken, authMethodHybrid:
 		me, err := cli.api.Me(ctx)
 		if err != nil {
-			die("%v", err)
+			cli.fail(err)
 		}
-		printJSON(me)
+		printResult(me)
 	case authMethodAccount:
 		me, err := cli.scraping.Me(ctx)
 		if err != nil {
-			die("%v", err)
+			cli.fail(err)
 		}
-		printJSON(me)
+		printResult(me)
 	default:
 		die("me is not available for method %s", cli.config.AuthMethod)
 	}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	tissue "github.com/mohemohe/go-tissue"
+)
+
+func cmdMute(args []string) {
+	if len(args) == 0 {
+		usageMute()
+		os.Exit(1)
+	}
+	sub, rest := args[0], args[1:]
+	switch sub {
+	case "list":
+		cmdMuteList(rest)
+	case "add":
+		cmdMuteAdd(rest)
+	case "remove":
+		cmdMuteRemove(rest)
+	case "-h", "--help", "help":
+		usageMute()
+	default:
+		die("unknown mute subcommand: %s", sub)
+	}
+}
+
+func usageMute() {
+	fmt.Fprintln(os.Stderr, "usage: tissue mute <subcommand>")
+	printCommand("  list    ミュートしているタグの一覧", tissue.OpTagMuteList)
+	printCommand("  add     タグをミュート (add <tag>)", tissue.OpTagMuteCreate)
+	printCommand("  remove  ミュートを解除 (remove <id>)", tissue.OpTagMuteDelete)
+	printHiddenNote()
+}
+
+func cmdMuteList(args []string) {
+	fs := newFlagSet("mute list")
+	_ = fs.Parse(args)
+
+	cli := buildClient()
+	cli.require(tissue.OpTagMuteList)
+	result, err := cli.scraping.ListTagMutes(context.Background())
+	if err != nil {
+		cli.fail(err)
+	}
+	printResult(result)
+}
+
+func cmdMuteAdd(args []string) {
+	fs := newFlagSet("mute add")
+	setUsage(fs, "tissue mute add <tag>")
+	pos := parseMixed(fs, args)
+	if len(pos) < 1 {
+		die("usage: tissue mute add <tag>")
+	}
+
+	cli := buildClient()
+	cli.require(tissue.OpTagMuteCreate)
+	result, err := cli.scraping.MuteTag(context.Background(), pos[0])
+	if err != nil {
+		cli.fail(err)
+	}
+	printResult(result)
+}
+
+func cmdMuteRemove(args []string) {
+	fs := newFlagSet("mute remove")
+	setUsage(fs, "tissue mute remove <id>")
+	pos := parseMixed(fs, args)
+	if len(pos) < 1 {
+		die("usage: tissue mute remove <id>")
+	}
+
+	cli := buildClient()
+	cli.require(tissue.OpTagMuteDelete)
+	if err := cli.scraping.UnmuteTag(context.Background(), pos[0]); err != nil {
+		cli.fail(err)
+	}
+	fmt.Fprintln(os.Stderr, "removed.")
+}
@@ -0,0 +1,742 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	tissue "github.com/mohemohe/go-tissue"
+	"github.com/mohemohe/go-tissue/api"
+	"github.com/mohemohe/go-tissue/bulk"
+	"github.com/mohemohe/go-tissue/dedupe"
+	"github.com/mohemohe/go-tissue/privacy"
+	"github.com/mohemohe/go-tissue/suggest"
+)
+
+const (
+	outputJSON     = "json"
+	outputTable    = "table"
+	outputYAML     = "yaml"
+	outputTSV      = "tsv"
+	outputTemplate = "template"
+)
+
+const noteWidth = 40
+
+// printResult はグローバルオプション --output に従って v を標準出力に書き出す。
+func printResult(v interface{}) {
+	format := globals.output
+	if format == "" {
+		format = outputJSON
+		if globals.template != "" {
+			format = outputTemplate
+		}
+	}
+	w := bufio.NewWriter(os.Stdout)
+	var err error
+	switch format {
+	case outputJSON:
+		err = writeJSON(w, v)
+	case outputYAML:
+		err = writeYAML(w, v)
+	case outputTable, outputTSV:
+		err = writeTable(w, v, format == outputTSV, splitColumns(globals.columns))
+	case outputTemplate:
+		err = writeTemplate(w, v, globals.template)
+	default:
+		die("unknown --output: %s (want json/table/yaml/tsv/template)", format)
+	}
+	if err == nil {
+		err = w.Flush()
+	}
+	if err != nil {
+		die("failed to encode: %v", err)
+	}
+}
+
+func writeJSON(w io.Writer, v interface{}) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+func splitColumns(s string) []string {
+	var result []string
+	for _, c := range strings.Split(s, ",") {
+		if trimmed := strings.TrimSpace(c); trimmed != "" {
+			result = append(result, strings.ToLower(trimmed))
+		}
+	}
+	return result
+}
+
+// elements は v がスライスならその要素を、そうでなければ v 自身を1要素として返す。ポインタは剥がす。
+func elements(v interface{}) []interface{} {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return nil
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return []interface{}{rv.Interface()}
+	}
+	result := make([]interface{}, 0, rv.Len())
+	for i := 0; i < rv.Len(); i++ {
+		e := rv.Index(i)
+		for e.Kind() == reflect.Ptr && !e.IsNil() {
+			e = e.Elem()
+		}
+		result = append(result, e.Interface())
+	}
+	return result
+}
+
+func writeTemplate(w io.Writer, v interface{}, text string) error {
+	if text == "" {
+		return fmt.Errorf("--template is required for --output template")
+	}
+	tmpl, err := template.New("output").Funcs(template.FuncMap{
+		"join": strings.Join,
+		"json": func(v interface{}) (string, error) {
+			b, err := json.Marshal(v)
+			return string(b), err
+		},
+		"localtime": func(v interface{}) string {
+			switch t := v.(type) {
+			case tissue.Timestamp:
+				return formatLocalTime(t.Time)
+			case time.Time:
+				return formatLocalTime(t)
+			}
+			return fmt.Sprint(v)
+		},
+		"truncate": truncate,
+	}).Parse(text)
+	if err != nil {
+		return err
+	}
+	for _, e := range elements(v) {
+		if err := tmpl.Execute(w, e); err != nil {
+			return err
+		}
+		if !strings.HasSuffix(text, "\n") {
+			if _, err := io.WriteString(w, "\n"); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+type column struct {
+	name string
+	// extra な列は --columns で明示された場合のみ表示する。
+	extra bool
+	value func(v interface{}) string
+}
+
+func columnsFor(v interface{}) []column {
+	switch v.(type) {
+	case tissue.Checkin, tissue.UserCheckin:
+		return checkinColumns
+	case tissue.Collection:
+		return collectionColumns
+	case tissue.CollectionItem:
+		return collectionItemColumns
+	case tissue.Me:
+		return meColumns
+	case tissue.User:
+		return userColumns
+	case string:
+		return []column{{name: "tag", value: func(v interface{}) string { return v.(string) }}}
+	case tissue.TagCount:
+		return []column{
+			{name: "name", value: func(v interface{}) string { return v.(tissue.TagCount).Name }},
+			{name: "count", value: func(v interface{}) string { return strconv.Itoa(v.(tissue.TagCount).Count) }},
+		}
+	case tissue.DailyCheckinCount:
+		return []column{
+			{name: "date", value: func(v interface{}) string { return v.(tissue.DailyCheckinCount).Date }},
+			{name: "count", value: func(v interface{}) string { return strconv.Itoa(v.(tissue.DailyCheckinCount).Count) }},
+		}
+	case api.HourlyCheckinSummary:
+		return []column{
+			{name: "hour", value: func(v interface{}) string { return strconv.Itoa(v.(api.HourlyCheckinSummary).Hour) }},
+			{name: "count", value: func(v interface{}) string { return strconv.Itoa(v.(api.HourlyCheckinSummary).Count) }},
+		}
+	case api.LinkCount:
+		return []column{
+			{name: "link", value: func(v interface{}) string { return v.(api.LinkCount).Link }},
+			{name: "count", value: func(v interface{}) string { return strconv.Itoa(v.(api.LinkCount).Count) }},
+		}
+	case bulk.Change:
+		return []column{
+			{name: "target", value: func(v interface{}) string { return v.(bulk.Change).Target }},
+			{name: "id", value: func(v interface{}) string { return strconv.FormatInt(v.(bulk.Change).ID, 10) }},
+			{name: "collection_id", value: func(v interface{}) string {
+				if id := v.(bulk.Change).CollectionID; id != 0 {
+					return strconv.FormatInt(id, 10)
+				}
+				return ""
+			}},
+			{name: "before", value: func(v interface{}) string { return strings.Join(v.(bulk.Change).Before, ",") }},
+			{name: "after", value: func(v interface{}) string { return strings.Join(v.(bulk.Change).After, ",") }},
+			{name: "link", value: func(v interface{}) string { return v.(bulk.Change).Link }},
+			{name: "new_link", value: func(v interface{}) string { return v.(bulk.Change).NewLink }},
+		}
+	case bulk.ItemResult:
+		return []column{
+			{name: "id", value: func(v interface{}) string { return strconv.FormatInt(v.(bulk.ItemResult).ID, 10) }},
+			{name: "ok", value: func(v interface{}) string { return strconv.FormatBool(v.(bulk.ItemResult).OK) }},
+			{name: "error", value: func(v interface{}) string { return v.(bulk.ItemResult).Error }},
+		}
+	case privacy.Finding:
+		return []column{
+			{name: "target", value: func(v interface{}) string { return v.(privacy.Finding).Target }},
+			{name: "id", value: func(v interface{}) string { return strconv.FormatInt(v.(privacy.Finding).ID, 10) }},
+			{name: "reasons", value: func(v interface{}) string { return strings.Join(v.(privacy.Finding).Reasons, ",") }},
+			{name: "details", value: func(v interface{}) string { return strings.Join(v.(privacy.Finding).Details, ",") }},
+			{name: "title", value: func(v interface{}) string { return v.(privacy.Finding).Title }},
+			{name: "link", value: func(v interface{}) string { return v.(privacy.Finding).Link }},
+		}
+	case privacy.Result:
+		return []column{
+			{name: "target", value: func(v interface{}) string { return v.(privacy.Result).Target }},
+			{name: "id", value: func(v interface{}) string { return strconv.FormatInt(v.(privacy.Result).ID, 10) }},
+			{name: "ok", value: func(v interface{}) string { return strconv.FormatBool(v.(privacy.Result).OK) }},
+			{name: "error", value: func(v interface{}) string { return v.(privacy.Result).Error }},
+		}
+	case dedupe.CheckinGroup:
+		return []column{
+			{name: "ids", value: func(v interface{}) string {
+				var ids []string
+				for _, c := range v.(dedupe.CheckinGroup).Checkins {
+					ids = append(ids, strconv.FormatInt(c.ID, 10))
+				}
+				return strings.Join(ids, ",")
+			}},
+			{name: "first", value: func(v interface{}) string {
+				return formatLocalTime(v.(dedupe.CheckinGroup).Checkins[0].CheckedInAt.Time)
+			}},
+			{name: "link", value: func(v interface{}) string { return v.(dedupe.CheckinGroup).Link }},
+			{name: "tags", value: func(v interface{}) string { return strings.Join(v.(dedupe.CheckinGroup).Tags, ",") }},
+		}
+	case dedupe.ItemGroup:
+		return []column{
+			{name: "collection_id", value: func(v interface{}) string { return strconv.FormatInt(v.(dedupe.ItemGroup).CollectionID, 10) }},
+			{name: "ids", value: func(v interface{}) string {
+				var ids []string
+				for _, it := range v.(dedupe.ItemGroup).Items {
+					ids = append(ids, strconv.FormatInt(it.ID, 10))
+				}
+				return strings.Join(ids, ",")
+			}},
+			{name: "link", value: func(v interface{}) string { return v.(dedupe.ItemGroup).Link }},
+		}
+	case tagLintResult:
+		return []column{
+			{name: "tag", value: func(v interface{}) string { return v.(tagLintResult).Tag }},
+			{name: "count", value: func(v interface{}) string { return strconv.Itoa(v.(tagLintResult).Count) }},
+			{name: "kind", value: func(v interface{}) string { return v.(tagLintResult).Kind }},
+			{name: "suggested", value: func(v interface{}) string { return v.(tagLintResult).Suggested }},
+		}
+	case suggest.Suggestion:
+		return []column{
+			{name: "tag", value: func(v interface{}) string { return v.(suggest.Suggestion).Tag }},
+			{name: "score", value: func(v interface{}) string { return strconv.FormatFloat(v.(suggest.Suggestion).Score, 'f', 1, 64) }},
+			{name: "reasons", value: func(v interface{}) string { return strings.Join(v.(suggest.Suggestion).Reasons, ",") }},
+		}
+	}
+	return genericColumns(v)
+}
+
+func asCheckin(v interface{}) (tissue.Checkin, string) {
+	switch c := v.(type) {
+	case tissue.UserCheckin:
+		if c.CheckinInterval > 0 {
+			return c.Checkin, formatSeconds(c.CheckinInterval)
+		}
+		return c.Checkin, ""
+	case tissue.Checkin:
+		return c, ""
+	}
+	return tissue.Checkin{}, ""
+}
+
+var checkinColumns = []column{
+	{name: "id", value: func(v interface{}) string { c, _ := asCheckin(v); return strconv.FormatInt(c.ID, 10) }},
+	{name: "checked_in_at", value: func(v interface{}) string { c, _ := asCheckin(v); return formatLocalTime(c.CheckedInAt.Time) }},
+	{name: "interval", value: func(v interface{}) string { _, i := asCheckin(v); return i }},
+	{name: "tags", value: func(v interface{}) string { c, _ := asCheckin(v); return strings.Join(c.Tags, ",") }},
+	{name: "link", value: func(v interface{}) string { c, _ := asCheckin(v); return c.Link }},
+	{name: "note", value: func(v interface{}) string { c, _ := asCheckin(v); return truncate(c.Note, noteWidth) }},
+	{name: "private", extra: true, value: func(v interface{}) string { c, _ := asCheckin(v); return formatFlag(c.IsPrivate) }},
+	{name: "sensitive", extra: true, value: func(v interface{}) string { c, _ := asCheckin(v); return formatFlag(c.IsTooSensitive) }},
+	{name: "source", extra: true, value: func(v interface{}) string { c, _ := asCheckin(v); return c.Source }},
+	{name: "likes", extra: true, value: func(v interface{}) string { c, _ := asCheckin(v); return strconv.Itoa(c.LikesCount) }},
+	{name: "user", extra: true, value: func(v interface{}) string { c, _ := asCheckin(v); return c.User.Name }},
+}
+
+var collectionColumns = []column{
+	{name: "id", value: func(v interface{}) string { return strconv.FormatInt(v.(tissue.Collection).ID, 10) }},
+	{name: "title", value: func(v interface{}) string { return v.(tissue.Collection).Title }},
+	{name: "private", value: func(v interface{}) string { return formatFlag(v.(tissue.Collection).IsPrivate) }},
+	{name: "user", value: func(v interface{}) string { return collectionOwner(v.(tissue.Collection)) }},
+	{name: "updated_at", value: func(v interface{}) string { return formatLocalTime(v.(tissue.Collection).UpdatedAt.Time) }},
+}
+
+func collectionOwner(c tissue.Collection) string {
+	if c.UserName != "" {
+		return c.UserName
+	}
+	return c.User.Name
+}
+
+var collectionItemColumns = []column{
+	{name: "id", value: func(v interface{}) string { return strconv.FormatInt(v.(tissue.CollectionItem).ID, 10) }},
+	{name: "collection_id", value: func(v interface{}) string { return strconv.FormatInt(v.(tissue.CollectionItem).CollectionID, 10) }},
+	{name: "link", value: func(v interface{}) string { return v.(tissue.CollectionItem).Link }},
+	{name: "tags", value: func(v interface{}) string { return strings.Join(v.(tissue.CollectionItem).Tags, ",") }},
+	{name: "note", value: func(v interface{}) string { return truncate(v.(tissue.CollectionItem).Note, noteWidth) }},
+	{name: "user", extra: true, value: func(v interface{}) string { return v.(tissue.CollectionItem).UserName }},
+}
+
+func asUser(v interface{}) tissue.User {
+	switch u := v.(type) {
+	case tissue.Me:
+		return u.User
+	case tissue.User:
+		return u
+	}
+	return tissue.User{}
+}
+
+var userColumns = []column{
+	{name: "name", value: func(v interface{}) string { return asUser(v).Name }},
+	{name: "display_name", value: func(v interface{}) string { return asUser(v).DisplayName }},
+	{name: "protected", value: func(v interface{}) string { return formatFlag(asUser(v).IsProtected) }},
+	{name: "private_likes", value: func(v interface{}) string { return formatFlag(asUser(v).PrivateLikes) }},
+	{name: "url", extra: true, value: func(v interface{}) string { return asUser(v).URL }},
+	{name: "bio", extra: true, value: func(v interface{}) string { return truncate(asUser(v).Bio, noteWidth) }},
+}
+
+var meColumns = append(append([]column{}, userColumns...),
+	column{name: "total_checkins", value: func(v interface{}) string {
+		return strconv.FormatInt(v.(tissue.Me).CheckinSummary.TotalCheckins, 10)
+	}},
+	column{name: "current_session", value: func(v interface{}) string {
+		return formatSeconds(v.(tissue.Me).CheckinSummary.CurrentSessionElapsed)
+	}},
+)
+
+// genericColumns は専用の列定義が無い型について、JSON 表現のトップレベルのキーを列として扱う。
+func genericColumns(v interface{}) []column {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil
+	}
+	node, err := decodeOrdered(b)
+	if err != nil {
+		return nil
+	}
+	obj, ok := node.(orderedObject)
+	if !ok {
+		return []column{{name: "value", value: func(v interface{}) string { return fmt.Sprint(v) }}}
+	}
+	var result []column
+	for _, kv := range obj {
+		key := kv.key
+		result = append(result, column{name: key, value: func(v interface{}) string {
+			b, _ := json.Marshal(v)
+			node, _ := decodeOrdered(b)
+			obj, _ := node.(orderedObject)
+			for _, kv := range obj {
+				if kv.key == key {
+					return scalarString(kv.value)
+				}
+			}
+			return ""
+		}})
+	}
+	return result
+}
+
+func scalarString(v interface{}) string {
+	switch x := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return x
+	case json.Number:
+		return x.String()
+	case bool:
+		return formatFlag(x)
+	}
+	b, _ := json.Marshal(orderedToPlain(v))
+	return string(b)
+}
+
+func selectColumns(all []column, names []string) ([]column, error) {
+	if len(names) == 0 {
+		var result []column
+		for _, c := range all {
+			if !c.extra {
+				result = append(result, c)
+			}
+		}
+		return result, nil
+	}
+	result := make([]column, 0, len(names))
+	for _, name := range names {
+		found := false
+		for _, c := range all {
+			if c.name == name {
+				result = append(result, c)
+				found = true
+				break
+			}
+		}
+		if !found {
+			available := make([]string, len(all))
+			for i, c := range all {
+				available[i] = c.name
+			}
+			return nil, fmt.Errorf("unknown column %q (available: %s)", name, strings.Join(available, ","))
+		}
+	}
+	return result, nil
+}
+
+func writeTable(w io.Writer, v interface{}, tsv bool, names []string) error {
+	elems := elements(v)
+	if len(elems) == 0 {
+		return nil
+	}
+	cols, err := selectColumns(columnsFor(elems[0]), names)
+	if err != nil {
+		return err
+	}
+	header := make([]string, len(cols))
+	for i, c := range cols {
+		header[i] = strings.ToUpper(c.name)
+	}
+	rows := [][]string{header}
+	for _, e := range elems {
+		row := make([]string, len(cols))
+		for i, c := range cols {
+			row[i] = c.value(e)
+		}
+		rows = append(rows, row)
+	}
+
+	if tsv {
+		for _, row := range rows {
+			for i := range row {
+				row[i] = strings.NewReplacer("\t", " ", "\n", " ", "\r", "").Replace(row[i])
+			}
+			if _, err := fmt.Fprintln(w, strings.Join(row, "\t")); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	widths := make([]int, len(cols))
+	for _, row := range rows {
+		for i, cell := range row {
+			if n := displayWidth(cell); n > widths[i] {
+				widths[i] = n
+			}
+		}
+	}
+	for _, row := range rows {
+		var sb strings.Builder
+		for i, cell := range row {
+			sb.WriteString(cell)
+			if i < len(row)-1 {
+				sb.WriteString(strings.Repeat(" ", widths[i]-displayWidth(cell)+2))
+			}
+		}
+		if _, err := fmt.Fprintln(w, strings.TrimRight(sb.String(), " ")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// displayWidth は端末上の表示幅を概算する。東アジアの全角文字は幅2として数える。
+func displayWidth(s string) int {
+	n := 0
+	for _, r := range s {
+		switch {
+		case r < 0x20:
+		case isWide(r):
+			n += 2
+		default:
+			n++
+		}
+	}
+	return n
+}
+
+func isWide(r rune) bool {
+	return unicode.Is(unicode.Han, r) ||
+		unicode.Is(unicode.Hiragana, r) ||
+		unicode.Is(unicode.Katakana, r) && !(r >= 0xff61 && r <= 0xff9f) ||
+		unicode.Is(unicode.Hangul, r) ||
+		(r >= 0x3000 && r <= 0x303f) ||
+		(r >= 0xff01 && r <= 0xff60) ||
+		(r >= 0x1f300 && r <= 0x1faff)
+}
+
+// truncate は改行を空白に置き換え、表示幅 width を超える部分を … で省略する。
+func truncate(s string, width int) string {
+	s = strings.Join(strings.Fields(s), " ")
+	if displayWidth(s) <= width {
+		return s
+	}
+	var sb strings.Builder
+	n := 0
+	for _, r := range s {
+		rw := displayWidth(string(r))
+		if n+rw > width-1 {
+			break
+		}
+		sb.WriteRune(r)
+		n += rw
+	}
+	return sb.String() + "…"
+}
+
+func formatLocalTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.In(location()).Format("2006-01-02 15:04")
+}
+
+// formatSeconds は秒数を "1日 02:03" 形式で表す。
+func formatSeconds(sec int64) string {
+	d := sec / 86400
+	h := sec % 86400 / 3600
+	m := sec % 3600 / 60
+	if d > 0 {
+		return fmt.Sprintf("%d日 %02d:%02d", d, h, m)
+	}
+	return fmt.Sprintf("%02d:%02d", h, m)
+}
+
+func formatFlag(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
+
+type orderedKV struct {
+	key   string
+	value interface{}
+}
+
+// orderedObject はキーの出現順を保持した JSON オブジェクト。
+type orderedObject []orderedKV
+
+func decodeOrdered(b []byte) (interface{}, error) {
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	return decodeOrderedValue(dec)
+}
+
+func decodeOrderedValue(dec *json.Decoder) (interface{}, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch t := tok.(type) {
+	case json.Delim:
+		switch t {
+		case '{':
+			obj := orderedObject{}
+			for dec.More() {
+				keyTok, err := dec.Token()
+				if err != nil {
+					return nil, err
+				}
+				val, err := decodeOrderedValue(dec)
+				if err != nil {
+					return nil, err
+				}
+				obj = append(obj, orderedKV{key: keyTok.(string), value: val})
+			}
+			_, err := dec.Token()
+			return obj, err
+		case '[':
+			arr := []interface{}{}
+			for dec.More() {
+				val, err := decodeOrderedValue(dec)
+				if err != nil {
+					return nil, err
+				}
+				arr = append(arr, val)
+			}
+			_, err := dec.Token()
+			return arr, err
+		}
+	}
+	return tok, nil
+}
+
+func orderedToPlain(v interface{}) interface{} {
+	switch x := v.(type) {
+	case orderedObject:
+		m := make(map[string]interface{}, len(x))
+		for _, kv := range x {
+			m[kv.key] = orderedToPlain(kv.value)
+		}
+		return m
+	case []interface{}:
+		result := make([]interface{}, len(x))
+		for i, e := range x {
+			result[i] = orderedToPlain(e)
+		}
+		return result
+	}
+	return v
+}
+
+// writeYAML は v の JSON 表現を、フィールド順を保ったまま YAML として書き出す。
+func writeYAML(w io.Writer, v interface{}) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	node, err := decodeOrdered(b)
+	if err != nil {
+		return err
+	}
+	bw := bufio.NewWriter(w)
+	writeYAMLNode(bw, node, 0, false)
+	return bw.Flush()
+}
+
+func writeYAMLNode(w *bufio.Writer, node interface{}, indent int, inList bool) {
+	pad := strings.Repeat("  ", indent)
+	switch x := node.(type) {
+	case orderedObject:
+		if len(x) == 0 {
+			w.WriteString(pad + "{}\n")
+			return
+		}
+		for i, kv := range x {
+			prefix := pad
+			if inList && i == 0 {
+				prefix = ""
+			}
+			w.WriteString(prefix + yamlString(kv.key) + ":")
+			writeYAMLChild(w, kv.value, indent+1)
+		}
+	case []interface{}:
+		if len(x) == 0 {
+			w.WriteString(pad + "[]\n")
+			return
+		}
+		for _, e := range x {
+			w.WriteString(pad + "-")
+			switch e.(type) {
+			case orderedObject, []interface{}:
+				if isEmptyContainer(e) {
+					w.WriteString(" ")
+					writeYAMLNode(w, e, 0, true)
+					continue
+				}
+				if _, ok := e.(orderedObject); ok {
+					w.WriteString(" ")
+					writeYAMLNode(w, e, indent+1, true)
+					continue
+				}
+				w.WriteString("\n")
+				writeYAMLNode(w, e, indent+1, false)
+			default:
+				w.WriteString(" " + yamlScalar(e) + "\n")
+			}
+		}
+	default:
+		w.WriteString(pad + yamlScalar(x) + "\n")
+	}
+}
+
+func writeYAMLChild(w *bufio.Writer, v interface{}, indent int) {
+	switch v.(type) {
+	case orderedObject, []interface{}:
+		if isEmptyContainer(v) {
+			w.WriteString(" ")
+			writeYAMLNode(w, v, 0, true)
+			return
+		}
+		w.WriteString("\n")
+		writeYAMLNode(w, v, indent, false)
+	default:
+		w.WriteString(" " + yamlScalar(v) + "\n")
+	}
+}
+
+func isEmptyContainer(v interface{}) bool {
+	switch x := v.(type) {
+	case orderedObject:
+		return len(x) == 0
+	case []interface{}:
+		return len(x) == 0
+	}
+	return false
+}
+
+func yamlScalar(v interface{}) string {
+	switch x := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return strconv.FormatBool(x)
+	case json.Number:
+		return x.String()
+	case string:
+		return yamlString(x)
+	}
+	return yamlString(fmt.Sprint(v))
+}
+
+func yamlString(s string) string {
+	if s == "" || !utf8.ValidString(s) {
+		return strconv.Quote(s)
+	}
+	switch strings.ToLower(s) {
+	case "true", "false", "null", "yes", "no", "on", "off", "~":
+		return strconv.Quote(s)
+	}
+	if _, err := strconv.ParseFloat(s, 64); err == nil {
+		return strconv.Quote(s)
+	}
+	if strings.TrimSpace(s) != s || strings.ContainsAny(s[:1], "-?:,[]{}#&*!|>'\"%@`") {
+		return strconv.Quote(s)
+	}
+	if strings.Contains(s, ": ") || strings.Contains(s, " #") || strings.HasSuffix(s, ":") {
+		return strconv.Quote(s)
+	}
+	for _, r := range s {
+		if unicode.IsControl(r) {
+			return strconv.Quote(s)
+		}
+	}
+	return s
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	tissue "github.com/mohemohe/go-tissue"
+)
+
+func TestWriteTable_Checkins(t *testing.T) {
+	// 日時は設定のタイムゾーンで表示する
+	saved := timeLocation
+	timeLocation = time.FixedZone("JST", 9*60*60)
+	defer func() { timeLocation = saved }()
+	checkins := []tissue.UserCheckin{
+		{
+			Checkin: tissue.Checkin{
+				ID:          1,
+				CheckedInAt: tissue.Timestamp{Time: time.Date(2024, 5, 1, 3, 34, 0, 0, time.UTC)},
+				Tags:        []string{"巨乳", "test"},
+				Note:        "line1\nline2",
+			},
+			CheckinInterval: 90061,
+		},
+	}
+	buf := &bytes.Buffer{}
+	if err := writeTable(buf, checkins, false, nil); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimRight(buf.String(), "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("unexpected lines: %q", lines)
+	}
+	if !strings.HasPrefix(lines[0], "ID") || !strings.Contains(lines[0], "INTERVAL") {
+		t.Errorf("unexpected header: %q", lines[0])
+	}
+	for _, want := range []string{"2024-05-01 12:34", "1日 01:01", "巨乳,test", "line1 line2"} {
+		if !strings.Contains(lines[1], want) {
+			t.Errorf("row %q does not contain %q", lines[1], want)
+		}
+	}
+
+	buf.Reset()
+	if err := writeTable(buf, checkins, true, []string{"id", "tags"}); err != nil {
+		t.Fatal(err)
+	}
+	if got := buf.String(); got != "ID\tTAGS\n1\t巨乳,test\n" {
+		t.Errorf("unexpected tsv: %q", got)
+	}
+
+	if err := writeTable(buf, checkins, true, []string{"nope"}); err == nil {
+		t.Error("expected unknown column error")
+	}
+}
+
+func TestWriteYAML(t *testing.T) {
+	buf := &bytes.Buffer{}
+	err := writeYAML(buf, []tissue.CollectionItem{{ID: 2, Link: "https://example.com", Tags: []string{"a", "true"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, want := range []string{"- id: 2\n", "  link: https://example.com\n", "  tags:\n    - a\n    - \"true\"\n"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("yaml %q does not contain %q", out, want)
+		}
+	}
+}
+
+func TestWriteTemplate(t *testing.T) {
+	buf := &bytes.Buffer{}
+	err := writeTemplate(buf, []tissue.Collection{{ID: 1, Title: "a"}, {ID: 2, Title: "b"}}, "{{.ID}} {{.Title}}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := buf.String(); got != "1 a\n2 b\n" {
+		t.Errorf("unexpected output: %q", got)
+	}
+}
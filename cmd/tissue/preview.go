@@ -0,0 +1,26 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/mohemohe/go-tissue/metadata"
+)
+
+// linkResolver はリンク先のメタデータの取得に使う。プロセス内で結果を共有する。
+var linkResolver = metadata.New(nil)
+
+// previewLink はリンク先のタイトルを標準エラー出力に表示する。取得に失敗しても投稿は続ける。
+func previewLink(ctx context.Context, link string) {
+	meta, err := linkResolver.Resolve(ctx, link)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "preview: failed to resolve %s: %v\n", link, err)
+		return
+	}
+	title := meta.Title
+	if title == "" {
+		title = "(no title)"
+	}
+	fmt.Fprintf(os.Stderr, "preview: %s [%s]\n", title, meta.Resolver)
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	tissue "github.com/mohemohe/go-tissue"
+	"github.com/mohemohe/go-tissue/bulk"
+	"github.com/mohemohe/go-tissue/privacy"
+)
+
+func cmdPrivacy(args []string) {
+	if len(args) == 0 {
+		usagePrivacy()
+		os.Exit(1)
+	}
+	sub, rest := args[0], args[1:]
+	switch sub {
+	case "audit":
+		cmdPrivacyAudit(rest)
+	case "apply":
+		cmdPrivacyApply(rest)
+	case "-h", "--help", "help":
+		usagePrivacy()
+	default:
+		die("unknown privacy subcommand: %s", sub)
+	}
+}
+
+func usagePrivacy() {
+	fmt.Fprintln(os.Stderr, "usage: tissue privacy <subcommand>")
+	printAPICommand("  audit   条件に該当する公開チェックイン・コレクションを報告", tissue.OpUserCheckins, tissue.OpCollectionList)
+	printAPICommand("  apply   条件に該当する公開チェックイン・コレクションを非公開にする", tissue.OpCheckinUpdate, tissue.OpCollectionUpdate)
+	printHiddenNote()
+}
+
+// privacyFlags は audit / apply に共通の条件のフラグ。
+type privacyFlags struct {
+	rules        *string
+	tooSensitive *bool
+	domains      *string
+	keywords     *string
+	allPublic    *bool
+}
+
+func addPrivacyFlags(fs *flag.FlagSet) *privacyFlags {
+	return &privacyFlags{
+		rules:        fs.String("rules", "", "条件のファイル (既定: TISSUE_PRIVACY_RULES / プロファイルの privacy_rules)"),
+		tooSensitive: fs.Bool("too-sensitive", false, "過激フラグ付きの公開チェックインを報告"),
+		domains:      fs.String("domains", "", "リンク先のドメイン (カンマ区切り、サブドメインも含む)"),
+		keywords:     fs.String("keywords", "", "ノート・コレクションのタイトルに含まれる語 (カンマ区切り)"),
+		allPublic:    fs.Bool("all-public", false, "条件に関係なくすべての公開エントリを対象にする"),
+	}
+}
+
+// load はファイルの条件にフラグの条件を足したものを返す。
+func (f *privacyFlags) load(cfg *Config) *privacy.Rules {
+	rules := &privacy.Rules{}
+	path := *f.rules
+	if path == "" {
+		path = cfg.PrivacyRules
+	}
+	if path != "" {
+		r, err := privacy.LoadFile(path)
+		if err != nil {
+			die("failed to load privacy rules: %v", err)
+		}
+		rules = r
+	}
+	rules.TooSensitive = rules.TooSensitive || *f.tooSensitive
+	rules.AllPublic = rules.AllPublic || *f.allPublic
+	rules.Domains = append(rules.Domains, splitTags(*f.domains)...)
+	rules.Keywords = append(rules.Keywords, splitTags(*f.keywords)...)
+	return rules
+}
+
+func emptyPrivacyRules(r *privacy.Rules) bool {
+	return !r.TooSensitive && !r.AllPublic && len(r.Domains) == 0 && len(r.Keywords) == 0
+}
+
+// audit は自分の全チェックイン・コレクションを監査し、概要を標準エラー出力に表示する。
+func audit(ctx context.Context, cli *clientBundle, rules *privacy.Rules) *privacy.Report {
+	cli.op = tissue.OpUserCheckins
+	report, err := privacy.Audit(ctx, cli.api, cli.meName(ctx), rules)
+	if err != nil {
+		cli.fail(err)
+	}
+	fmt.Fprintf(os.Stderr, "public: %d checkin(s), %d collection(s); matched: %d\n",
+		report.PublicCheckins, report.PublicCollections, len(report.Findings))
+	return report
+}
+
+func cmdPrivacyAudit(args []string) {
+	fs := newFlagSet("privacy audit")
+	f := addPrivacyFlags(fs)
+	_ = fs.Parse(args)
+
+	cli := buildClient()
+	cli.requireAPI("privacy audit")
+	for _, op := range []tissue.Operation{tissue.OpUserCheckins, tissue.OpCollectionList, tissue.OpCollectionItemList} {
+		cli.require(op)
+	}
+	rules := f.load(cli.config)
+	if emptyPrivacyRules(rules) {
+		// 条件が無ければ、公開されているものをすべて報告する
+		rules.AllPublic = true
+	}
+	report := audit(context.Background(), cli, rules)
+	printResult(report.Findings)
+}
+
+func cmdPrivacyApply(args []string) {
+	fs := newFlagSet("privacy apply")
+	f := addPrivacyFlags(fs)
+	dryRun := fs.Bool("dry-run", false, "非公開にする対象を表示するだけで変更しない")
+	yes := fs.Bool("yes", false, "確認せずに実行する")
+	concurrency := fs.Int("concurrency", bulk.DefaultConcurrency, "同時に実行する数")
+	interval := fs.Duration("interval", 0, "各更新を開始する間隔")
+	_ = fs.Parse(args)
+
+	cli := buildClient()
+	cli.requireAPI("privacy apply")
+	ops := []tissue.Operation{tissue.OpUserCheckins, tissue.OpCollectionList, tissue.OpCollectionItemList}
+	if !*dryRun {
+		ops = append(ops, tissue.OpCheckinUpdate, tissue.OpCollectionUpdate)
+	}
+	for _, op := range ops {
+		cli.require(op)
+	}
+	rules := f.load(cli.config)
+	if emptyPrivacyRules(rules) {
+		die("specify conditions (--rules, --too-sensitive, --domains, --keywords) or --all-public")
+	}
+	ctx := context.Background()
+	report := audit(ctx, cli, rules)
+	if *dryRun || len(report.Findings) == 0 {
+		printResult(report.Findings)
+		return
+	}
+	if !*yes && !confirmPrivacy(report.Findings) {
+		die("aborted.")
+	}
+	results := privacy.Apply(ctx, cli.api, report.Findings, &privacy.ApplyOption{
+		Concurrency: *concurrency,
+		Interval:    *interval,
+		OnResult: func(r privacy.Result) {
+			if r.OK {
+				fmt.Fprintf(os.Stderr, "ok %s %d\n", r.Target, r.ID)
+			} else {
+				fmt.Fprintf(os.Stderr, "failed %s %d: %s\n", r.Target, r.ID, r.Error)
+			}
+		},
+	})
+	printResult(results)
+	for _, r := range results {
+		if !r.OK {
+			os.Exit(1)
+		}
+	}
+}
+
+func confirmPrivacy(findings []privacy.Finding) bool {
+	const shown = 10
+	for i, f := range findings {
+		if i == shown {
+			fmt.Fprintf(os.Stderr, "  ... and %d more\n", len(findings)-shown)
+			break
+		}
+		fmt.Fprintf(os.Stderr, "  %s %d  %v  %s%s\n", f.Target, f.ID, f.Reasons, f.Title, f.Link)
+	}
+	return confirm(fmt.Sprintf("make %d entries private?", len(findings)))
+}
@@ -0,0 +1,218 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	tissue "github.com/mohemohe/go-tissue"
+)
+
+func cmdProfile(args []string) {
+	if len(args) == 0 {
+		usageProfile()
+		os.Exit(1)
+	}
+	sub, rest := args[0], args[1:]
+	switch sub {
+	case "list":
+		cmdProfileList(rest)
+	case "use":
+		cmdProfileUse(rest)
+	case "remove":
+		cmdProfileRemove(rest)
+	case "show":
+		cmdProfileShow(rest)
+	case "set":
+		cmdProfileSet(rest)
+	case "-h", "--help", "help":
+		usageProfile()
+	default:
+		die("unknown profile subcommand: %s", sub)
+	}
+}
+
+func usageProfile() {
+	fmt.Fprintln(os.Stderr, "usage: tissue profile <subcommand>")
+	fmt.Fprintln(os.Stderr, "  list    プロファイル一覧")
+	fmt.Fprintln(os.Stderr, "  use     既定プロファイルを変更")
+	fmt.Fprintln(os.Stderr, "  remove  プロファイルを削除")
+	fmt.Fprintln(os.Stderr, "  show    プロファイルの内容を表示 (秘密情報はマスク)")
+	printCommand("  set     Tissue アカウントのプロフィール・プライバシー設定を変更", tissue.OpProfileUpdate, tissue.OpPrivacyUpdate)
+	printHiddenNote()
+}
+
+// cmdProfileSet は設定プロファイルではなく、Tissue 上のユーザープロフィールを変更する。
+func cmdProfileSet(args []string) {
+	fs := newFlagSet("profile set")
+	setUsage(fs, "tissue profile set [--display-name NAME] [--bio TEXT] [--url URL] [--protected=true|false] [--private-likes=true|false]")
+	displayName := fs.String("display-name", "", "表示名")
+	bio := fs.String("bio", "", "自己紹介 (空文字でクリア)")
+	link := fs.String("url", "", "URL (空文字でクリア)")
+	protected := fs.String("protected", "", "チェックイン履歴を非公開にする (true/false)")
+	privateLikes := fs.String("private-likes", "", "いいね一覧を非公開にする (true/false)")
+	_ = fs.Parse(args)
+
+	// 空文字でのクリアを指定なしと区別するため、明示されたフラグだけを変更する
+	given := map[string]bool{}
+	fs.Visit(func(f *flag.Flag) { given[f.Name] = true })
+	profile := &tissue.UpdateProfileOption{}
+	if given["display-name"] {
+		if *displayName == "" {
+			die("--display-name cannot be empty")
+		}
+		profile.DisplayName = displayName
+	}
+	if given["bio"] {
+		profile.Bio = bio
+	}
+	if given["url"] {
+		profile.URL = link
+	}
+	privacy := &tissue.UpdatePrivacyOption{}
+	if b, ok := parseOptionalBool(*protected); ok {
+		privacy.IsProtected = &b
+	}
+	if b, ok := parseOptionalBool(*privateLikes); ok {
+		privacy.PrivateLikes = &b
+	}
+	updateProfile := profile.DisplayName != nil || profile.Bio != nil || profile.URL != nil
+	updatePrivacy := privacy.IsProtected != nil || privacy.PrivateLikes != nil
+	if !updateProfile && !updatePrivacy {
+		die("nothing to update (see `tissue profile set --help`)")
+	}
+
+	cli := buildClient()
+	ctx := context.Background()
+	var user *tissue.User
+	var err error
+	// 片方だけ変更された状態で止まらないよう、先に両方を確認する
+	if updatePrivacy {
+		cli.require(tissue.OpPrivacyUpdate)
+	}
+	if updateProfile {
+		cli.require(tissue.OpProfileUpdate)
+		if user, err = cli.scraping.UpdateProfile(ctx, profile); err != nil {
+			cli.fail(err)
+		}
+	}
+	if updatePrivacy {
+		cli.op = tissue.OpPrivacyUpdate
+		if user, err = cli.scraping.UpdatePrivacy(ctx, privacy); err != nil {
+			cli.fail(err)
+		}
+	}
+	printResult(user)
+}
+
+type profileEntry struct {
+	Name       string `json:"name"`
+	Default    bool   `json:"default"`
+	AuthMethod string `json:"auth_method"`
+	BaseURL    string `json:"base_url,omitempty"`
+}
+
+func mustLoadConfigFile() *ConfigFile {
+	file, err := loadConfigFile()
+	if err != nil {
+		die("config not loaded (run `tissue configure` first): %v", err)
+	}
+	return file
+}
+
+func cmdProfileList(args []string) {
+	fs := newFlagSet("profile list")
+	_ = fs.Parse(args)
+
+	file := mustLoadConfigFile()
+	result := []profileEntry{}
+	for _, name := range file.profileNames() {
+		cfg := file.Profiles[name]
+		result = append(result, profileEntry{
+			Name:       name,
+			Default:    name == file.DefaultProfile,
+			AuthMethod: cfg.AuthMethod,
+			BaseURL:    cfg.BaseURL,
+		})
+	}
+	printResult(result)
+}
+
+func cmdProfileUse(args []string) {
+	fs := newFlagSet("profile use")
+	setUsage(fs, "tissue profile use <name>")
+	pos := parseMixed(fs, args)
+	if len(pos) < 1 {
+		die("usage: tissue profile use <name>")
+	}
+	file := mustLoadConfigFile()
+	if _, ok := file.Profiles[pos[0]]; !ok {
+		die("profile %q not found", pos[0])
+	}
+	file.DefaultProfile = pos[0]
+	if err := saveConfigFile(file); err != nil {
+		die("failed to save config: %v", err)
+	}
+	fmt.Fprintf(os.Stderr, "default profile: %s\n", pos[0])
+}
+
+func cmdProfileRemove(args []string) {
+	fs := newFlagSet("profile remove")
+	setUsage(fs, "tissue profile remove <name>")
+	pos := parseMixed(fs, args)
+	if len(pos) < 1 {
+		die("usage: tissue profile remove <name>")
+	}
+	file := mustLoadConfigFile()
+	if _, ok := file.Profiles[pos[0]]; !ok {
+		die("profile %q not found", pos[0])
+	}
+	if err := deleteSecrets(file.Profiles[pos[0]]); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: failed to delete secrets: %v\n", err)
+	}
+	delete(file.Profiles, pos[0])
+	if file.DefaultProfile == pos[0] {
+		file.DefaultProfile = ""
+		if names := file.profileNames(); len(names) > 0 {
+			file.DefaultProfile = names[0]
+		}
+	}
+	if err := saveConfigFile(file); err != nil {
+		die("failed to save config: %v", err)
+	}
+	fmt.Fprintln(os.Stderr, "removed.")
+}
+
+func cmdProfileShow(args []string) {
+	fs := newFlagSet("profile show")
+	setUsage(fs, "tissue profile show [name]")
+	pos := parseMixed(fs, args)
+	file := mustLoadConfigFile()
+	name := selectedProfile(file)
+	if len(pos) > 0 {
+		name = pos[0]
+	}
+	cfg, ok := file.Profiles[name]
+	if !ok {
+		die("profile %q not found", name)
+	}
+	masked := *cfg
+	masked.AccessToken = maskSecret(masked.AccessToken)
+	masked.Password = maskSecret(masked.Password)
+	printResult(struct {
+		Name string `json:"name"`
+		Config
+	}{Name: name, Config: masked})
+}
+
+// maskSecret は秘密情報を末尾4文字以外伏せ字にする。
+func maskSecret(s string) string {
+	if s == "" {
+		return ""
+	}
+	if len(s) <= 8 {
+		return "****"
+	}
+	return "****" + s[len(s)-4:]
+}
@@ -0,0 +1,212 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+)
+
+const (
+	sourceDefault = "default"
+	sourceInfer   = "inferred"
+)
+
+// configSources は解決済み設定の各フィールドがどこから来たかを保持する。
+type configSources map[string]string
+
+type configOverride struct {
+	field string
+	flag  string
+	value string
+	env   string
+	apply func(cfg *Config, v string)
+}
+
+func configOverrides() []configOverride {
+	return []configOverride{
+		{field: "base_url", flag: "--base-url", value: globals.baseURL, env: "TISSUE_BASE_URL",
+			apply: func(cfg *Config, v string) { cfg.BaseURL = v }},
+		{field: "auth_method", flag: "--auth-method", value: globals.authMethod, env: "TISSUE_AUTH_METHOD",
+			apply: func(cfg *Config, v string) { cfg.AuthMethod = v }},
+		{field: "access_token", flag: "--token", value: globals.accessToken, env: "TISSUE_ACCESS_TOKEN",
+			apply: func(cfg *Config, v string) {
+				clearSecret(cfg, secretAccessToken)
+				cfg.AccessToken = v
+			}},
+		{field: "email", flag: "--email", value: globals.email, env: "TISSUE_EMAIL",
+			apply: func(cfg *Config, v string) { cfg.Email = v }},
+		{field: "password", flag: "--password", value: globals.password, env: "TISSUE_PASSWORD",
+			apply: func(cfg *Config, v string) {
+				clearSecret(cfg, secretPassword)
+				cfg.Password = v
+			}},
+		{field: "tag_rules", flag: "--tag-rules", value: globals.tagRules, env: "TISSUE_TAG_RULES",
+			apply: func(cfg *Config, v string) { cfg.TagRules = v }},
+		{field: "link_rules", flag: "--link-rules", value: globals.linkRules, env: "TISSUE_LINK_RULES",
+			apply: func(cfg *Config, v string) { cfg.LinkRules = v }},
+		{field: "duplicate_window", flag: "--duplicate-window", value: globals.dupWindow, env: "TISSUE_DUPLICATE_WINDOW",
+			apply: func(cfg *Config, v string) { cfg.DuplicateWindow = v }},
+		{field: "privacy_rules", env: "TISSUE_PRIVACY_RULES",
+			apply: func(cfg *Config, v string) { cfg.PrivacyRules = v }},
+		{field: "timezone", flag: "--timezone", value: globals.timezone, env: "TISSUE_TIMEZONE",
+			apply: func(cfg *Config, v string) { cfg.Timezone = v }},
+	}
+}
+
+// resolveConfig はグローバルフラグ、TISSUE_* 環境変数、プロファイルの順に優先して設定を組み立てる。
+// 結果はメモリ上だけのもので、設定ファイルには保存しない。
+func resolveConfig() (*Config, configSources, error) {
+	sources := configSources{}
+	file, err := loadConfigFile()
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, nil, err
+	}
+	name := selectedProfile(file)
+	cfg := &Config{Name: name}
+	if file != nil {
+		if p, ok := file.Profiles[name]; ok {
+			c := *p
+			cfg = &c
+			src := "profile " + name
+			for field, set := range map[string]bool{
+				"base_url":         c.BaseURL != "",
+				"auth_method":      c.AuthMethod != "",
+				"access_token":     c.hasSecret(secretAccessToken),
+				"email":            c.Email != "",
+				"password":         c.hasSecret(secretPassword),
+				"tag_rules":        c.TagRules != "",
+				"link_rules":       c.LinkRules != "",
+				"duplicate_window": c.DuplicateWindow != "",
+				"privacy_rules":    c.PrivacyRules != "",
+				"timezone":         c.Timezone != "",
+			} {
+				if set {
+					sources[field] = src
+				}
+			}
+		} else if globals.profile != "" || os.Getenv("TISSUE_PROFILE") != "" {
+			return nil, nil, fmt.Errorf("profile %q not found", name)
+		}
+	}
+
+	for _, o := range configOverrides() {
+		if v := os.Getenv(o.env); v != "" {
+			o.apply(cfg, v)
+			sources[o.field] = "env " + o.env
+		}
+		if o.value != "" {
+			o.apply(cfg, o.value)
+			sources[o.field] = "flag " + o.flag
+		}
+	}
+
+	if cfg.AuthMethod == "" {
+		switch {
+		case cfg.hasSecret(secretAccessToken) && cfg.Email != "":
+			cfg.AuthMethod = authMethodHybrid
+			sources["auth_method"] = sourceInfer
+		case cfg.hasSecret(secretAccessToken):
+			cfg.AuthMethod = authMethodToken
+			sources["auth_method"] = sourceInfer
+		case cfg.Email != "":
+			cfg.AuthMethod = authMethodAccount
+			sources["auth_method"] = sourceInfer
+		default:
+			return nil, nil, errors.New("no configuration found")
+		}
+	}
+	if cfg.BaseURL == "" {
+		sources["base_url"] = sourceDefault
+	}
+	return cfg, sources, nil
+}
+
+func mustResolveConfig() *Config {
+	cfg, _, err := resolveConfig()
+	if err != nil {
+		die("config not loaded (run `tissue configure` first, or set TISSUE_* / --token): %v", err)
+	}
+	return cfg
+}
+
+func cmdConfig(args []string) {
+	if len(args) == 0 {
+		usageConfig()
+		os.Exit(1)
+	}
+	sub, rest := args[0], args[1:]
+	switch sub {
+	case "show":
+		cmdConfigShow(rest)
+	case "-h", "--help", "help":
+		usageConfig()
+	default:
+		die("unknown config subcommand: %s", sub)
+	}
+}
+
+func usageConfig() {
+	fmt.Fprintln(os.Stderr, "usage: tissue config <subcommand>")
+	fmt.Fprintln(os.Stderr, "  show    設定を表示 (--resolved でフラグ・環境変数適用後の値と出所)")
+}
+
+type resolvedField struct {
+	Field  string `json:"field"`
+	Value  string `json:"value"`
+	Source string `json:"source"`
+}
+
+func cmdConfigShow(args []string) {
+	fs := newFlagSet("config show")
+	resolved := fs.Bool("resolved", false, "フラグ・環境変数を適用した最終的な値と、その出所を表示")
+	_ = fs.Parse(args)
+
+	if !*resolved {
+		cmdProfileShow(nil)
+		return
+	}
+	cfg, sources, err := resolveConfig()
+	if err != nil {
+		die("%v", err)
+	}
+	token, password := describeSecret(cfg, secretAccessToken), describeSecret(cfg, secretPassword)
+	result := []resolvedField{
+		{Field: "profile", Value: cfg.Name, Source: profileSource()},
+		{Field: "base_url", Value: defaultOr(cfg.BaseURL, "https://shikorism.net"), Source: sources["base_url"]},
+		{Field: "auth_method", Value: cfg.AuthMethod, Source: sources["auth_method"]},
+		{Field: "access_token", Value: token, Source: sources["access_token"]},
+		{Field: "email", Value: cfg.Email, Source: sources["email"]},
+		{Field: "password", Value: password, Source: sources["password"]},
+		{Field: "tag_rules", Value: cfg.TagRules, Source: sources["tag_rules"]},
+		{Field: "link_rules", Value: cfg.LinkRules, Source: sources["link_rules"]},
+		{Field: "duplicate_window", Value: cfg.DuplicateWindow, Source: sources["duplicate_window"]},
+		{Field: "privacy_rules", Value: cfg.PrivacyRules, Source: sources["privacy_rules"]},
+		{Field: "timezone", Value: defaultOr(cfg.Timezone, "Local"), Source: sources["timezone"]},
+	}
+	printResult(result)
+}
+
+func profileSource() string {
+	if globals.profile != "" {
+		return "flag --profile"
+	}
+	if os.Getenv("TISSUE_PROFILE") != "" {
+		return "env TISSUE_PROFILE"
+	}
+	return "default_profile"
+}
+
+// describeSecret は秘密情報そのものではなく、マスクした値か参照先を返す。
+func describeSecret(cfg *Config, kind string) string {
+	plain, ref := cfg.AccessToken, cfg.AccessTokenRef
+	if kind == secretPassword {
+		plain, ref = cfg.Password, cfg.PasswordRef
+	}
+	switch {
+	case cfg.secretSource(kind) != "":
+		return cfg.SecretBackend + ": " + cfg.secretSource(kind)
+	case ref != "":
+		return cfg.SecretBackend + ": " + ref
+	}
+	return maskSecret(plain)
+}
@@ -2,14 +2,13 @@ package main
 
 import (
 	"context"
-	"flag"
 
 	tissue "github.com/mohemohe/go-tissue"
 	"github.com/mohemohe/go-tissue/api"
 )
 
 func cmdSearch(args []string) {
-	fs := flag.NewFlagSet("search", flag.ExitOnError)
+	fs := newFlagSet("search")
 	setUsage(fs, "tissue search <query> [options]")
 	query := fs.String("q", "", "検索キーワード")
 	page := fs.Int("page", 1, "ページ")
@@ -23,18 +22,19 @@ func cmdSearch(args []string) {
 	}
 
 	cli := buildClient()
+	cli.require(tissue.OpSearchCheckins)
 	ctx := context.Background()
 	switch cli.config.AuthMethod {
-	case authMethodToken:
+	case authMethodToken, authMethodHybrid:
 		result, err := cli.api.SearchCheckins(ctx, &api.SearchOption{
 			Query:   *query,
 			Page:    *page,
 			PerPage: *perPage,
 		})
 		if err != nil {
-			die("%v", err)
+			cli.fail(err)
 		}
-		printJSON(result)
+		printResult(result)
 	case authMethodAccount:
 		result, err := cli.scraping.SearchCheckins(ctx, &tissue.SearchCheckinsOption{
 			Query:   *query,
@@ -42,9 +42,9 @@ func cmdSearch(args []string) {
 			PerPage: *perPage,
 		})
 		if err != nil {
-			die("%v", err)
+			cli.fail(err)
 		}
-		printJSON(result)
+		printResult(result)
 	default:
 		die("search is not available for method %s", cli.config.AuthMethod)
 	}
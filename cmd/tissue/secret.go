@@ -0,0 +1,210 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+const (
+	secretBackendPlain   = "plain"
+	secretBackendKeyring = "keyring"
+	secretBackendFile    = "file"
+	secretBackendEnv     = "env"
+	secretBackendCommand = "command"
+)
+
+const (
+	secretAccessToken = "access_token"
+	secretPassword    = "password"
+)
+
+// secretStore は秘密情報の保存先。ref は "<profile>/<種類>" 形式のキー。
+type secretStore interface {
+	Get(ref string) (string, error)
+	Set(ref, value string) error
+	Delete(ref string) error
+}
+
+func newSecretStore(backend string) (secretStore, error) {
+	switch backend {
+	case secretBackendKeyring:
+		return newKeyringStore()
+	case secretBackendFile:
+		return newFileStore()
+	}
+	return nil, fmt.Errorf("secret backend %q does not store secrets", backend)
+}
+
+func secretRef(profile, kind string) string {
+	return profile + "/" + kind
+}
+
+// accessToken はプロファイルの設定に従ってアクセストークンを解決する。
+func (c *Config) accessToken() (string, error) {
+	return c.resolveSecret(c.AccessToken, c.AccessTokenRef, c.AccessTokenCmd, c.AccessTokenEnv)
+}
+
+func (c *Config) password() (string, error) {
+	return c.resolveSecret(c.Password, c.PasswordRef, c.PasswordCmd, c.PasswordEnv)
+}
+
+func (c *Config) secret(kind string) (string, error) {
+	if kind == secretAccessToken {
+		return c.accessToken()
+	}
+	return c.password()
+}
+
+// resolveSecret はコマンド、環境変数、シークレットストア、平文の順に値を探す。
+func (c *Config) resolveSecret(plain, ref, command, env string) (string, error) {
+	switch {
+	case command != "":
+		return runSecretCommand(command)
+	case env != "":
+		v := os.Getenv(env)
+		if v == "" {
+			return "", fmt.Errorf("environment variable %s is empty", env)
+		}
+		return v, nil
+	case ref != "":
+		store, err := newSecretStore(c.SecretBackend)
+		if err != nil {
+			return "", err
+		}
+		return store.Get(ref)
+	}
+	return plain, nil
+}
+
+// runSecretCommand はシェル経由でコマンドを実行し、標準出力の1行目を返す。
+func runSecretCommand(command string) (string, error) {
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.Command("cmd", "/C", command)
+	} else {
+		cmd = exec.Command("sh", "-c", command)
+	}
+	cmd.Stdin = os.Stdin
+	cmd.Stderr = os.Stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("secret command %q: %w", command, err)
+	}
+	line, _, _ := strings.Cut(string(bytes.TrimSpace(out)), "\n")
+	line = strings.TrimSpace(line)
+	if line == "" {
+		return "", fmt.Errorf("secret command %q returned nothing", command)
+	}
+	return line, nil
+}
+
+// storeSecret は value を cfg.SecretBackend に保存し、設定には参照だけを残す。
+func storeSecret(cfg *Config, kind, value string) error {
+	switch cfg.SecretBackend {
+	case "", secretBackendPlain, secretBackendCommand:
+		return nil
+	}
+	store, err := newSecretStore(cfg.SecretBackend)
+	if err != nil {
+		return err
+	}
+	ref := secretRef(cfg.Name, kind)
+	if err := store.Set(ref, value); err != nil {
+		return err
+	}
+	switch kind {
+	case secretAccessToken:
+		cfg.AccessToken, cfg.AccessTokenRef = "", ref
+	case secretPassword:
+		cfg.Password, cfg.PasswordRef = "", ref
+	}
+	return nil
+}
+
+// deleteSecrets はプロファイルが参照しているストア上の秘密情報を削除する。
+func deleteSecrets(cfg *Config) error {
+	return cleanupSecrets(cfg, &Config{})
+}
+
+// cleanupSecrets は old が参照していて cur がもう参照していないストア上の秘密情報を削除する。
+func cleanupSecrets(old, cur *Config) error {
+	var errs []error
+	for _, ref := range []string{old.AccessTokenRef, old.PasswordRef} {
+		if ref == "" {
+			continue
+		}
+		if cur.SecretBackend == old.SecretBackend && (cur.AccessTokenRef == ref || cur.PasswordRef == ref) {
+			continue
+		}
+		store, err := newSecretStore(old.SecretBackend)
+		if err != nil {
+			return err
+		}
+		if err := store.Delete(ref); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (c *Config) hasSecret(kind string) bool {
+	switch kind {
+	case secretAccessToken:
+		return c.AccessToken != "" || c.AccessTokenRef != "" || c.AccessTokenCmd != "" || c.AccessTokenEnv != ""
+	case secretPassword:
+		return c.Password != "" || c.PasswordRef != "" || c.PasswordCmd != "" || c.PasswordEnv != ""
+	}
+	return false
+}
+
+func (c *Config) setPlainSecret(kind, value string) {
+	switch kind {
+	case secretAccessToken:
+		c.AccessToken = value
+	case secretPassword:
+		c.Password = value
+	}
+}
+
+// secretSource は env / command バックエンドでの参照先 (環境変数名またはコマンド) を返す。
+func (c *Config) secretSource(kind string) string {
+	switch {
+	case kind == secretAccessToken && c.SecretBackend == secretBackendEnv:
+		return c.AccessTokenEnv
+	case kind == secretAccessToken && c.SecretBackend == secretBackendCommand:
+		return c.AccessTokenCmd
+	case kind == secretPassword && c.SecretBackend == secretBackendEnv:
+		return c.PasswordEnv
+	case kind == secretPassword && c.SecretBackend == secretBackendCommand:
+		return c.PasswordCmd
+	}
+	return ""
+}
+
+func (c *Config) setSecretSource(kind, source string) {
+	switch {
+	case kind == secretAccessToken && c.SecretBackend == secretBackendEnv:
+		c.AccessTokenEnv = source
+	case kind == secretAccessToken && c.SecretBackend == secretBackendCommand:
+		c.AccessTokenCmd = source
+	case kind == secretPassword && c.SecretBackend == secretBackendEnv:
+		c.PasswordEnv = source
+	case kind == secretPassword && c.SecretBackend == secretBackendCommand:
+		c.PasswordCmd = source
+	}
+}
+
+// clearSecret は kind の秘密情報に関する設定 (平文・参照) をすべて消す。ストア上の値は消さない。
+func clearSecret(c *Config, kind string) {
+	switch kind {
+	case secretAccessToken:
+		c.AccessToken, c.AccessTokenRef, c.AccessTokenCmd, c.AccessTokenEnv = "", "", "", ""
+	case secretPassword:
+		c.Password, c.PasswordRef, c.PasswordCmd, c.PasswordEnv = "", "", "", ""
+	}
+}
@@ -0,0 +1,223 @@
+package main
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"golang.org/x/crypto/pbkdf2"
+	"golang.org/x/term"
+)
+
+const (
+	secretFileVersion = 1
+	pbkdf2Iterations  = 600000
+)
+
+// fileStore はパスフレーズから導出した鍵 (PBKDF2-HMAC-SHA256) で AES-256-GCM 暗号化したファイルに秘密情報を保存する。
+type fileStore struct {
+	path string
+}
+
+// secretFilePassphrase は1回の実行中に何度もパスフレーズを尋ねないためのキャッシュ。
+var secretFilePassphrase []byte
+
+type secretFile struct {
+	Version    int    `json:"version"`
+	Iterations int    `json:"iterations"`
+	Salt       []byte `json:"salt"`
+	Nonce      []byte `json:"nonce"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+func secretFilePath() (string, error) {
+	p, err := configPath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(p), "secrets.enc"), nil
+}
+
+func newFileStore() (*fileStore, error) {
+	p, err := secretFilePath()
+	if err != nil {
+		return nil, err
+	}
+	return &fileStore{path: p}, nil
+}
+
+// secretPassphrase は TISSUE_SECRET_PASSPHRASE、なければ端末からエコーなしでパスフレーズを得る。
+// 標準入力が端末でなければ入力を待たずにエラーにする。confirm なら打ち間違いを防ぐため2回入力させる。
+func (s *fileStore) secretPassphrase(confirm bool) ([]byte, error) {
+	if secretFilePassphrase != nil {
+		return secretFilePassphrase, nil
+	}
+	if env := os.Getenv("TISSUE_SECRET_PASSPHRASE"); env != "" {
+		secretFilePassphrase = []byte(env)
+		return secretFilePassphrase, nil
+	}
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return nil, errors.New("stdin is not a terminal; set TISSUE_SECRET_PASSPHRASE to unlock the secret file")
+	}
+	pass, err := readPassphrase(fd, "Secret file passphrase: ")
+	if err != nil {
+		return nil, err
+	}
+	if confirm {
+		again, err := readPassphrase(fd, "Confirm passphrase: ")
+		if err != nil {
+			return nil, err
+		}
+		if !bytes.Equal(pass, again) {
+			return nil, errors.New("passphrases do not match")
+		}
+	}
+	secretFilePassphrase = pass
+	return secretFilePassphrase, nil
+}
+
+func readPassphrase(fd int, prompt string) ([]byte, error) {
+	fmt.Fprint(os.Stderr, prompt)
+	pass, err := term.ReadPassword(fd)
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return nil, fmt.Errorf("read passphrase: %w", err)
+	}
+	if len(pass) == 0 {
+		return nil, errors.New("passphrase is empty")
+	}
+	return pass, nil
+}
+
+func (s *fileStore) load() (map[string]string, *secretFile, error) {
+	b, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return map[string]string{}, nil, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	f := &secretFile{}
+	if err := json.Unmarshal(b, f); err != nil {
+		return nil, nil, err
+	}
+	if f.Version != secretFileVersion {
+		return nil, nil, fmt.Errorf("unsupported secret file version: %d", f.Version)
+	}
+	pass, err := s.secretPassphrase(false)
+	if err != nil {
+		return nil, nil, err
+	}
+	aead, err := newSecretAEAD(pass, f.Salt, f.Iterations)
+	if err != nil {
+		return nil, nil, err
+	}
+	plain, err := aead.Open(nil, f.Nonce, f.Ciphertext, nil)
+	if err != nil {
+		return nil, nil, errors.New("failed to decrypt secret file (wrong passphrase?)")
+	}
+	secrets := map[string]string{}
+	if err := json.Unmarshal(plain, &secrets); err != nil {
+		return nil, nil, err
+	}
+	return secrets, f, nil
+}
+
+// save は secrets を暗号化して書き込む。prev が nil (ファイルを新しく作る) ならパスフレーズを確認入力させる。
+func (s *fileStore) save(secrets map[string]string, prev *secretFile) error {
+	pass, err := s.secretPassphrase(prev == nil)
+	if err != nil {
+		return err
+	}
+	f := &secretFile{Version: secretFileVersion, Iterations: pbkdf2Iterations}
+	if prev != nil {
+		f.Salt, f.Iterations = prev.Salt, prev.Iterations
+	} else {
+		f.Salt = make([]byte, 16)
+		if _, err := rand.Read(f.Salt); err != nil {
+			return err
+		}
+	}
+	aead, err := newSecretAEAD(pass, f.Salt, f.Iterations)
+	if err != nil {
+		return err
+	}
+	f.Nonce = make([]byte, aead.NonceSize())
+	if _, err := rand.Read(f.Nonce); err != nil {
+		return err
+	}
+	plain, err := json.Marshal(secrets)
+	if err != nil {
+		return err
+	}
+	f.Ciphertext = aead.Seal(nil, f.Nonce, plain, nil)
+	b, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0700); err != nil {
+		return err
+	}
+	return os.WriteFile(s.path, b, 0600)
+}
+
+func (s *fileStore) Get(ref string) (string, error) {
+	secrets, _, err := s.load()
+	if err != nil {
+		return "", err
+	}
+	v, ok := secrets[ref]
+	if !ok {
+		return "", fmt.Errorf("secret %q not found in %s", ref, s.path)
+	}
+	return v, nil
+}
+
+func (s *fileStore) Set(ref, value string) error {
+	secrets, prev, err := s.load()
+	if err != nil {
+		return err
+	}
+	secrets[ref] = value
+	return s.save(secrets, prev)
+}
+
+func (s *fileStore) Delete(ref string) error {
+	secrets, prev, err := s.load()
+	if err != nil {
+		return err
+	}
+	if _, ok := secrets[ref]; !ok {
+		return nil
+	}
+	delete(secrets, ref)
+	return s.save(secrets, prev)
+}
+
+func newSecretAEAD(passphrase, salt []byte, iterations int) (cipher.AEAD, error) {
+	if iterations <= 0 {
+		return nil, errors.New("invalid iteration count")
+	}
+	block, err := aes.NewCipher(pbkdf2.Key(passphrase, salt, iterations, 32, sha256.New))
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// needsPassphraseInput は秘密情報の解決に file バックエンドのパスフレーズを端末 (標準入力) から入力する必要があるかを返す。
+func (c *Config) needsPassphraseInput() bool {
+	if c.SecretBackend != secretBackendFile || secretFilePassphrase != nil || os.Getenv("TISSUE_SECRET_PASSPHRASE") != "" {
+		return false
+	}
+	fromStore := func(ref, command, env string) bool { return ref != "" && command == "" && env == "" }
+	return fromStore(c.AccessTokenRef, c.AccessTokenCmd, c.AccessTokenEnv) || fromStore(c.PasswordRef, c.PasswordCmd, c.PasswordEnv)
+}
@@ -0,0 +1,177 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/godbus/dbus/v5"
+)
+
+// Secret Service API (https://specifications.freedesktop.org/secret-service/) の名前。
+const (
+	secretServiceName  = "org.freedesktop.secrets"
+	secretServicePath  = dbus.ObjectPath("/org/freedesktop/secrets")
+	secretServiceIface = "org.freedesktop.Secret.Service"
+	secretItemIface    = "org.freedesktop.Secret.Item"
+	secretPromptIface  = "org.freedesktop.Secret.Prompt"
+	secretCollIface    = "org.freedesktop.Secret.Collection"
+	// noPrompt は確認の画面が要らないことを表すパス。
+	noPrompt = dbus.ObjectPath("/")
+)
+
+// keyringStore はセッションバスの Secret Service (GNOME Keyring / KWallet 等) に D-Bus で直接保存する。
+// 属性は secret-tool と同じなので、secret-tool で保存したものも読める。
+type keyringStore struct {
+	conn    *dbus.Conn
+	session dbus.ObjectPath
+}
+
+// dbusSecret は Secret Service の Secret 構造体 (oayays)。
+type dbusSecret struct {
+	Session     dbus.ObjectPath
+	Parameters  []byte
+	Value       []byte
+	ContentType string
+}
+
+func newKeyringStore() (secretStore, error) {
+	conn, err := dbus.SessionBus()
+	if err != nil {
+		return nil, fmt.Errorf("keyring backend requires a D-Bus session bus: %w", err)
+	}
+	s := &keyringStore{conn: conn}
+	var output dbus.Variant
+	// plain は平文で受け渡す方式。セッションバスは同じユーザーのプロセスにしか開かれていない。
+	err = s.service().Call(secretServiceIface+".OpenSession", 0, "plain", dbus.MakeVariant("")).Store(&output, &s.session)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open Secret Service session: %w", err)
+	}
+	return s, nil
+}
+
+func (s *keyringStore) service() dbus.BusObject {
+	return s.conn.Object(secretServiceName, secretServicePath)
+}
+
+func (s *keyringStore) object(path dbus.ObjectPath) dbus.BusObject {
+	return s.conn.Object(secretServiceName, path)
+}
+
+func (s *keyringStore) attributes(ref string) map[string]string {
+	return map[string]string{"service": "go-tissue", "account": ref}
+}
+
+// search は ref の項目を探し、ロックされていれば解除して返す。
+func (s *keyringStore) search(ref string) ([]dbus.ObjectPath, error) {
+	var unlocked, locked []dbus.ObjectPath
+	if err := s.service().Call(secretServiceIface+".SearchItems", 0, s.attributes(ref)).Store(&unlocked, &locked); err != nil {
+		return nil, fmt.Errorf("keyring search: %w", err)
+	}
+	if len(locked) > 0 {
+		if err := s.unlock(locked); err != nil {
+			return nil, err
+		}
+		unlocked = append(unlocked, locked...)
+	}
+	return unlocked, nil
+}
+
+func (s *keyringStore) unlock(paths []dbus.ObjectPath) error {
+	var unlocked []dbus.ObjectPath
+	var prompt dbus.ObjectPath
+	if err := s.service().Call(secretServiceIface+".Unlock", 0, paths).Store(&unlocked, &prompt); err != nil {
+		return fmt.Errorf("keyring unlock: %w", err)
+	}
+	_, err := s.prompt(prompt)
+	return err
+}
+
+// prompt は Secret Service が求めた確認 (ロック解除のパスワード入力など) を表示し、完了を待つ。
+func (s *keyringStore) prompt(path dbus.ObjectPath) (dbus.Variant, error) {
+	if path == noPrompt || path == "" {
+		return dbus.Variant{}, nil
+	}
+	match := []dbus.MatchOption{
+		dbus.WithMatchObjectPath(path),
+		dbus.WithMatchInterface(secretPromptIface),
+		dbus.WithMatchMember("Completed"),
+	}
+	if err := s.conn.AddMatchSignal(match...); err != nil {
+		return dbus.Variant{}, err
+	}
+	defer func() { _ = s.conn.RemoveMatchSignal(match...) }()
+	signals := make(chan *dbus.Signal, 1)
+	s.conn.Signal(signals)
+	defer s.conn.RemoveSignal(signals)
+
+	if err := s.object(path).Call(secretPromptIface+".Prompt", 0, "").Err; err != nil {
+		return dbus.Variant{}, fmt.Errorf("keyring prompt: %w", err)
+	}
+	for sig := range signals {
+		if sig.Path != path || sig.Name != secretPromptIface+".Completed" || len(sig.Body) < 2 {
+			continue
+		}
+		if dismissed, _ := sig.Body[0].(bool); dismissed {
+			return dbus.Variant{}, errors.New("keyring prompt was dismissed")
+		}
+		result, _ := sig.Body[1].(dbus.Variant)
+		return result, nil
+	}
+	return dbus.Variant{}, errors.New("keyring prompt: connection closed")
+}
+
+func (s *keyringStore) Get(ref string) (string, error) {
+	items, err := s.search(ref)
+	if err != nil {
+		return "", err
+	}
+	if len(items) == 0 {
+		return "", fmt.Errorf("secret %q not found in keyring", ref)
+	}
+	var secret dbusSecret
+	if err := s.object(items[0]).Call(secretItemIface+".GetSecret", 0, s.session).Store(&secret); err != nil {
+		return "", fmt.Errorf("keyring get: %w", err)
+	}
+	return string(secret.Value), nil
+}
+
+func (s *keyringStore) Set(ref, value string) error {
+	var collection dbus.ObjectPath
+	if err := s.service().Call(secretServiceIface+".ReadAlias", 0, "default").Store(&collection); err != nil {
+		return fmt.Errorf("keyring: %w", err)
+	}
+	if collection == noPrompt {
+		return errors.New("keyring has no default collection")
+	}
+	if err := s.unlock([]dbus.ObjectPath{collection}); err != nil {
+		return err
+	}
+	properties := map[string]dbus.Variant{
+		secretItemIface + ".Label":      dbus.MakeVariant("go-tissue " + ref),
+		secretItemIface + ".Attributes": dbus.MakeVariant(s.attributes(ref)),
+	}
+	secret := dbusSecret{Session: s.session, Value: []byte(value), ContentType: "text/plain; charset=utf8"}
+	var item, prompt dbus.ObjectPath
+	if err := s.object(collection).Call(secretCollIface+".CreateItem", 0, properties, secret, true).Store(&item, &prompt); err != nil {
+		return fmt.Errorf("keyring store: %w", err)
+	}
+	_, err := s.prompt(prompt)
+	return err
+}
+
+func (s *keyringStore) Delete(ref string) error {
+	items, err := s.search(ref)
+	if err != nil {
+		return err
+	}
+	for _, item := range items {
+		var prompt dbus.ObjectPath
+		if err := s.object(item).Call(secretItemIface+".Delete", 0).Store(&prompt); err != nil {
+			return fmt.Errorf("keyring delete: %w", err)
+		}
+		if _, err := s.prompt(prompt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
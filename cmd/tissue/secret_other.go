@@ -0,0 +1,12 @@
+//go:build !linux
+
+package main
+
+import (
+	"errors"
+	"runtime"
+)
+
+func newKeyringStore() (secretStore, error) {
+	return nil, errors.New("keyring backend is not supported on " + runtime.GOOS)
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFileStore(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("TISSUE_SECRET_PASSPHRASE", "correct horse")
+	secretFilePassphrase = nil
+	defer func() { secretFilePassphrase = nil }()
+
+	store, err := newFileStore()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Set("default/access_token", "s3cret-token"); err != nil {
+		t.Fatal(err)
+	}
+	b, err := os.ReadFile(store.path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(b), "s3cret-token") {
+		t.Error("secret stored in plaintext")
+	}
+	if got, err := store.Get("default/access_token"); err != nil || got != "s3cret-token" {
+		t.Errorf("Get = %q, %v", got, err)
+	}
+
+	secretFilePassphrase = []byte("wrong")
+	if _, err := store.Get("default/access_token"); err == nil {
+		t.Error("expected decryption error with wrong passphrase")
+	}
+	secretFilePassphrase = nil
+
+	if err := store.Delete("default/access_token"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Get("default/access_token"); err == nil {
+		t.Error("expected not found after delete")
+	}
+	if filepath.Base(store.path) != "secrets.enc" {
+		t.Errorf("unexpected path: %s", store.path)
+	}
+}
+
+func TestConfig_ResolveSecret(t *testing.T) {
+	t.Setenv("TISSUE_TEST_TOKEN", "from-env")
+	cfg := &Config{SecretBackend: secretBackendEnv, AccessTokenEnv: "TISSUE_TEST_TOKEN"}
+	if got, err := cfg.accessToken(); err != nil || got != "from-env" {
+		t.Errorf("env: %q, %v", got, err)
+	}
+	cfg = &Config{SecretBackend: secretBackendCommand, PasswordCmd: "echo from-cmd; echo ignored"}
+	if got, err := cfg.password(); err != nil || got != "from-cmd" {
+		t.Errorf("command: %q, %v", got, err)
+	}
+	cfg = &Config{AccessToken: "plain"}
+	if got, err := cfg.accessToken(); err != nil || got != "plain" {
+		t.Errorf("plain: %q, %v", got, err)
+	}
+}
+
+func TestConfig_NeedsPassphraseInput(t *testing.T) {
+	t.Setenv("TISSUE_SECRET_PASSPHRASE", "")
+	secretFilePassphrase = nil
+	cfg := &Config{SecretBackend: secretBackendFile, AccessTokenRef: "default/access_token"}
+	if !cfg.needsPassphraseInput() {
+		t.Error("file backend without passphrase should need stdin")
+	}
+	if (&Config{SecretBackend: secretBackendFile, AccessTokenRef: "default/access_token", AccessTokenEnv: "X"}).needsPassphraseInput() {
+		t.Error("env override does not read the secret file")
+	}
+	t.Setenv("TISSUE_SECRET_PASSPHRASE", "correct horse")
+	if cfg.needsPassphraseInput() {
+		t.Error("passphrase from env should not need stdin")
+	}
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	tissue "github.com/mohemohe/go-tissue"
+)
+
+func cmdSiteExport(args []string) {
+	fs := newFlagSet("site-export")
+	setUsage(fs, "tissue site-export [--file checkins.csv] [--since 2024-01 --until 2024-03]")
+	file := fs.String("file", "-", "書き出し先 (- で標準出力)")
+	since := fs.String("since", "", "この日時以降の行だけを書き出す (2024-05-01 / last month / 2024-Q1 など)")
+	until := fs.String("until", "", "この日時以前の行だけを書き出す (期間を指す表現ならその終わりまで)")
+	_ = fs.Parse(args)
+	period := mustParseRange(*since, *until)
+
+	cli := buildClient()
+	cli.require(tissue.OpCSVExport)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+	writeOutput(*file, func(w io.Writer) error {
+		if period.isZero() {
+			return cli.scraping.ExportCheckinsCSV(ctx, w)
+		}
+		pr, pw := io.Pipe()
+		go func() {
+			pw.CloseWithError(cli.scraping.ExportCheckinsCSV(ctx, pw))
+		}()
+		err := filterExportCSV(pr, w, period, location())
+		pr.CloseWithError(err)
+		return err
+	})
+}
+
+// exportTimeLayouts はサイトの CSV の日時の形式。
+var exportTimeLayouts = []string{"2006/01/02 15:04:05", "2006/01/02 15:04"}
+
+// filterExportCSV はサイトの CSV から日時が period の範囲にある行だけを書き出す。
+// CSV の日時にはタイムゾーンが無いので loc で解釈する。
+func filterExportCSV(r io.Reader, w io.Writer, period timeRange, loc *time.Location) error {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	header, err := cr.Read()
+	if err != nil {
+		return err
+	}
+	column := -1
+	for i, name := range header {
+		if strings.TrimPrefix(name, "\ufeff") == "日時" {
+			column = i
+			break
+		}
+	}
+	if column < 0 {
+		return errors.New("export has no 日時 column")
+	}
+	cw := csv.NewWriter(w)
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	for {
+		record, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if column >= len(record) {
+			continue
+		}
+		t, err := parseExportTime(record[column], loc)
+		if err != nil {
+			return err
+		}
+		if !period.contains(t) {
+			continue
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func parseExportTime(s string, loc *time.Location) (time.Time, error) {
+	for _, layout := range exportTimeLayouts {
+		if t, err := time.ParseInLocation(layout, s, loc); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unexpected time in export: %q", s)
+}
+
+func cmdSiteImport(args []string) {
+	fs := newFlagSet("site-import")
+	setUsage(fs, "tissue site-import <checkins.csv|->")
+	pos := parseMixed(fs, args)
+	if len(pos) < 1 {
+		die("usage: tissue site-import <checkins.csv|->")
+	}
+	var r io.Reader = os.Stdin
+	if pos[0] != "-" {
+		f, err := os.Open(pos[0])
+		if err != nil {
+			die("failed to open %s: %v", pos[0], err)
+		}
+		defer f.Close()
+		r = f
+	}
+
+	cli := buildClient()
+	cli.require(tissue.OpCSVImport)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+	result, err := cli.scraping.ImportCheckinsCSV(ctx, r)
+	if err != nil {
+		cli.fail(err)
+	}
+	printResult(result)
+	if len(result.Errors) > 0 {
+		die("import failed with %d error(s)", len(result.Errors))
+	}
+}
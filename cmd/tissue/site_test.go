@@ -0,0 +1,35 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFilterExportCSV(t *testing.T) {
+	jst := time.FixedZone("JST", 9*60*60)
+	in := "\ufeff日時,ノート,オカズリンク,タグ1,タグ2\n" +
+		"2024/04/30 23:59,,,a,\n" +
+		"2024/05/01 00:00,\"x, y\",https://example.com/,a,b\n" +
+		"2024/05/31 23:59:59,,,,\n" +
+		"2024/06/01 00:00,,,,\n"
+	period := timeRange{
+		since: time.Date(2024, 5, 1, 0, 0, 0, 0, jst),
+		until: time.Date(2024, 6, 1, 0, 0, 0, 0, jst).Add(-time.Nanosecond),
+	}
+	out := &bytes.Buffer{}
+	if err := filterExportCSV(strings.NewReader(in), out, period, jst); err != nil {
+		t.Fatal(err)
+	}
+	want := "\ufeff日時,ノート,オカズリンク,タグ1,タグ2\n" +
+		"2024/05/01 00:00,\"x, y\",https://example.com/,a,b\n" +
+		"2024/05/31 23:59:59,,,,\n"
+	if out.String() != want {
+		t.Errorf("got:\n%s\nwant:\n%s", out.String(), want)
+	}
+
+	if err := filterExportCSV(strings.NewReader("日時\n2024-05-01\n"), &bytes.Buffer{}, period, jst); err == nil {
+		t.Error("expected error for unexpected time format")
+	}
+}
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	tissue "github.com/mohemohe/go-tissue"
+	"github.com/mohemohe/go-tissue/api"
+	"github.com/mohemohe/go-tissue/render"
+)
+
+var statsOperations = map[string]tissue.Operation{
+	"daily":  tissue.OpDailyStats,
+	"hourly": tissue.OpHourlyStats,
+	"tags":   tissue.OpTagStats,
+}
+
+func cmdStats(args []string) {
+	fs := newFlagSet("stats")
+	kind := fs.String("kind", "daily", "統計の種類: daily / hourly / tags")
+	user := fs.String("user", "", "対象ユーザー名 (省略時は自分)")
+	since := fs.String("since", "", "集計開始日 (2024-05-01 / last month / 2024-Q1 など)")
+	until := fs.String("until", "", "集計終了日 (期間を指す表現ならその終わりの日)")
+	svgPath := fs.String("svg", "", "SVG の出力先 (- で標準出力)")
+	pngPath := fs.String("png", "", "PNG の出力先 (- で標準出力)")
+	colors := fs.String("colors", "", "塗り色をカンマ区切りで指定 (少ない順, 例: #fdd0e0,#e84a8a)")
+	emptyColor := fs.String("empty-color", "", "0件セルの色")
+	background := fs.String("background", "", "背景色")
+	limit := fs.Int("limit", 10, "tags の描画件数")
+	_ = fs.Parse(args)
+
+	r := mustParseRange(*since, *until)
+	period := api.UserStatsPeriodOption{Since: r.since, Until: r.until}
+
+	palette := render.DefaultPalette
+	if *colors != "" {
+		levels, err := render.ParseHexColors(*colors)
+		if err != nil {
+			die("invalid --colors: %v", err)
+		}
+		palette.Levels = levels
+	}
+	if *emptyColor != "" {
+		c, err := render.ParseHexColor(*emptyColor)
+		if err != nil {
+			die("invalid --empty-color: %v", err)
+		}
+		palette.Empty = c
+	}
+	if *background != "" {
+		c, err := render.ParseHexColor(*background)
+		if err != nil {
+			die("invalid --background: %v", err)
+		}
+		palette.Background = c
+	}
+	chartOption := &render.ChartOption{Palette: &palette, Limit: *limit}
+
+	op, ok := statsOperations[*kind]
+	if !ok {
+		die("unknown --kind: %s (want daily/hourly/tags)", *kind)
+	}
+
+	cli := buildClient()
+	cli.require(op)
+	ctx := context.Background()
+	name := *user
+	if name == "" {
+		name = cli.meName(ctx)
+	}
+
+	switch *kind {
+	case "daily":
+		result := fetchDailyStats(ctx, cli, name, &period)
+		calendarOption := &render.CalendarOption{Since: period.Since, Until: period.Until, Palette: &palette, Location: location()}
+		writeChart(*svgPath, *pngPath, result,
+			func(w io.Writer) error { return render.CalendarSVG(w, result, calendarOption) },
+			func(w io.Writer) error { return render.CalendarPNG(w, result, calendarOption) })
+	case "hourly":
+		result, err := cli.api.UserHourlyCheckinStats(ctx, name, &period)
+		if err != nil {
+			cli.fail(err)
+		}
+		writeChart(*svgPath, *pngPath, result,
+			func(w io.Writer) error { return render.HourlySVG(w, result, chartOption) },
+			func(w io.Writer) error { return render.HourlyPNG(w, result, chartOption) })
+	case "tags":
+		result := fetchTagStats(ctx, cli, name, &period)
+		writeChart(*svgPath, *pngPath, result,
+			func(w io.Writer) error { return render.TagBarsSVG(w, result, chartOption) },
+			func(w io.Writer) error { return render.TagBarsPNG(w, result, chartOption) })
+	}
+}
+
+func fetchDailyStats(ctx context.Context, cli *clientBundle, name string, period *api.UserStatsPeriodOption) []tissue.DailyCheckinCount {
+	switch cli.config.AuthMethod {
+	case authMethodToken, authMethodHybrid:
+		result, err := cli.api.UserDailyCheckinStats(ctx, name, period)
+		if err != nil {
+			cli.fail(err)
+		}
+		return result
+	case authMethodAccount:
+		result, err := cli.scraping.UserDailyCheckinStats(ctx, name, &tissue.UserDailyCheckinStatsOption{
+			Since: period.Since,
+			Until: period.Until,
+		})
+		if err != nil {
+			cli.fail(err)
+		}
+		return result
+	}
+	die("stats is not available for method %s", cli.config.AuthMethod)
+	return nil
+}
+
+func fetchTagStats(ctx context.Context, cli *clientBundle, name string, period *api.UserStatsPeriodOption) []tissue.TagCount {
+	switch cli.config.AuthMethod {
+	case authMethodToken, authMethodHybrid:
+		result, err := cli.api.UserTagStats(ctx, name, period)
+		if err != nil {
+			cli.fail(err)
+		}
+		return result
+	case authMethodAccount:
+		// スクレイピング版のタグ統計は全期間のみ
+		if !period.Since.IsZero() || !period.Until.IsZero() {
+			die("--since/--until are not available for tags with method %s", cli.config.AuthMethod)
+		}
+		result, err := cli.scraping.UserTagStats(ctx, name)
+		if err != nil {
+			cli.fail(err)
+		}
+		return result
+	}
+	die("stats is not available for method %s", cli.config.AuthMethod)
+	return nil
+}
+
+// writeChart は --svg / --png の指定に従って図を書き出す。どちらも無ければ JSON を表示する。
+func writeChart(svgPath, pngPath string, data interface{}, svg, png func(io.Writer) error) {
+	if svgPath == "" && pngPath == "" {
+		printResult(data)
+		return
+	}
+	if svgPath != "" {
+		writeOutput(svgPath, svg)
+	}
+	if pngPath != "" {
+		writeOutput(pngPath, png)
+	}
+}
+
+func writeOutput(path string, write func(io.Writer) error) {
+	if path == "-" {
+		if err := write(os.Stdout); err != nil {
+			die("failed to write: %v", err)
+		}
+		return
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		die("failed to create %s: %v", path, err)
+	}
+	if err := write(f); err != nil {
+		_ = f.Close()
+		die("failed to write %s: %v", path, err)
+	}
+	if err := f.Close(); err != nil {
+		die("failed to write %s: %v", path, err)
+	}
+	fmt.Fprintf(os.Stderr, "saved: %s\n", path)
+}
@@ -2,29 +2,249 @@ package main
 
 import (
 	"context"
-	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	tissue "github.com/mohemohe/go-tissue"
+	"github.com/mohemohe/go-tissue/bulk"
+	"github.com/mohemohe/go-tissue/suggest"
 )
 
+// autoTagMinScore は --auto-tags で採用する候補のスコアの下限。最近使っただけ・よく使うだけのタグは採用しない。
+const autoTagMinScore = 2.0
+
 func cmdTags(args []string) {
-	fs := flag.NewFlagSet("tags", flag.ExitOnError)
+	if len(args) > 0 {
+		switch args[0] {
+		case "suggest":
+			cmdTagsSuggest(args[1:])
+			return
+		case "lint":
+			cmdTagsLint(args[1:])
+			return
+		case "rename":
+			cmdTagsRename(args[1:])
+			return
+		case "merge":
+			cmdTagsMerge(args[1:])
+			return
+		}
+	}
+	fs := newFlagSet("tags")
 	_ = fs.Parse(args)
 
 	cli := buildClient()
+	cli.require(tissue.OpRecentTags)
 	ctx := context.Background()
-	switch cli.config.AuthMethod {
-	case authMethodToken:
-		result, err := cli.api.RecentTags(ctx)
-		if err != nil {
-			die("%v", err)
+	result, err := cli.scraping.RecentTags(ctx)
+	if err != nil {
+		cli.fail(err)
+	}
+	printResult(result)
+}
+
+func cmdTagsSuggest(args []string) {
+	fs := newFlagSet("tags suggest")
+	link := fs.String("link", "", "オカズリンク")
+	note := fs.String("note", "", "ノート")
+	limit := fs.Int("limit", suggest.DefaultLimit, "候補の最大数")
+	_ = fs.Parse(args)
+
+	cli := buildClient()
+	result := cli.suggestTags(context.Background(), *link, *note, *limit)
+	printResult(result)
+}
+
+// tagLintResult はルールに合わない既存のタグと、その使用回数。
+type tagLintResult struct {
+	Tag       string `json:"tag"`
+	Count     int    `json:"count"`
+	Kind      string `json:"kind"`
+	Suggested string `json:"suggested,omitempty"`
+}
+
+func cmdTagsLint(args []string) {
+	fs := newFlagSet("tags lint")
+	user := fs.String("user", "", "対象ユーザー名 (省略時は自分)")
+	_ = fs.Parse(args)
+
+	cli := buildClient()
+	if cli.tagRules == nil {
+		die("no tag rules configured (set --tag-rules, TISSUE_TAG_RULES or tag_rules in the profile)")
+	}
+	cli.require(tissue.OpTagStats)
+	ctx := context.Background()
+	name := *user
+	if name == "" {
+		name = cli.meName(ctx)
+	}
+	results := []tagLintResult{}
+	for _, t := range fetchTagStats(ctx, cli, name, nil) {
+		if issue := cli.tagRules.Check(t.Name); issue != nil {
+			results = append(results, tagLintResult{Tag: t.Name, Count: t.Count, Kind: issue.Kind, Suggested: issue.Suggested})
 		}
-		printJSON(result)
-	case authMethodAccount:
-		result, err := cli.scraping.RecentTags(ctx)
+	}
+	printResult(results)
+	if len(results) > 0 {
+		os.Exit(1)
+	}
+}
+
+func cmdTagsRename(args []string) {
+	fs := newFlagSet("tags rename")
+	setUsage(fs, "tissue tags rename <old> <new>")
+	f := addBulkFlags(fs)
+	skipCollections := fs.Bool("skip-collections", false, "コレクションアイテムのタグは変更しない")
+	pos := parseMixed(fs, args)
+	if len(pos) != 2 {
+		die("usage: tissue tags rename <old> <new>")
+	}
+	rewriteTags("tags rename", pos, bulk.Rename(pos[0], pos[1]), f, *skipCollections)
+}
+
+func cmdTagsMerge(args []string) {
+	fs := newFlagSet("tags merge")
+	setUsage(fs, "tissue tags merge <tag>... --into <tag>")
+	f := addBulkFlags(fs)
+	into := fs.String("into", "", "統合先のタグ")
+	skipCollections := fs.Bool("skip-collections", false, "コレクションアイテムのタグは変更しない")
+	pos := parseMixed(fs, args)
+	if len(pos) < 1 || *into == "" {
+		die("usage: tissue tags merge <tag>... --into <tag>")
+	}
+	rewriteTags("tags merge", append(pos, "--into", *into), bulk.Merge(pos, *into), f, *skipCollections)
+}
+
+// rewriteTags は自分の全チェックイン (と全コレクションアイテム) のタグに m を適用する。
+func rewriteTags(command string, args []string, m bulk.TagMapping, f *bulkFlags, skipCollections bool) {
+	cli := buildClient()
+	ops := []tissue.Operation{tissue.OpCheckinUpdate, tissue.OpUserCheckins}
+	if !skipCollections {
+		ops = append(ops, tissue.OpCollectionItemUpdate, tissue.OpCollectionList, tissue.OpCollectionItemList)
+	}
+	for _, op := range ops {
+		cli.require(op)
+	}
+	ctx := context.Background()
+	name := cli.meName(ctx)
+
+	cli.op = tissue.OpUserCheckins
+	changes, err := bulk.PlanCheckins(ctx, cli.api, name, m)
+	if err != nil {
+		cli.fail(err)
+	}
+	if !skipCollections {
+		cli.op = tissue.OpCollectionItemList
+		items, err := bulk.PlanCollectionItems(ctx, cli.api, name, m)
 		if err != nil {
-			die("%v", err)
+			cli.fail(err)
 		}
-		printJSON(result)
+		changes = append(changes, items...)
+	}
+	cli.op = tissue.OpCheckinUpdate
+	runBulk(ctx, cli, f, command, args, changes)
+}
+
+// suggestTags は自分の履歴から link / note に付けるタグの候補を返す。
+func (b *clientBundle) suggestTags(ctx context.Context, link, note string, limit int) []suggest.Suggestion {
+	b.require(tissue.OpUserCheckins)
+	var src suggest.Source
+	switch b.config.AuthMethod {
+	case authMethodToken, authMethodHybrid:
+		src = suggest.NewAPISource(b.api)
+	case authMethodAccount:
+		src = suggest.NewScrapingSource(b.scraping)
 	default:
-		die("tags is not available for method %s", cli.config.AuthMethod)
+		die("unknown method: %s", b.config.AuthMethod)
+	}
+	s := &suggest.Suggester{Source: src, User: b.meName(ctx), Limit: limit}
+	if link != "" {
+		s.Resolver = linkResolver
+	}
+	result, err := s.SuggestTags(ctx, link, note)
+	if err != nil {
+		b.fail(err)
+	}
+	return result
+}
+
+// autoTags は候補のうちスコアが autoTagMinScore 以上のものを tags に加える。
+func autoTags(tags []string, suggestions []suggest.Suggestion) []string {
+	var picked []string
+	for _, s := range suggestions {
+		if s.Score >= autoTagMinScore {
+			picked = append(picked, s.Tag)
+		}
+	}
+	if len(picked) > 0 {
+		fmt.Fprintf(os.Stderr, "auto tags: %s\n", strings.Join(picked, ", "))
+	}
+	return mergeTags(tags, picked)
+}
+
+// promptTags は候補を標準エラー出力に表示し、標準入力で選ばれたものを tags に加える。
+func promptTags(tags []string, suggestions []suggest.Suggestion) []string {
+	var candidates []suggest.Suggestion
+	for _, s := range suggestions {
+		if !containsTag(tags, s.Tag) {
+			candidates = append(candidates, s)
+		}
+	}
+	if len(candidates) == 0 {
+		fmt.Fprintln(os.Stderr, "no tag suggestions.")
+		return tags
+	}
+	fmt.Fprintln(os.Stderr, "tag suggestions:")
+	for i, s := range candidates {
+		fmt.Fprintf(os.Stderr, "  %d) %s (%.1f: %s)\n", i+1, s.Tag, s.Score, strings.Join(s.Reasons, ", "))
+	}
+	fmt.Fprint(os.Stderr, "追加するタグの番号 (カンマ区切り、a で全部、空で追加しない): ")
+	picked, err := pickSuggestions(candidates, readLine())
+	if err != nil {
+		die("%v", err)
+	}
+	return mergeTags(tags, picked)
+}
+
+// pickSuggestions は "1,3" や "a" のような入力で選ばれた候補のタグを返す。
+func pickSuggestions(candidates []suggest.Suggestion, input string) ([]string, error) {
+	input = strings.TrimSpace(input)
+	if input == "" {
+		return nil, nil
+	}
+	var picked []string
+	if strings.EqualFold(input, "a") || strings.EqualFold(input, "all") {
+		for _, s := range candidates {
+			picked = append(picked, s.Tag)
+		}
+		return picked, nil
+	}
+	for _, f := range strings.FieldsFunc(input, func(r rune) bool { return r == ',' || r == ' ' }) {
+		n, err := strconv.Atoi(f)
+		if err != nil || n < 1 || n > len(candidates) {
+			return nil, fmt.Errorf("invalid selection: %s", f)
+		}
+		picked = append(picked, candidates[n-1].Tag)
+	}
+	return picked, nil
+}
+
+func mergeTags(tags, add []string) []string {
+	for _, t := range add {
+		if !containsTag(tags, t) {
+			tags = append(tags, t)
+		}
+	}
+	return tags
+}
+
+func containsTag(tags []string, tag string) bool {
+	for _, t := range tags {
+		if strings.EqualFold(t, tag) {
+			return true
+		}
 	}
+	return false
 }
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/mohemohe/go-tissue/suggest"
+)
+
+func TestPickSuggestions(t *testing.T) {
+	candidates := []suggest.Suggestion{{Tag: "a"}, {Tag: "b"}, {Tag: "c"}}
+	cases := []struct {
+		input string
+		want  []string
+		err   bool
+	}{
+		{"", nil, false},
+		{"\n", nil, false},
+		{"1,3\n", []string{"a", "c"}, false},
+		{"2 1", []string{"b", "a"}, false},
+		{"a", []string{"a", "b", "c"}, false},
+		{"4", nil, true},
+		{"x", nil, true},
+	}
+	for _, c := range cases {
+		got, err := pickSuggestions(candidates, c.input)
+		if (err != nil) != c.err {
+			t.Fatalf("%q: unexpected error: %v", c.input, err)
+		}
+		if !reflect.DeepEqual(got, c.want) {
+			t.Fatalf("%q: got %v, want %v", c.input, got, c.want)
+		}
+	}
+}
+
+func TestAutoTags(t *testing.T) {
+	suggestions := []suggest.Suggestion{{Tag: "Voice", Score: 5}, {Tag: "new", Score: 2}, {Tag: "recent", Score: 0.5}}
+	got := autoTags([]string{"voice"}, suggestions)
+	if want := []string{"voice", "new"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"time"
+
+	"github.com/mohemohe/go-tissue/timeexpr"
+)
+
+var timeLocation *time.Location
+
+// location は timezone の設定を返す。設定されていなければ time.Local。
+func location() *time.Location {
+	if timeLocation == nil {
+		timeLocation = mustLoadTimezone(mustResolveConfig())
+	}
+	return timeLocation
+}
+
+func mustLoadTimezone(cfg *Config) *time.Location {
+	if cfg.Timezone == "" {
+		return time.Local
+	}
+	loc, err := time.LoadLocation(cfg.Timezone)
+	if err != nil {
+		die("invalid timezone: %v", err)
+	}
+	return loc
+}
+
+// mustParseTime は --at / --since / --until の値を timeexpr の表現として解釈する。
+func mustParseTime(name, s string) timeexpr.Range {
+	r, err := timeexpr.Parse(s, time.Now(), location())
+	if err != nil {
+		die("invalid %s: %v", name, err)
+	}
+	return r
+}
+
+// mustParseInstant は --at のように1つの時刻を取るフラグの値を解釈する。
+// yesterday や 2024-05-01 のような期間を指す表現は、どの時刻か決まらないのでエラーにする。
+func mustParseInstant(name, s string) time.Time {
+	r := mustParseTime(name, s)
+	if !r.Start.Equal(r.End) {
+		die("invalid %s: %q is a period, not a point in time (use e.g. \"yesterday 23:10\", \"2024-05-01 23:10\" or -30m)", name, s)
+	}
+	return r.Start
+}
+
+// timeRange は --since / --until で指定された範囲 (両端を含む)。指定されなかった端はゼロ値。
+type timeRange struct {
+	since, until time.Time
+}
+
+// mustParseRange は --since には期間の始まりを、--until には期間の終わりを使う。
+// --until yesterday ならその日の終わりまでを含む。
+func mustParseRange(since, until string) timeRange {
+	var r timeRange
+	if since != "" {
+		r.since = mustParseTime("--since", since).Start
+	}
+	if until != "" {
+		r.until = mustParseTime("--until", until).End
+	}
+	if !r.since.IsZero() && !r.until.IsZero() && r.since.After(r.until) {
+		die("--since must not be after --until")
+	}
+	return r
+}
+
+func (r timeRange) isZero() bool {
+	return r.since.IsZero() && r.until.IsZero()
+}
+
+func (r timeRange) contains(t time.Time) bool {
+	return (r.since.IsZero() || !t.Before(r.since)) && (r.until.IsZero() || !t.After(r.until))
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	tissue "github.com/mohemohe/go-tissue"
+)
+
+func cmdWebhook(args []string) {
+	if len(args) == 0 {
+		usageWebhook()
+		os.Exit(1)
+	}
+	sub, rest := args[0], args[1:]
+	switch sub {
+	case "list":
+		cmdWebhookList(rest)
+	case "create":
+		cmdWebhookCreate(rest)
+	case "delete":
+		cmdWebhookDelete(rest)
+	case "-h", "--help", "help":
+		usageWebhook()
+	default:
+		die("unknown webhook subcommand: %s", sub)
+	}
+}
+
+func usageWebhook() {
+	fmt.Fprintln(os.Stderr, "usage: tissue webhook <subcommand>")
+	printCommand("  list    Webhook 一覧", tissue.OpWebhookList)
+	printCommand("  create  Webhook を発行 (create <name>)", tissue.OpWebhookCreate)
+	printCommand("  delete  Webhook を削除 (delete <id>)", tissue.OpWebhookDelete)
+	printHiddenNote()
+}
+
+func cmdWebhookList(args []string) {
+	fs := newFlagSet("webhook list")
+	_ = fs.Parse(args)
+
+	cli := buildClient()
+	cli.require(tissue.OpWebhookList)
+	result, err := cli.scraping.ListWebhooks(context.Background())
+	if err != nil {
+		cli.fail(err)
+	}
+	printResult(result)
+}
+
+func cmdWebhookCreate(args []string) {
+	fs := newFlagSet("webhook create")
+	setUsage(fs, "tissue webhook create <name>")
+	pos := parseMixed(fs, args)
+	if len(pos) < 1 {
+		die("usage: tissue webhook create <name>")
+	}
+
+	cli := buildClient()
+	cli.require(tissue.OpWebhookCreate)
+	result, err := cli.scraping.CreateWebhook(context.Background(), pos[0])
+	if err != nil {
+		cli.fail(err)
+	}
+	printResult(result)
+}
+
+func cmdWebhookDelete(args []string) {
+	fs := newFlagSet("webhook delete")
+	setUsage(fs, "tissue webhook delete <id>")
+	pos := parseMixed(fs, args)
+	if len(pos) < 1 {
+		die("usage: tissue webhook delete <id>")
+	}
+
+	cli := buildClient()
+	cli.require(tissue.OpWebhookDelete)
+	if err := cli.scraping.DeleteWebhook(context.Background(), pos[0]); err != nil {
+		cli.fail(err)
+	}
+	fmt.Fprintln(os.Stderr, "deleted.")
+}
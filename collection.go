@@ -41,6 +41,12 @@ func (c *Client) ListCollections(ctx context.Context, option *ListCollectionsOpt
 }
 
 func (c *Client) CreateCollection(ctx context.Context, option *CreateCollectionOption) (*Collection, error) {
+	if option == nil {
+		return nil, errNilOption
+	}
+	if err := c.validate(option); err != nil {
+		return nil, err
+	}
 	result := &Collection{}
 	if err := c.sendJSON(ctx, http.MethodPost, "/api/collections", option, result); err != nil {
 		return nil, err
@@ -52,6 +58,9 @@ func (c *Client) UpdateCollection(ctx context.Context, option *UpdateCollectionO
 	if option == nil {
 		return nil, errNilOption
 	}
+	if err := c.validate(option); err != nil {
+		return nil, err
+	}
 	result := &Collection{}
 	path := "/api/collections/" + strconv.FormatInt(option.ID, 10)
 	if err := c.sendJSON(ctx, http.MethodPut, path, option, result); err != nil {
@@ -50,6 +50,24 @@ func (c *Client) CreateCollectionItem(ctx context.Context, option *CreateCollect
 	if option == nil {
 		return nil, errNilOption
 	}
+	o := *option
+	tags, err := NormalizeTags(c.option.TagNormalizer, o.Tags)
+	if err != nil {
+		return nil, err
+	}
+	o.Tags = tags
+	if o.Link, err = CanonicalizeLink(c.option.LinkCanonicalizer, o.Link); err != nil {
+		return nil, err
+	}
+	if err := c.validate(&o); err != nil {
+		return nil, err
+	}
+	if g := c.option.DuplicateGuard; g != nil {
+		if err := g.CheckCollectionItem(ctx, duplicateSource{c}, o.CollectionID, o.Link); err != nil {
+			return nil, err
+		}
+	}
+	option = &o
 	result := &CollectionItem{}
 	path := "/api/collections/" + strconv.FormatInt(option.CollectionID, 10) + "/items"
 	if err := c.sendJSON(ctx, http.MethodPost, path, option, result); err != nil {
@@ -62,6 +80,16 @@ func (c *Client) UpdateCollectionItem(ctx context.Context, option *UpdateCollect
 	if option == nil {
 		return nil, errNilOption
 	}
+	o := *option
+	tags, err := NormalizeTagsPtr(c.option.TagNormalizer, o.Tags)
+	if err != nil {
+		return nil, err
+	}
+	o.Tags = tags
+	if err := c.validate(&o); err != nil {
+		return nil, err
+	}
+	option = &o
 	result := &CollectionItem{}
 	path := "/api/collections/" + strconv.FormatInt(option.CollectionID, 10) + "/items/" + strconv.FormatInt(option.ItemID, 10)
 	if err := c.sendJSON(ctx, http.MethodPatch, path, option, result); err != nil {
@@ -0,0 +1,128 @@
+package go_tissue
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"html"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CSVImportResult はサイトの CSV インポートの結果。Errors があれば、サイトは1件も取り込んでいない。
+type CSVImportResult struct {
+	Imported int      `json:"imported"`
+	Message  string   `json:"message"`
+	Errors   []string `json:"errors,omitempty"`
+}
+
+// csvImportPollInterval はインポートが処理中のとき、結果を確認し直す間隔。
+var csvImportPollInterval = 3 * time.Second
+
+var (
+	alertPattern    = regexp.MustCompile(`(?s)<div[^>]*class="[^"]*\balert-(success|danger|info)\b[^"]*"[^>]*>(.*?)</div>`)
+	listItemPattern = regexp.MustCompile(`(?s)<li[^>]*>(.*?)</li>`)
+	buttonPattern   = regexp.MustCompile(`(?s)<button\b.*?</button>`)
+	tagPattern      = regexp.MustCompile(`(?s)<[^>]*>`)
+	importedPattern = regexp.MustCompile(`(\d+)\s*件`)
+)
+
+// ExportCheckinsCSV はサイトのデータエクスポート (UTF-8 の CSV) をダウンロードし、w に書き出す。
+func (c *Client) ExportCheckinsCSV(ctx context.Context, w io.Writer) error {
+	res, err := c.doRequest(ctx, http.MethodGet, "/setting/export/csv", url.Values{"charset": {"utf8"}}, nil, "")
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return readErrorResponse(res)
+	}
+	if mediaType, _, _ := mime.ParseMediaType(res.Header.Get("Content-Type")); mediaType == "text/html" {
+		return errors.New("export did not return CSV (the session may have expired or the site changed)")
+	}
+	_, err = io.Copy(w, res.Body)
+	return err
+}
+
+// ImportCheckinsCSV は r の CSV (UTF-8) をサイトのインポート機能でアップロードし、結果を返す。
+// 取り込まれたチェックインの Source は "csv" になる。処理中と表示された場合は結果が出るまで待つ。
+func (c *Client) ImportCheckinsCSV(ctx context.Context, r io.Reader) (*CSVImportResult, error) {
+	body := &bytes.Buffer{}
+	mw := multipart.NewWriter(body)
+	if err := mw.WriteField("encoding", "UTF-8"); err != nil {
+		return nil, err
+	}
+	part, err := mw.CreateFormFile("file", "checkins.csv")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := io.Copy(part, r); err != nil {
+		return nil, err
+	}
+	if err := mw.Close(); err != nil {
+		return nil, err
+	}
+	res, err := c.doRequest(ctx, http.MethodPost, "/setting/import", nil, body, mw.FormDataContentType())
+	if err != nil {
+		return nil, err
+	}
+	page, err := readPage(res)
+	res.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		if result, ok := parseCSVImportResult(page); ok {
+			return result, nil
+		}
+		if !strings.Contains(page, "インポート中") && !strings.Contains(page, "処理中") {
+			return nil, errors.New("import result not found in the settings page")
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(csvImportPollInterval):
+		}
+		if page, err = c.getPage(ctx, "/setting/import"); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// parseCSVImportResult はインポート後のページに表示される成功 / エラーのメッセージを読み取る。
+func parseCSVImportResult(page string) (*CSVImportResult, bool) {
+	result := &CSVImportResult{}
+	found := false
+	for _, m := range alertPattern.FindAllStringSubmatch(page, -1) {
+		switch m[1] {
+		case "success":
+			found = true
+			result.Message = pageText(m[2])
+			if n := importedPattern.FindStringSubmatch(result.Message); n != nil {
+				result.Imported, _ = strconv.Atoi(n[1])
+			}
+		case "danger":
+			found = true
+			items := listItemPattern.FindAllStringSubmatch(m[2], -1)
+			if len(items) == 0 {
+				result.Errors = append(result.Errors, pageText(m[2]))
+			}
+			for _, item := range items {
+				result.Errors = append(result.Errors, pageText(item[1]))
+			}
+		}
+	}
+	return result, found
+}
+
+func pageText(s string) string {
+	s = tagPattern.ReplaceAllString(buttonPattern.ReplaceAllString(s, ""), " ")
+	return strings.Join(strings.Fields(html.UnescapeString(s)), " ")
+}
@@ -0,0 +1,105 @@
+package go_tissue
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeCSV は CSV のエクスポート・インポート画面を模す。
+type fakeCSV struct {
+	mu       sync.Mutex
+	imported []byte
+	polls    int
+}
+
+func serveCSV(f *fakeTissue) *fakeCSV {
+	c := &fakeCSV{}
+	f.handle("/setting/export/csv", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("charset") != "utf8" {
+			http.Error(w, "invalid charset", http.StatusUnprocessableEntity)
+			return
+		}
+		w.Header().Set("Content-Type", "text/csv; charset=UTF-8")
+		fmt.Fprint(w, "日時,ノート\n2020/07/21 19:19,test\n")
+	})
+	f.handle("/setting/import", func(w http.ResponseWriter, r *http.Request) {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		if r.Method == http.MethodPost {
+			file, _, err := r.FormFile("file")
+			if err != nil || r.FormValue("encoding") != "UTF-8" {
+				http.Error(w, "invalid form", http.StatusUnprocessableEntity)
+				return
+			}
+			c.imported, _ = io.ReadAll(file)
+			c.polls = 0
+			http.Redirect(w, r, "/setting/import", http.StatusFound)
+			return
+		}
+		// 最初の1回は処理中として返す
+		c.polls++
+		if c.polls == 1 {
+			fmt.Fprint(w, `<p>インポート中です</p>`)
+			return
+		}
+		if strings.Contains(string(c.imported), "broken") {
+			fmt.Fprint(w, `<div class="alert alert-danger"><ul><li>2 行目 : 日時の形式が不正です</li><li>3 行目 : &quot;link&quot; が長すぎます</li></ul></div>`)
+			return
+		}
+		fmt.Fprint(w, `<div class="alert alert-success alert-dismissible">1件のインポートに成功しました。<button type="button" class="close"><span>&times;</span></button></div>`)
+	})
+	return c
+}
+
+func TestClient_ExportCheckinsCSV(t *testing.T) {
+	f := newFakeTissue(t)
+	serveCSV(f)
+	client := newFakeClient(t, f, "secret")
+
+	buf := &bytes.Buffer{}
+	if err := client.ExportCheckinsCSV(context.Background(), buf); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(buf.String(), "日時,ノート\n") {
+		t.Errorf("unexpected csv: %q", buf.String())
+	}
+}
+
+func TestClient_ImportCheckinsCSV(t *testing.T) {
+	saved := csvImportPollInterval
+	csvImportPollInterval = 10 * time.Millisecond
+	t.Cleanup(func() { csvImportPollInterval = saved })
+
+	f := newFakeTissue(t)
+	site := serveCSV(f)
+	client := newFakeClient(t, f, "secret")
+	ctx := context.Background()
+
+	result, err := client.ImportCheckinsCSV(ctx, strings.NewReader("日時\n2020/07/21 19:19\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Imported != 1 || result.Message != "1件のインポートに成功しました。" || len(result.Errors) != 0 {
+		t.Errorf("unexpected result: %+v", result)
+	}
+	if string(site.imported) != "日時\n2020/07/21 19:19\n" {
+		t.Errorf("unexpected upload: %q", site.imported)
+	}
+
+	result, err = client.ImportCheckinsCSV(ctx, strings.NewReader("broken"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"2 行目 : 日時の形式が不正です", `3 行目 : "link" が長すぎます`}
+	if result.Imported != 0 || !reflect.DeepEqual(result.Errors, want) {
+		t.Errorf("unexpected result: %+v", result)
+	}
+}
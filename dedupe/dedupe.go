@@ -0,0 +1,190 @@
+// Package dedupe は重複したチェックインとコレクションアイテムを見つける。
+//
+// Webhook の再送や二度押しで数秒違いに同じチェックインが作られたものと、同じコレクションに同じリンクのアイテムが
+// 複数入ったものを Detector で探す。Guard は tissue.DuplicateGuard を実装しているので、各クライアントの
+// ClientOption.DuplicateGuard に指定すると作成の前に重複を防げる。
+package dedupe
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	tissue "github.com/mohemohe/go-tissue"
+	"github.com/mohemohe/go-tissue/bulk"
+)
+
+// DefaultWindow は同じ内容のチェックインを重複とみなす間隔の既定値。
+const DefaultWindow = time.Minute
+
+// Keep は重複のうちどれを残すか。
+type Keep string
+
+const (
+	KeepOldest Keep = "oldest"
+	KeepNewest Keep = "newest"
+)
+
+// ParseKeep は oldest / newest を Keep にする。
+func ParseKeep(s string) (Keep, error) {
+	switch k := Keep(s); k {
+	case KeepOldest, KeepNewest:
+		return k, nil
+	}
+	return "", fmt.Errorf("invalid keep %q (oldest or newest)", s)
+}
+
+// Detector は重複の判定の条件。
+type Detector struct {
+	// Window は同じリンク・タグのチェックインを重複とみなす間隔。0 なら DefaultWindow。
+	Window time.Duration
+	// Canonicalizer はリンクの比較に使う。nil なら前後の空白だけを除いて比較する。
+	Canonicalizer tissue.LinkCanonicalizer
+}
+
+// CheckinGroup は互いに重複するチェックイン。Checkins は古い順。
+type CheckinGroup struct {
+	Link     string           `json:"link"`
+	Tags     []string         `json:"tags"`
+	Checkins []tissue.Checkin `json:"checkins"`
+}
+
+// Split は keep で残す1件と、それ以外を返す。
+func (g CheckinGroup) Split(keep Keep) (tissue.Checkin, []tissue.Checkin) {
+	return split(g.Checkins, keep)
+}
+
+// ItemGroup は同じコレクションにある、同じリンクのアイテム。Items は ID の昇順 (追加された順)。
+type ItemGroup struct {
+	CollectionID int64                   `json:"collection_id"`
+	Link         string                  `json:"link"`
+	Items        []tissue.CollectionItem `json:"items"`
+}
+
+// Split は keep で残す1件と、それ以外を返す。
+func (g ItemGroup) Split(keep Keep) (tissue.CollectionItem, []tissue.CollectionItem) {
+	return split(g.Items, keep)
+}
+
+func split[T any](list []T, keep Keep) (T, []T) {
+	if keep == KeepNewest {
+		return list[len(list)-1], append([]T(nil), list[:len(list)-1]...)
+	}
+	return list[0], append([]T(nil), list[1:]...)
+}
+
+func (d *Detector) window() time.Duration {
+	if d.Window <= 0 {
+		return DefaultWindow
+	}
+	return d.Window
+}
+
+// link は比較に使うリンクを返す。揃えられないリンクはそのまま使う。
+func (d *Detector) link(link string) string {
+	link = strings.TrimSpace(link)
+	if d.Canonicalizer == nil || link == "" {
+		return link
+	}
+	if canon, err := d.Canonicalizer.CanonicalizeLink(link); err == nil {
+		return canon
+	}
+	return link
+}
+
+// checkinKey はリンクとタグ (順序・大文字小文字を区別しない) から比較用のキーを作る。
+func (d *Detector) checkinKey(link string, tags []string) string {
+	folded := make([]string, 0, len(tags))
+	for _, t := range tags {
+		folded = append(folded, strings.ToLower(strings.TrimSpace(t)))
+	}
+	sort.Strings(folded)
+	return d.link(link) + "\x00" + strings.Join(folded, "\x00")
+}
+
+// CheckinGroups は checkins から重複の組を探す。同じリンク・タグで、直前のものから Window 以内のものを同じ組にする。
+func (d *Detector) CheckinGroups(checkins []tissue.Checkin) []CheckinGroup {
+	sorted := append([]tissue.Checkin(nil), checkins...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		a, b := sorted[i], sorted[j]
+		if !a.CheckedInAt.Equal(b.CheckedInAt.Time) {
+			return a.CheckedInAt.Before(b.CheckedInAt.Time)
+		}
+		return a.ID < b.ID
+	})
+	var groups []*CheckinGroup
+	current := map[string]*CheckinGroup{}
+	for _, c := range sorted {
+		key := d.checkinKey(c.Link, c.Tags)
+		if g, ok := current[key]; ok {
+			last := g.Checkins[len(g.Checkins)-1]
+			if c.CheckedInAt.Sub(last.CheckedInAt.Time) <= d.window() {
+				g.Checkins = append(g.Checkins, c)
+				continue
+			}
+		}
+		g := &CheckinGroup{Link: c.Link, Tags: c.Tags, Checkins: []tissue.Checkin{c}}
+		current[key] = g
+		groups = append(groups, g)
+	}
+	result := []CheckinGroup{}
+	for _, g := range groups {
+		if len(g.Checkins) > 1 {
+			result = append(result, *g)
+		}
+	}
+	return result
+}
+
+// ItemGroups は1つのコレクションのアイテムから、リンクが同じものの組を探す。
+func (d *Detector) ItemGroups(collectionID int64, items []tissue.CollectionItem) []ItemGroup {
+	sorted := append([]tissue.CollectionItem(nil), items...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].ID < sorted[j].ID })
+	var keys []string
+	groups := map[string]*ItemGroup{}
+	for _, it := range sorted {
+		key := d.link(it.Link)
+		g, ok := groups[key]
+		if !ok {
+			g = &ItemGroup{CollectionID: collectionID, Link: key}
+			groups[key] = g
+			keys = append(keys, key)
+		}
+		g.Items = append(g.Items, it)
+	}
+	result := []ItemGroup{}
+	for _, key := range keys {
+		if g := groups[key]; len(g.Items) > 1 {
+			result = append(result, *g)
+		}
+	}
+	return result
+}
+
+// ScanCheckins は user の全チェックイン (ClientOption.Filter で隠れるものも含む) を読み、重複の組を返す。
+func (d *Detector) ScanCheckins(ctx context.Context, c bulk.Reader, user string) ([]CheckinGroup, error) {
+	checkins, err := bulk.AllCheckins(ctx, c, user, nil)
+	if err != nil {
+		return nil, err
+	}
+	return d.CheckinGroups(checkins), nil
+}
+
+// ScanCollections は user の全コレクションのアイテムを読み、重複の組を返す。
+func (d *Detector) ScanCollections(ctx context.Context, c bulk.Reader, user string) ([]ItemGroup, error) {
+	collections, err := bulk.AllCollections(ctx, c, user)
+	if err != nil {
+		return nil, err
+	}
+	result := []ItemGroup{}
+	for _, col := range collections {
+		items, err := bulk.AllCollectionItems(ctx, c, col.ID)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, d.ItemGroups(col.ID, items)...)
+	}
+	return result, nil
+}
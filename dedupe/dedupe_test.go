@@ -0,0 +1,119 @@
+package dedupe
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	tissue "github.com/mohemohe/go-tissue"
+)
+
+func at(sec int) tissue.Timestamp {
+	return tissue.Timestamp{Time: time.Date(2024, 1, 1, 0, 0, sec, 0, time.UTC)}
+}
+
+func ids(checkins []tissue.Checkin) []int64 {
+	var result []int64
+	for _, c := range checkins {
+		result = append(result, c.ID)
+	}
+	return result
+}
+
+type trimQuery struct{}
+
+func (trimQuery) CanonicalizeLink(link string) (string, error) {
+	if i := strings.Index(link, "?"); i >= 0 {
+		return link[:i], nil
+	}
+	return link, nil
+}
+
+func TestDetector_CheckinGroups(t *testing.T) {
+	d := &Detector{Window: 10 * time.Second, Canonicalizer: trimQuery{}}
+	groups := d.CheckinGroups([]tissue.Checkin{
+		{ID: 3, CheckedInAt: at(8), Link: "https://example.com/a?utm_source=x", Tags: []string{"b", "A"}},
+		{ID: 1, CheckedInAt: at(0), Link: "https://example.com/a", Tags: []string{"a", "b"}},
+		{ID: 2, CheckedInAt: at(5), Link: "https://example.com/a", Tags: []string{"a"}},
+		{ID: 4, CheckedInAt: at(17), Link: "https://example.com/a", Tags: []string{"a", "b"}},
+		{ID: 5, CheckedInAt: at(40), Link: "https://example.com/a", Tags: []string{"a", "b"}},
+		{ID: 6, CheckedInAt: at(41)},
+		{ID: 7, CheckedInAt: at(42)},
+	})
+	if len(groups) != 2 {
+		t.Fatalf("unexpected groups: %+v", groups)
+	}
+	if got := ids(groups[0].Checkins); len(got) != 3 || got[0] != 1 || got[1] != 3 || got[2] != 4 {
+		t.Errorf("unexpected first group: %v", got)
+	}
+	if got := ids(groups[1].Checkins); len(got) != 2 || got[0] != 6 {
+		t.Errorf("unexpected second group: %v", got)
+	}
+	kept, dups := groups[0].Split(KeepNewest)
+	if kept.ID != 4 || len(dups) != 2 || dups[0].ID != 1 {
+		t.Errorf("unexpected split: %d %v", kept.ID, ids(dups))
+	}
+	if len(groups[0].Checkins) != 3 {
+		t.Error("Split must not modify the group")
+	}
+}
+
+func TestDetector_ItemGroups(t *testing.T) {
+	d := &Detector{Canonicalizer: trimQuery{}}
+	groups := d.ItemGroups(47, []tissue.CollectionItem{
+		{ID: 3, Link: "https://example.com/a?ref=x"},
+		{ID: 1, Link: "https://example.com/a"},
+		{ID: 2, Link: "https://example.com/b"},
+	})
+	if len(groups) != 1 || groups[0].Link != "https://example.com/a" || groups[0].Items[0].ID != 1 {
+		t.Fatalf("unexpected groups: %+v", groups)
+	}
+	if kept, dups := groups[0].Split(KeepOldest); kept.ID != 1 || len(dups) != 1 || dups[0].ID != 3 {
+		t.Errorf("unexpected split: %+v %+v", kept, dups)
+	}
+}
+
+type fakeSource struct {
+	checkins []tissue.Checkin
+	items    map[int64][]tissue.CollectionItem
+}
+
+func (s fakeSource) RecentCheckins(ctx context.Context) ([]tissue.Checkin, error) {
+	return s.checkins, nil
+}
+
+func (s fakeSource) CollectionItems(ctx context.Context, collectionID int64) ([]tissue.CollectionItem, error) {
+	return s.items[collectionID], nil
+}
+
+func TestGuard(t *testing.T) {
+	now := time.Now()
+	src := fakeSource{
+		checkins: []tissue.Checkin{
+			{ID: 10, CheckedInAt: tissue.Timestamp{Time: now.Add(-20 * time.Second)}, Link: "https://example.com/", Tags: []string{"a"}},
+		},
+		items: map[int64][]tissue.CollectionItem{47: {{ID: 5, Link: "https://example.com/x"}}},
+	}
+	g := &Guard{}
+	ctx := context.Background()
+	var dup *tissue.DuplicateError
+	err := g.CheckCheckin(ctx, src, tissue.DuplicateCandidate{CheckedInAt: now, Link: "https://example.com/", Tags: []string{"A"}})
+	if !errors.As(err, &dup) || dup.ID != 10 {
+		t.Fatalf("expected duplicate error, got %v", err)
+	}
+	if err := g.CheckCheckin(ctx, src, tissue.DuplicateCandidate{CheckedInAt: now, Link: "https://example.com/", Tags: []string{"b"}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.CheckCheckin(ctx, src, tissue.DuplicateCandidate{CheckedInAt: now.Add(time.Hour), Link: "https://example.com/", Tags: []string{"a"}}); err != nil {
+		t.Fatal(err)
+	}
+	err = g.CheckCollectionItem(ctx, src, 47, "https://example.com/x")
+	if !errors.As(err, &dup) || dup.ID != 5 || dup.CollectionID != 47 {
+		t.Fatalf("expected duplicate error, got %v", err)
+	}
+	if err := g.CheckCollectionItem(ctx, src, 48, "https://example.com/x"); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,46 @@
+package dedupe
+
+import (
+	"context"
+
+	tissue "github.com/mohemohe/go-tissue"
+)
+
+// Guard は作成の前に重複を調べる tissue.DuplicateGuard。
+// チェックインは最新の1ページ分と比べ、同じリンク・タグで Window 以内のものがあれば *tissue.DuplicateError を返す。
+// コレクションアイテムは同じコレクションに同じリンクのものがあればエラーにする。
+type Guard struct {
+	Detector
+}
+
+func (g *Guard) CheckCheckin(ctx context.Context, src tissue.DuplicateSource, c tissue.DuplicateCandidate) error {
+	recent, err := src.RecentCheckins(ctx)
+	if err != nil {
+		return err
+	}
+	key := g.checkinKey(c.Link, c.Tags)
+	for _, r := range recent {
+		d := r.CheckedInAt.Sub(c.CheckedInAt)
+		if d < 0 {
+			d = -d
+		}
+		if d <= g.window() && g.checkinKey(r.Link, r.Tags) == key {
+			return &tissue.DuplicateError{Target: "checkin", ID: r.ID, Link: r.Link}
+		}
+	}
+	return nil
+}
+
+func (g *Guard) CheckCollectionItem(ctx context.Context, src tissue.DuplicateSource, collectionID int64, link string) error {
+	items, err := src.CollectionItems(ctx, collectionID)
+	if err != nil {
+		return err
+	}
+	key := g.link(link)
+	for _, it := range items {
+		if g.link(it.Link) == key {
+			return &tissue.DuplicateError{Target: "collection_item", ID: it.ID, CollectionID: collectionID, Link: it.Link}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package go_tissue
+
+import (
+	"context"
+	"strconv"
+	"time"
+)
+
+// DuplicateGuard は作成の前に重複を調べる。ClientOption.DuplicateGuard に指定すると、
+// チェックインとコレクションアイテムの作成の前に呼ばれ、エラーを返すと作成しない。実装は dedupe パッケージにある。
+// 呼ばれるのはタグの正規化・リンクの正規化の後。
+type DuplicateGuard interface {
+	CheckCheckin(ctx context.Context, src DuplicateSource, c DuplicateCandidate) error
+	CheckCollectionItem(ctx context.Context, src DuplicateSource, collectionID int64, link string) error
+}
+
+// DuplicateCandidate はこれから作成するチェックイン。
+type DuplicateCandidate struct {
+	CheckedInAt time.Time
+	Link        string
+	Tags        []string
+}
+
+// DuplicateSource は重複を調べるために、クライアントが DuplicateGuard に渡す既存のデータ。
+type DuplicateSource interface {
+	// RecentCheckins は自分の最新のチェックインを新しい順に1ページ分返す。
+	RecentCheckins(ctx context.Context) ([]Checkin, error)
+	// CollectionItems はコレクションのアイテムをすべて返す。
+	CollectionItems(ctx context.Context, collectionID int64) ([]CollectionItem, error)
+}
+
+// DuplicateError は DuplicateGuard が重複を見つけたときのエラー。
+type DuplicateError struct {
+	// Target は "checkin" か "collection_item"。
+	Target       string
+	ID           int64
+	CollectionID int64
+	Link         string
+}
+
+func (e *DuplicateError) Error() string {
+	if e.Target == "collection_item" {
+		return "duplicate of collection item " + strconv.FormatInt(e.CollectionID, 10) + "/" + strconv.FormatInt(e.ID, 10) + ": " + e.Link
+	}
+	return "duplicate of " + e.Target + " " + strconv.FormatInt(e.ID, 10) + ": " + e.Link
+}
+
+// duplicateSource は Client の DuplicateSource。
+type duplicateSource struct {
+	c *Client
+}
+
+func (s duplicateSource) RecentCheckins(ctx context.Context) ([]Checkin, error) {
+	me, err := s.c.Me(ctx)
+	if err != nil {
+		return nil, err
+	}
+	checkins, err := s.c.UserCheckins(ctx, me.Name, &UserCheckinsOption{Page: 1, Unfiltered: true})
+	if err != nil {
+		return nil, err
+	}
+	result := make([]Checkin, len(checkins))
+	for i, c := range checkins {
+		result[i] = c.Checkin
+	}
+	return result, nil
+}
+
+func (s duplicateSource) CollectionItems(ctx context.Context, collectionID int64) ([]CollectionItem, error) {
+	var result []CollectionItem
+	for page := 1; ; page++ {
+		items, err := s.c.ListCollectionItems(ctx, &ListCollectionItemsOption{CollectionID: collectionID, Page: page})
+		if err != nil {
+			return nil, err
+		}
+		if len(items) == 0 {
+			return result, nil
+		}
+		result = append(result, items...)
+	}
+}
@@ -0,0 +1,111 @@
+// Package filter はチェックイン一覧からミュートしたものを取り除くルールを提供する。
+//
+// Rules は tissue.CheckinFilter を実装しているので、各クライアントの ClientOption.Filter に指定すると
+// 一覧系のメソッドの結果に自動で適用される。
+package filter
+
+import (
+	"context"
+	"net/url"
+	"strings"
+
+	tissue "github.com/mohemohe/go-tissue"
+)
+
+type Rules struct {
+	// MutedTags はいずれかを含むチェックインを取り除くタグ。大文字小文字は区別しない。
+	MutedTags []string `json:"muted_tags,omitempty"`
+	// MutedUsers はチェックインを取り除くユーザー名。
+	MutedUsers []string `json:"muted_users,omitempty"`
+	// MutedDomains はリンク先のホストがこのドメイン (またはそのサブドメイン) であるチェックインを取り除く。
+	MutedDomains []string `json:"muted_domains,omitempty"`
+	// HideTooSensitive は過激フラグの付いたチェックインを取り除く。
+	HideTooSensitive bool `json:"hide_too_sensitive,omitempty"`
+	// HidePrivate は非公開のチェックインを取り除く。自分の一覧を公開先へ転載する場合などに使う。
+	HidePrivate bool `json:"hide_private,omitempty"`
+	// HideSiteMuted はサイトの設定でミュートされている (IsMuted) チェックインを取り除く。
+	HideSiteMuted bool `json:"hide_site_muted,omitempty"`
+}
+
+// AllowCheckin は c をルールに照らし、残すべきなら true を返す。
+func (r *Rules) AllowCheckin(c tissue.Checkin) bool {
+	if r == nil {
+		return true
+	}
+	if (r.HideTooSensitive && c.IsTooSensitive) || (r.HidePrivate && c.IsPrivate) || (r.HideSiteMuted && c.IsMuted != 0) {
+		return false
+	}
+	for _, name := range r.MutedUsers {
+		if c.User.Name == name {
+			return false
+		}
+	}
+	for _, tag := range c.Tags {
+		if containsFold(r.MutedTags, tag) {
+			return false
+		}
+	}
+	if c.Link != "" && len(r.MutedDomains) > 0 {
+		if u, err := url.Parse(c.Link); err == nil && matchDomain(r.MutedDomains, u.Hostname()) {
+			return false
+		}
+	}
+	return true
+}
+
+// Apply は checkins のうちルールに合うものだけを返す。
+func (r *Rules) Apply(checkins []tissue.Checkin) []tissue.Checkin {
+	return tissue.FilterCheckins(r, checkins)
+}
+
+// TagMuteStore はサイトのタグのミュート設定を読み書きできるクライアント (*tissue.Client, *api.HybridClient)。
+type TagMuteStore interface {
+	ListTagMutes(ctx context.Context) ([]tissue.TagMute, error)
+	MuteTag(ctx context.Context, tag string) (*tissue.TagMute, error)
+}
+
+// SyncTagMutes はサイトのタグのミュート設定と MutedTags を相互に反映し、両者を同じ集合にする。
+// どちらからも削除はしない。
+func (r *Rules) SyncTagMutes(ctx context.Context, store TagMuteStore) error {
+	mutes, err := store.ListTagMutes(ctx)
+	if err != nil {
+		return err
+	}
+	site := make([]string, len(mutes))
+	for i, m := range mutes {
+		site[i] = m.Tag
+	}
+	for _, tag := range r.MutedTags {
+		if !containsFold(site, tag) {
+			if _, err := store.MuteTag(ctx, tag); err != nil {
+				return err
+			}
+		}
+	}
+	for _, tag := range site {
+		if !containsFold(r.MutedTags, tag) {
+			r.MutedTags = append(r.MutedTags, tag)
+		}
+	}
+	return nil
+}
+
+func containsFold(list []string, s string) bool {
+	for _, v := range list {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchDomain(domains []string, host string) bool {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	for _, d := range domains {
+		d = strings.ToLower(strings.Trim(d, "."))
+		if d != "" && (host == d || strings.HasSuffix(host, "."+d)) {
+			return true
+		}
+	}
+	return false
+}
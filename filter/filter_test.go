@@ -0,0 +1,102 @@
+package filter
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"testing"
+
+	tissue "github.com/mohemohe/go-tissue"
+	"github.com/mohemohe/go-tissue/api"
+)
+
+func TestRules_AllowCheckin(t *testing.T) {
+	rules := &Rules{
+		MutedTags:        []string{"NTR"},
+		MutedUsers:       []string{"spammer"},
+		MutedDomains:     []string{"example.com"},
+		HideTooSensitive: true,
+		HideSiteMuted:    true,
+	}
+	cases := []struct {
+		name    string
+		checkin tissue.Checkin
+		allow   bool
+	}{
+		{"plain", tissue.Checkin{Tags: []string{"a"}, Link: "https://example.org/"}, true},
+		{"tag", tissue.Checkin{Tags: []string{"a", "ntr"}}, false},
+		{"user", tissue.Checkin{User: tissue.User{Name: "spammer"}}, false},
+		{"domain", tissue.Checkin{Link: "https://example.com/x"}, false},
+		{"subdomain", tissue.Checkin{Link: "https://www.Example.com/x"}, false},
+		{"similar domain", tissue.Checkin{Link: "https://notexample.com/x"}, true},
+		{"too sensitive", tissue.Checkin{IsTooSensitive: true}, false},
+		{"private", tissue.Checkin{IsPrivate: true}, true},
+		{"site muted", tissue.Checkin{IsMuted: 1}, false},
+	}
+	for _, c := range cases {
+		if got := rules.AllowCheckin(c.checkin); got != c.allow {
+			t.Errorf("%s: got %v, want %v", c.name, got, c.allow)
+		}
+	}
+	if !(*Rules)(nil).AllowCheckin(tissue.Checkin{IsTooSensitive: true}) {
+		t.Error("nil rules must allow everything")
+	}
+}
+
+func TestRules_AttachedToClient(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		checkins := make([]tissue.Checkin, 4)
+		for i := range checkins {
+			checkins[i] = tissue.Checkin{ID: int64(i + 1), Tags: []string{"tag" + strconv.Itoa(i%2)}}
+		}
+		_ = json.NewEncoder(w).Encode(checkins)
+	}))
+	defer server.Close()
+
+	client, err := api.NewClient(&api.ClientOption{
+		BaseURL:     server.URL,
+		AccessToken: "token",
+		Filter:      &Rules{MutedTags: []string{"tag1"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkins, err := client.UserLikes(context.Background(), "alice", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(checkins) != 2 || checkins[0].ID != 1 || checkins[1].ID != 3 {
+		t.Errorf("unexpected checkins: %+v", checkins)
+	}
+}
+
+type fakeStore struct {
+	mutes []tissue.TagMute
+}
+
+func (s *fakeStore) ListTagMutes(ctx context.Context) ([]tissue.TagMute, error) {
+	return s.mutes, nil
+}
+
+func (s *fakeStore) MuteTag(ctx context.Context, tag string) (*tissue.TagMute, error) {
+	m := tissue.TagMute{ID: strconv.Itoa(len(s.mutes) + 1), Tag: tag}
+	s.mutes = append(s.mutes, m)
+	return &m, nil
+}
+
+func TestRules_SyncTagMutes(t *testing.T) {
+	store := &fakeStore{mutes: []tissue.TagMute{{ID: "1", Tag: "site"}, {ID: "2", Tag: "Both"}}}
+	rules := &Rules{MutedTags: []string{"local", "both"}}
+	if err := rules.SyncTagMutes(context.Background(), store); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"local", "both", "site"}; !reflect.DeepEqual(rules.MutedTags, want) {
+		t.Errorf("unexpected rules: %v", rules.MutedTags)
+	}
+	if len(store.mutes) != 3 || store.mutes[2].Tag != "local" {
+		t.Errorf("unexpected site mutes: %+v", store.mutes)
+	}
+}
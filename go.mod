@@ -2,4 +2,12 @@ module github.com/mohemohe/go-tissue
 
 go 1.21
 
-require github.com/joho/godotenv v1.5.1
+require (
+	github.com/godbus/dbus/v5 v5.1.0
+	github.com/joho/godotenv v1.5.1
+	golang.org/x/crypto v0.17.0
+	golang.org/x/term v0.15.0
+	golang.org/x/text v0.14.0
+)
+
+require golang.org/x/sys v0.15.0 // indirect
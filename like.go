@@ -0,0 +1,109 @@
+package go_tissue
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"html"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+type likeResponse struct {
+	Ejaculation *Checkin `json:"ejaculation"`
+}
+
+// Like はチェックインにいいねし、更新後のチェックインを返す。いいね済みの場合もエラーにしない。
+func (c *Client) Like(ctx context.Context, checkinID int64) (*Checkin, error) {
+	b, err := json.Marshal(map[string]int64{"id": checkinID})
+	if err != nil {
+		return nil, err
+	}
+	return c.like(ctx, http.MethodPost, "/api/likes", bytes.NewReader(b), checkinID, http.StatusConflict)
+}
+
+// Unlike はチェックインのいいねを取り消し、更新後のチェックインを返す。いいねしていない場合もエラーにしない。
+func (c *Client) Unlike(ctx context.Context, checkinID int64) (*Checkin, error) {
+	return c.like(ctx, http.MethodDelete, "/api/likes/"+strconv.FormatInt(checkinID, 10), nil, checkinID, http.StatusNotFound)
+}
+
+// like は /api/likes への要求を送る。状態が既にそうなっている場合サイトは alreadyStatus を返すが、
+// チェックインが存在すれば本文に含まれるので、その場合は成功として扱う。
+func (c *Client) like(ctx context.Context, method, spath string, body io.Reader, checkinID int64, alreadyStatus int) (*Checkin, error) {
+	res, err := c.doRequest(ctx, method, spath, nil, body, "application/json")
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	ok := res.StatusCode >= 200 && res.StatusCode < 300
+	if !ok && res.StatusCode != alreadyStatus {
+		return nil, readErrorResponse(res)
+	}
+	b, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+	result := &likeResponse{}
+	if err := json.Unmarshal(b, result); err != nil || result.Ejaculation == nil || result.Ejaculation.ID == 0 {
+		if !ok {
+			return nil, &StatusError{StatusCode: res.StatusCode, Status: res.Status, Body: strings.TrimSpace(string(b))}
+		}
+		if err != nil {
+			return nil, err
+		}
+		return &Checkin{ID: checkinID}, nil
+	}
+	return result.Ejaculation, nil
+}
+
+var (
+	likeUsersPattern = regexp.MustCompile(`(?s)<div class="[^"]*\blike-users\b[^"]*">(.*?)</div>`)
+	likeUserPattern  = regexp.MustCompile(`(?s)<a href="[^"]*/user/([^"/?#]+)"[^>]*>(.*?)</a>`)
+	imgTitlePattern  = regexp.MustCompile(`\btitle="([^"]*)"`)
+	imgSrcPattern    = regexp.MustCompile(`\bsrc="([^"]*)"`)
+)
+
+// LikedBy はチェックインにいいねしたユーザーを返す。API が無いためチェックインのページから読み取るので、
+// ページに表示されるユーザー (名前・表示名・アイコン) だけが対象になる。
+func (c *Client) LikedBy(ctx context.Context, checkinID int64) ([]User, error) {
+	res, err := c.doRequest(ctx, http.MethodGet, "/checkin/"+strconv.FormatInt(checkinID, 10), nil, nil, "")
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return nil, readErrorResponse(res)
+	}
+	b, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+	return parseLikedBy(string(b)), nil
+}
+
+func parseLikedBy(page string) []User {
+	users := []User{}
+	section := likeUsersPattern.FindStringSubmatch(page)
+	if section == nil {
+		return users
+	}
+	for _, m := range likeUserPattern.FindAllStringSubmatch(section[1], -1) {
+		name, err := url.PathUnescape(m[1])
+		if err != nil {
+			name = m[1]
+		}
+		user := User{Name: name}
+		if t := imgTitlePattern.FindStringSubmatch(m[2]); t != nil {
+			user.DisplayName = html.UnescapeString(t[1])
+		}
+		if src := imgSrcPattern.FindStringSubmatch(m[2]); src != nil {
+			user.ProfileMiniImageURL = html.UnescapeString(src[1])
+		}
+		users = append(users, user)
+	}
+	return users
+}
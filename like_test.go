@@ -0,0 +1,96 @@
+package go_tissue
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync/atomic"
+	"testing"
+)
+
+// serveLikes はチェックイン 1 だけが存在するものとして、いいねの API を f に足す。
+func serveLikes(f *fakeTissue) {
+	var liked atomic.Bool
+	write := func(w http.ResponseWriter, id int64, like bool) {
+		if id != 1 {
+			http.Error(w, `{"message":"Not Found"}`, http.StatusNotFound)
+			return
+		}
+		status := http.StatusOK
+		if liked.Swap(like) == like {
+			status = http.StatusConflict
+			if !like {
+				status = http.StatusNotFound
+			}
+		}
+		count := 0
+		if like {
+			count = 1
+		}
+		w.WriteHeader(status)
+		_ = json.NewEncoder(w).Encode(map[string]Checkin{"ejaculation": {ID: 1, LikesCount: count}})
+	}
+	f.handle("/api/likes", func(w http.ResponseWriter, r *http.Request) {
+		var in struct{ ID int64 }
+		_ = json.NewDecoder(r.Body).Decode(&in)
+		write(w, in.ID, true)
+	})
+	f.handle("/api/likes/", func(w http.ResponseWriter, r *http.Request) {
+		write(w, map[string]int64{"/api/likes/1": 1}[r.URL.Path], false)
+	})
+}
+
+func TestClient_LikeUnlike(t *testing.T) {
+	f := newFakeTissue(t)
+	serveLikes(f)
+	client := newFakeClient(t, f, "secret")
+	ctx := context.Background()
+
+	// 2回目は既にいいね済み (409) だが成功として扱う
+	for i := 0; i < 2; i++ {
+		checkin, err := client.Like(ctx, 1)
+		if err != nil {
+			t.Fatalf("Like #%d: %v", i, err)
+		}
+		if checkin.LikesCount != 1 {
+			t.Errorf("Like #%d: unexpected likes count %d", i, checkin.LikesCount)
+		}
+	}
+	for i := 0; i < 2; i++ {
+		checkin, err := client.Unlike(ctx, 1)
+		if err != nil {
+			t.Fatalf("Unlike #%d: %v", i, err)
+		}
+		if checkin.LikesCount != 0 {
+			t.Errorf("Unlike #%d: unexpected likes count %d", i, checkin.LikesCount)
+		}
+	}
+
+	if _, err := client.Like(ctx, 2); !IsNotFound(err) {
+		t.Errorf("expected not found for missing checkin, got %v", err)
+	}
+	if _, err := client.Unlike(ctx, 2); !IsNotFound(err) {
+		t.Errorf("expected not found for missing checkin, got %v", err)
+	}
+}
+
+func TestParseLikedBy(t *testing.T) {
+	page := `<div class="card"><div class="like-users flex-grow-1 overflow-hidden">
+<a href="https://shikorism.net/user/alice"><img src="https://example.com/a.png" width="30" height="30" class="rounded" data-toggle="tooltip" title="Alice &amp; Co"></a>
+<a href="https://shikorism.net/user/%E3%81%82"><img src="https://example.com/b.png" title="あ"></a>
+</div></div>
+<a href="https://shikorism.net/user/someone">unrelated</a>`
+	users := parseLikedBy(page)
+	if len(users) != 2 {
+		t.Fatalf("unexpected users: %+v", users)
+	}
+	if users[0].Name != "alice" || users[0].DisplayName != "Alice & Co" || users[0].ProfileMiniImageURL != "https://example.com/a.png" {
+		t.Errorf("unexpected user: %+v", users[0])
+	}
+	if users[1].Name != "あ" {
+		t.Errorf("unexpected user: %+v", users[1])
+	}
+	if got := parseLikedBy("<html></html>"); got == nil || len(got) != 0 {
+		t.Errorf("expected empty slice, got %#v", got)
+	}
+}
@@ -0,0 +1,27 @@
+package go_tissue
+
+// LinkCanonicalizer は送信前にリンクを正規の形に揃える。ClientOption.LinkCanonicalizer に指定すると、
+// チェックインとコレクションアイテムの作成・更新で送るリンクに適用される。実装は linkcanon パッケージにある。
+type LinkCanonicalizer interface {
+	CanonicalizeLink(link string) (string, error)
+}
+
+// CanonicalizeLink は c で link を揃える。c が nil か link が空ならそのまま返す。
+func CanonicalizeLink(c LinkCanonicalizer, link string) (string, error) {
+	if c == nil || link == "" {
+		return link, nil
+	}
+	return c.CanonicalizeLink(link)
+}
+
+// CanonicalizeLinkPtr は更新用の *string に CanonicalizeLink を適用する。nil はそのまま返す。
+func CanonicalizeLinkPtr(c LinkCanonicalizer, link *string) (*string, error) {
+	if c == nil || link == nil {
+		return link, nil
+	}
+	result, err := CanonicalizeLink(c, *link)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
@@ -0,0 +1,281 @@
+// Package linkcanon は同じ作品を指すリンクを1つの形に揃える。
+//
+// トラッキング用のクエリ (utm_* など) を除き、リダイレクタやアフィリエイトの包みを外し、モバイル版のホストを通常のものに揃える。
+// Canonicalizer は tissue.LinkCanonicalizer を実装しているので、各クライアントの ClientOption.LinkCanonicalizer に指定すると
+// チェックイン・コレクションアイテムの作成・更新で送るリンクに適用される。
+package linkcanon
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"unicode/utf8"
+)
+
+// MaxLinkLength は API 仕様上のリンクの最大文字数。
+const MaxLinkLength = 2000
+
+// maxRewrites は URL を置き換える (リダイレクタを外す・ホストを変える) 回数の上限。
+const maxRewrites = 5
+
+var (
+	ErrScheme  = errors.New("link must be http or https")
+	ErrTooLong = errors.New("link is too long")
+)
+
+// Rule は1つのホスト向けの書き換え。u は書き換えてよい。
+type Rule interface {
+	// Match は u にこのルールを適用するかを返す。
+	Match(u *url.URL) bool
+	// Rewrite は u を書き換える。別の URL に置き換えるときは新しい URL を返し、そうでなければ nil を返す。
+	// 新しい URL を返すと、それに対してもう一度ルールを探して適用する。
+	Rewrite(u *url.URL) (*url.URL, error)
+}
+
+// HostRule は JSON で書ける、ホストごとの書き換え。
+type HostRule struct {
+	// Hosts は対象のホスト。サブドメインも含む。
+	Hosts []string `json:"hosts"`
+	// Paths はパスの前方一致。空ならすべてのパス。
+	Paths []string `json:"paths,omitempty"`
+	// Host は書き換え後のホスト (モバイル版を通常のものに揃えるなど)。空なら変えない。
+	Host string `json:"host,omitempty"`
+	// Unwrap はリダイレクト先が入っているクエリの名前。値が http(s) の URL なら、それに置き換える。
+	Unwrap []string `json:"unwrap,omitempty"`
+	// StripParams は取り除くクエリの名前。末尾の * は前方一致。
+	StripParams []string `json:"strip_params,omitempty"`
+	// KeepParams を指定すると、それ以外のクエリをすべて取り除く。
+	KeepParams []string `json:"keep_params,omitempty"`
+	// StripFragment は # 以降を取り除く。
+	StripFragment bool `json:"strip_fragment,omitempty"`
+}
+
+func (r *HostRule) Match(u *url.URL) bool {
+	if !r.matchHost(strings.ToLower(u.Hostname())) {
+		return false
+	}
+	if len(r.Paths) == 0 {
+		return true
+	}
+	for _, p := range r.Paths {
+		if strings.HasPrefix(u.Path, p) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *HostRule) matchHost(host string) bool {
+	for _, h := range r.Hosts {
+		h = strings.ToLower(strings.Trim(h, "."))
+		if h != "" && (host == h || strings.HasSuffix(host, "."+h)) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *HostRule) Rewrite(u *url.URL) (*url.URL, error) {
+	query := parseQuery(u.RawQuery)
+	for _, name := range r.Unwrap {
+		if v, ok := query.get(name); ok {
+			if target, err := url.Parse(v); err == nil && (target.Scheme == "http" || target.Scheme == "https") {
+				return target, nil
+			}
+		}
+	}
+	if len(r.KeepParams) > 0 {
+		query = query.filter(func(name string) bool { return !matchParam(r.KeepParams, name) })
+	}
+	query = query.filter(func(name string) bool { return matchParam(r.StripParams, name) })
+	u.RawQuery = query.encode()
+	if r.StripFragment {
+		u.Fragment, u.RawFragment = "", ""
+	}
+	if r.Host != "" && !strings.EqualFold(u.Hostname(), r.Host) {
+		// ホストを変えたら、変えた先のホストのルールも適用されるように新しい URL として返す
+		next := *u
+		next.Host = r.Host
+		if port := u.Port(); port != "" {
+			next.Host += ":" + port
+		}
+		return &next, nil
+	}
+	return nil, nil
+}
+
+// Canonicalizer は Rules を順に適用する。
+type Canonicalizer struct {
+	// StripParams はすべてのホストで取り除くクエリの名前。末尾の * は前方一致。
+	StripParams []string
+	Rules       []Rule
+	// MaxLength はリンクの最大文字数。0 なら MaxLinkLength。
+	MaxLength int
+}
+
+// Default は組み込みのルールだけの Canonicalizer を返す。
+func Default() *Canonicalizer {
+	return &Canonicalizer{StripParams: append([]string(nil), DefaultStripParams...), Rules: DefaultRules()}
+}
+
+// File は LoadFile で読む JSON の形式。
+type File struct {
+	StripParams []string    `json:"strip_params,omitempty"`
+	Rules       []*HostRule `json:"rules,omitempty"`
+	// NoDefaults なら組み込みのルールを使わない。
+	NoDefaults bool `json:"no_defaults,omitempty"`
+	MaxLength  int  `json:"max_length,omitempty"`
+}
+
+// LoadFile は path の JSON ファイルからルールを読み込む。ファイルのルールは組み込みのルールより先に適用する。
+func LoadFile(path string) (*Canonicalizer, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	f := &File{}
+	if err := json.Unmarshal(b, f); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	c := &Canonicalizer{MaxLength: f.MaxLength}
+	if !f.NoDefaults {
+		c = Default()
+		c.MaxLength = f.MaxLength
+	}
+	c.StripParams = append(c.StripParams, f.StripParams...)
+	rules := make([]Rule, 0, len(f.Rules)+len(c.Rules))
+	for _, r := range f.Rules {
+		rules = append(rules, r)
+	}
+	c.Rules = append(rules, c.Rules...)
+	return c, nil
+}
+
+// CanonicalizeLink は link を揃える。http(s) 以外や、揃えた結果が長すぎるものはエラーにする。
+func (c *Canonicalizer) CanonicalizeLink(link string) (string, error) {
+	u, err := url.Parse(strings.TrimSpace(link))
+	if err != nil {
+		return "", err
+	}
+	for i := 0; ; i++ {
+		u.Scheme = strings.ToLower(u.Scheme)
+		if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return "", fmt.Errorf("%w: %s", ErrScheme, link)
+		}
+		u.Host = normalizeHost(u)
+		next, err := c.apply(u)
+		if err != nil {
+			return "", err
+		}
+		if next == nil || i >= maxRewrites {
+			break
+		}
+		u = next
+	}
+	query := parseQuery(u.RawQuery).filter(func(name string) bool { return matchParam(c.StripParams, name) })
+	u.RawQuery = query.encode()
+	u.ForceQuery = false
+	if u.Path == "" {
+		u.Path = "/"
+	}
+	result := u.String()
+	max := c.MaxLength
+	if max <= 0 {
+		max = MaxLinkLength
+	}
+	if utf8.RuneCountInString(result) > max {
+		return "", fmt.Errorf("%w: %d characters (max %d)", ErrTooLong, utf8.RuneCountInString(result), max)
+	}
+	return result, nil
+}
+
+// apply は最初に一致したルールを適用する。
+func (c *Canonicalizer) apply(u *url.URL) (*url.URL, error) {
+	for _, r := range c.Rules {
+		if r.Match(u) {
+			return r.Rewrite(u)
+		}
+	}
+	return nil, nil
+}
+
+// normalizeHost はホストを小文字にし、既定のポートと末尾の . を除く。
+func normalizeHost(u *url.URL) string {
+	host := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+	if strings.Contains(host, ":") {
+		host = "[" + host + "]"
+	}
+	port := u.Port()
+	if port == "" || (u.Scheme == "http" && port == "80") || (u.Scheme == "https" && port == "443") {
+		return host
+	}
+	return host + ":" + port
+}
+
+func matchParam(patterns []string, name string) bool {
+	name = strings.ToLower(name)
+	for _, p := range patterns {
+		p = strings.ToLower(p)
+		if prefix, ok := strings.CutSuffix(p, "*"); ok {
+			if strings.HasPrefix(name, prefix) {
+				return true
+			}
+		} else if name == p {
+			return true
+		}
+	}
+	return false
+}
+
+// query は順序を保ったクエリ。url.Values はエンコード時に並べ替えるので使わない。
+type query []string
+
+func parseQuery(raw string) query {
+	var q query
+	for _, part := range strings.Split(raw, "&") {
+		if part != "" {
+			q = append(q, part)
+		}
+	}
+	return q
+}
+
+func (q query) name(part string) string {
+	name, _, _ := strings.Cut(part, "=")
+	if unescaped, err := url.QueryUnescape(name); err == nil {
+		return unescaped
+	}
+	return name
+}
+
+func (q query) get(name string) (string, bool) {
+	for _, part := range q {
+		if q.name(part) == name {
+			_, v, _ := strings.Cut(part, "=")
+			unescaped, err := url.QueryUnescape(v)
+			if err != nil {
+				return "", false
+			}
+			return unescaped, true
+		}
+	}
+	return "", false
+}
+
+// filter は drop が true を返す名前のものを除いたクエリを返す。
+func (q query) filter(drop func(name string) bool) query {
+	result := query{}
+	for _, part := range q {
+		if !drop(q.name(part)) {
+			result = append(result, part)
+		}
+	}
+	return result
+}
+
+func (q query) encode() string {
+	return strings.Join(q, "&")
+}
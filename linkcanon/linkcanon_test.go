@@ -0,0 +1,72 @@
+package linkcanon
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCanonicalizer_CanonicalizeLink(t *testing.T) {
+	c := Default()
+	cases := []struct {
+		in, want string
+	}{
+		{"https://example.com/a?utm_source=x&b=1&UTM_Medium=y#frag", "https://example.com/a?b=1#frag"},
+		{"HTTPS://Example.COM:443", "https://example.com/"},
+		{"http://example.com:8080/?fbclid=1", "http://example.com:8080/"},
+		{"https://m.youtube.com/watch?v=abc&si=xyz", "https://www.youtube.com/watch?v=abc"},
+		{"https://mobile.twitter.com/user/status/1?s=20&t=aaa", "https://twitter.com/user/status/1"},
+		{"https://www.google.com/url?q=https%3A%2F%2Fwww.dlsite.com%2Fmaniax%2Fwork%2F%3D%2Fproduct_id%2FRJ1.html%3Faffiliate_id%3Dx&sa=D", "https://www.dlsite.com/maniax/work/=/product_id/RJ1.html"},
+		{"https://al.dmm.co.jp/?lurl=https%3A%2F%2Fwww.dmm.co.jp%2Fdc%2Fdoujin%2F-%2Fdetail%2F%3D%2Fcid%3Dd_1%2F&af_id=aff-001&ch=toolbar", "https://www.dmm.co.jp/dc/doujin/-/detail/=/cid=d_1/"},
+		{"https://sp.dlsite.com/work?aid=1", "https://www.dlsite.com/work"},
+		{"https://www.google.com/search?q=https://example.com/", "https://www.google.com/search?q=https://example.com/"},
+		{"  https://example.com/?z=1&a=2  ", "https://example.com/?z=1&a=2"},
+	}
+	for _, tc := range cases {
+		got, err := c.CanonicalizeLink(tc.in)
+		if err != nil {
+			t.Errorf("%s: %v", tc.in, err)
+			continue
+		}
+		if got != tc.want {
+			t.Errorf("%s: got %s, want %s", tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestCanonicalizer_Errors(t *testing.T) {
+	c := Default()
+	for _, link := range []string{"ftp://example.com/", "javascript:alert(1)", "example.com/a"} {
+		if _, err := c.CanonicalizeLink(link); !errors.Is(err, ErrScheme) {
+			t.Errorf("%s: expected ErrScheme, got %v", link, err)
+		}
+	}
+	long := "https://example.com/" + strings.Repeat("a", MaxLinkLength)
+	if _, err := c.CanonicalizeLink(long); !errors.Is(err, ErrTooLong) {
+		t.Errorf("expected ErrTooLong, got %v", err)
+	}
+}
+
+func TestLoadFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "links.json")
+	content := `{
+		"strip_params": ["sessid"],
+		"rules": [{"hosts": ["example.jp"], "host": "www.example.jp", "keep_params": ["id"], "strip_fragment": true}]
+	}`
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	c, err := LoadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := c.CanonicalizeLink("https://sp.example.jp/p?id=1&x=2&sessid=3&utm_source=a#top")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "https://www.example.jp/p?id=1"; got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
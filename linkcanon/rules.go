@@ -0,0 +1,31 @@
+package linkcanon
+
+// DefaultStripParams はすべてのホストで取り除く、トラッキング用のクエリ。
+var DefaultStripParams = []string{
+	"utm_*", "fbclid", "gclid", "dclid", "yclid", "msclkid", "igshid", "mc_cid", "mc_eid", "_ga", "_gl",
+}
+
+// DefaultRules は組み込みのホストごとのルールを返す。呼び出しごとに新しいものを返すので、書き換えてもよい。
+func DefaultRules() []Rule {
+	return []Rule{
+		// リダイレクタ
+		&HostRule{Hosts: []string{"www.google.com", "www.google.co.jp"}, Paths: []string{"/url"}, Unwrap: []string{"q", "url"}},
+		&HostRule{Hosts: []string{"l.facebook.com", "l.instagram.com"}, Unwrap: []string{"u"}},
+		&HostRule{Hosts: []string{"www.pixiv.net"}, Paths: []string{"/jump.php"}, Unwrap: []string{"url"}},
+		// アフィリエイト
+		&HostRule{Hosts: []string{"al.dmm.co.jp", "al.fanza.co.jp", "al.dmm.com"}, Unwrap: []string{"lurl"}},
+		// モバイル版のホスト。変えた先のホストのルールも続けて適用される
+		&HostRule{Hosts: []string{"m.youtube.com"}, Host: "www.youtube.com"},
+		&HostRule{Hosts: []string{"mobile.twitter.com"}, Host: "twitter.com"},
+		&HostRule{Hosts: []string{"sp.nicovideo.jp"}, Host: "www.nicovideo.jp"},
+		&HostRule{Hosts: []string{"sp.dlsite.com"}, Host: "www.dlsite.com"},
+		&HostRule{Hosts: []string{"touch.pixiv.net"}, Host: "www.pixiv.net"},
+		// サイトごとのトラッキング・アフィリエイト用のクエリ
+		&HostRule{Hosts: []string{"dlsite.com", "dlsite.jp"}, StripParams: []string{"affiliate_id", "aid", "unique_op"}},
+		&HostRule{Hosts: []string{"dmm.co.jp", "dmm.com"}, StripParams: []string{"af_id", "ch", "ch_id", "i3_ref", "i3_ord", "dmmref"}},
+		&HostRule{Hosts: []string{"amazon.co.jp", "amazon.com"}, StripParams: []string{"tag", "ref", "ref_", "linkcode", "linkid", "camp", "creative", "psc", "pd_rd_*", "pf_rd_*", "content-id", "th"}},
+		&HostRule{Hosts: []string{"youtube.com"}, StripParams: []string{"si", "feature"}},
+		&HostRule{Hosts: []string{"twitter.com", "x.com"}, StripParams: []string{"s", "t", "ref_src", "ref_url"}},
+		&HostRule{Hosts: []string{"nicovideo.jp"}, StripParams: []string{"ref", "cmnhd_ref"}},
+	}
+}
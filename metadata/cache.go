@@ -0,0 +1,87 @@
+package metadata
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+// Cache は解決に成功した結果を、件数と有効期間を区切って保持する Resolver。複数の goroutine から使用できる。
+type Cache struct {
+	resolver Resolver
+	size     int
+	ttl      time.Duration
+	now      func() time.Time
+
+	mu      sync.Mutex
+	order   *list.List
+	entries map[string]*list.Element
+}
+
+type cacheEntry struct {
+	link    string
+	meta    Metadata
+	expires time.Time
+}
+
+// NewCache は resolver の結果を最大 size 件 (0 なら DefaultCacheSize)、ttl (0 なら DefaultCacheTTL) の間キャッシュする。
+// 上限を超えたら最も長く使われていないものから捨てる。
+func NewCache(resolver Resolver, size int, ttl time.Duration) *Cache {
+	if size == 0 {
+		size = DefaultCacheSize
+	}
+	if ttl == 0 {
+		ttl = DefaultCacheTTL
+	}
+	return &Cache{resolver: resolver, size: size, ttl: ttl, now: time.Now, order: list.New(), entries: map[string]*list.Element{}}
+}
+
+func (c *Cache) Resolve(ctx context.Context, link string) (*Metadata, error) {
+	if meta, ok := c.get(link); ok {
+		return meta, nil
+	}
+	meta, err := c.resolver.Resolve(ctx, link)
+	if err != nil {
+		return nil, err
+	}
+	c.put(link, meta)
+	return meta, nil
+}
+
+func (c *Cache) get(link string) (*Metadata, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[link]
+	if !ok {
+		return nil, false
+	}
+	entry := e.Value.(*cacheEntry)
+	if c.now().After(entry.expires) {
+		c.order.Remove(e)
+		delete(c.entries, link)
+		return nil, false
+	}
+	c.order.MoveToFront(e)
+	meta := entry.meta
+	meta.Tags = append([]string(nil), meta.Tags...)
+	return &meta, true
+}
+
+func (c *Cache) put(link string, meta *Metadata) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry := &cacheEntry{link: link, meta: *meta, expires: c.now().Add(c.ttl)}
+	entry.meta.Tags = append([]string(nil), meta.Tags...)
+	if e, ok := c.entries[link]; ok {
+		e.Value = entry
+		c.order.MoveToFront(e)
+		return
+	}
+	c.entries[link] = c.order.PushFront(entry)
+	for c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cacheEntry).link)
+	}
+}
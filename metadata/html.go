@@ -0,0 +1,141 @@
+package metadata
+
+import (
+	"context"
+	"encoding/json"
+	"html"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+var (
+	metaPattern   = regexp.MustCompile(`(?is)<meta\b([^>]*)>`)
+	linkPattern   = regexp.MustCompile(`(?is)<link\b([^>]*)>`)
+	titlePattern  = regexp.MustCompile(`(?is)<title[^>]*>(.*?)</title>`)
+	attrPattern   = regexp.MustCompile(`([a-zA-Z_:-]+)(?:\s*=\s*(?:"([^"]*)"|'([^']*)'))?`)
+	spacesPattern = regexp.MustCompile(`\s+`)
+)
+
+// HTMLResolver はページの OGP / Twitter Card / <title> からメタデータを作る。
+// タイトルか画像が欠けていて oEmbed の discovery リンクがあれば、oEmbed で補う。
+// 文字コードは UTF-8 を前提とする。
+type HTMLResolver struct {
+	Fetcher *Fetcher
+	// Header はリクエストに付けるヘッダー。年齢確認の Cookie などに使う。
+	Header http.Header
+}
+
+func (r *HTMLResolver) Resolve(ctx context.Context, link string) (*Metadata, error) {
+	if _, err := parseLink(link); err != nil {
+		return nil, err
+	}
+	page, res, err := r.Fetcher.Get(ctx, link, r.Header)
+	if err != nil {
+		return nil, err
+	}
+	meta := parseHTML(string(page), res.Request.URL)
+	meta.URL = link
+	meta.Resolver = "html"
+	if meta.Title == "" || meta.Image == "" {
+		if endpoint := oEmbedEndpoint(string(page), res.Request.URL); endpoint != "" {
+			r.fillOEmbed(ctx, meta, endpoint)
+		}
+	}
+	return meta, nil
+}
+
+// fillOEmbed は meta の空欄を oEmbed の結果で埋める。oEmbed の失敗は無視する。
+func (r *HTMLResolver) fillOEmbed(ctx context.Context, meta *Metadata, endpoint string) {
+	b, _, err := r.Fetcher.Get(ctx, endpoint, nil)
+	if err != nil {
+		return
+	}
+	var o struct {
+		Title        string `json:"title"`
+		AuthorName   string `json:"author_name"`
+		ThumbnailURL string `json:"thumbnail_url"`
+	}
+	if json.Unmarshal(b, &o) != nil {
+		return
+	}
+	if meta.Title == "" {
+		meta.Title = o.Title
+	}
+	if meta.Image == "" {
+		meta.Image = o.ThumbnailURL
+	}
+}
+
+func parseHTML(page string, base *url.URL) *Metadata {
+	props := map[string]string{}
+	for _, m := range metaPattern.FindAllStringSubmatch(page, -1) {
+		attrs := parseAttrs(m[1])
+		key := attrs["property"]
+		if key == "" {
+			key = attrs["name"]
+		}
+		key = strings.ToLower(key)
+		if _, ok := props[key]; key == "" || ok {
+			continue
+		}
+		props[key] = strings.TrimSpace(attrs["content"])
+	}
+	first := func(keys ...string) string {
+		for _, k := range keys {
+			if v := props[k]; v != "" {
+				return v
+			}
+		}
+		return ""
+	}
+	meta := &Metadata{
+		Title:       first("og:title", "twitter:title"),
+		Description: first("og:description", "twitter:description", "description"),
+		Image:       resolveRef(base, first("og:image", "og:image:url", "twitter:image", "twitter:image:src")),
+	}
+	if meta.Title == "" {
+		if m := titlePattern.FindStringSubmatch(page); m != nil {
+			meta.Title = cleanText(m[1])
+		}
+	}
+	return meta
+}
+
+func oEmbedEndpoint(page string, base *url.URL) string {
+	for _, m := range linkPattern.FindAllStringSubmatch(page, -1) {
+		attrs := parseAttrs(m[1])
+		if strings.EqualFold(attrs["type"], "application/json+oembed") {
+			return resolveRef(base, attrs["href"])
+		}
+	}
+	return ""
+}
+
+func parseAttrs(s string) map[string]string {
+	attrs := map[string]string{}
+	for _, m := range attrPattern.FindAllStringSubmatch(s, -1) {
+		v := m[2]
+		if v == "" {
+			v = m[3]
+		}
+		attrs[strings.ToLower(m[1])] = html.UnescapeString(v)
+	}
+	return attrs
+}
+
+func resolveRef(base *url.URL, ref string) string {
+	if ref == "" || base == nil {
+		return ref
+	}
+	u, err := base.Parse(ref)
+	if err != nil {
+		return ref
+	}
+	return u.String()
+}
+
+func cleanText(s string) string {
+	return strings.TrimSpace(spacesPattern.ReplaceAllString(html.UnescapeString(s), " "))
+}
@@ -0,0 +1,167 @@
+// Package metadata はチェックインのリンク先の情報 (Tissue の「オカズカード」に相当するタイトル・画像・タグ) を取得する。
+//
+// New が返す Resolver は、サイト別の Resolver (pixiv / DLsite / FANZA) で扱えないリンクを
+// 汎用の HTML (OGP / Twitter Card / oEmbed) の Resolver で解決し、結果をキャッシュする。
+package metadata
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+type Metadata struct {
+	URL         string   `json:"url"`
+	Title       string   `json:"title"`
+	Description string   `json:"description,omitempty"`
+	Image       string   `json:"image,omitempty"`
+	Tags        []string `json:"tags,omitempty"`
+	// Resolver は解決に使った Resolver の名前。
+	Resolver string `json:"resolver"`
+}
+
+// Resolver はリンクのメタデータを取得する。
+type Resolver interface {
+	Resolve(ctx context.Context, link string) (*Metadata, error)
+}
+
+// SiteResolver は Match が true を返すリンクだけを解決する Resolver。
+type SiteResolver interface {
+	Resolver
+	Match(u *url.URL) bool
+}
+
+// ErrUnsupported はリンクを解決できる Resolver が無いことを表す。
+var ErrUnsupported = errors.New("unsupported link")
+
+const (
+	DefaultTimeout     = 10 * time.Second
+	DefaultMaxBodySize = 2 << 20
+	DefaultCacheSize   = 256
+	DefaultCacheTTL    = time.Hour
+)
+
+type Option struct {
+	// HTTPClient は取得に使うクライアント。nil なら http.DefaultClient。
+	HTTPClient *http.Client
+	// Timeout は1回の Resolve にかける時間の上限。0 なら DefaultTimeout。
+	Timeout time.Duration
+	// MaxBodySize は読み込む応答の大きさの上限。超えた分は読まない。0 なら DefaultMaxBodySize。
+	MaxBodySize int64
+	// CacheSize はキャッシュする件数。0 なら DefaultCacheSize、負ならキャッシュしない。
+	CacheSize int
+	// CacheTTL はキャッシュの有効期間。0 なら DefaultCacheTTL。
+	CacheTTL time.Duration
+}
+
+// New は既定のサイト別 Resolver と汎用の Resolver を組み合わせた Resolver を返す。
+func New(option *Option) Resolver {
+	if option == nil {
+		option = &Option{}
+	}
+	f := &Fetcher{Client: option.HTTPClient, MaxBodySize: option.MaxBodySize}
+	var r Resolver = &Chain{
+		Sites: []SiteResolver{
+			&PixivResolver{Fetcher: f},
+			&DLsiteResolver{Fetcher: f},
+			&FanzaResolver{Fetcher: f},
+		},
+		Fallback: &HTMLResolver{Fetcher: f},
+	}
+	r = &timeoutResolver{resolver: r, timeout: option.Timeout}
+	if option.CacheSize >= 0 {
+		r = NewCache(r, option.CacheSize, option.CacheTTL)
+	}
+	return r
+}
+
+// Chain は Sites のうち最初に Match したもので解決し、どれも Match しなければ Fallback で解決する。
+type Chain struct {
+	Sites    []SiteResolver
+	Fallback Resolver
+}
+
+func (c *Chain) Resolve(ctx context.Context, link string) (*Metadata, error) {
+	u, err := parseLink(link)
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range c.Sites {
+		if s.Match(u) {
+			return s.Resolve(ctx, link)
+		}
+	}
+	if c.Fallback == nil {
+		return nil, ErrUnsupported
+	}
+	return c.Fallback.Resolve(ctx, link)
+}
+
+type timeoutResolver struct {
+	resolver Resolver
+	timeout  time.Duration
+}
+
+func (r *timeoutResolver) Resolve(ctx context.Context, link string) (*Metadata, error) {
+	timeout := r.timeout
+	if timeout == 0 {
+		timeout = DefaultTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	return r.resolver.Resolve(ctx, link)
+}
+
+func parseLink(link string) (*url.URL, error) {
+	u, err := url.Parse(link)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupported, link)
+	}
+	return u, nil
+}
+
+// Fetcher は大きさの上限付きで HTTP の応答を読む。各 Resolver で共有する。
+type Fetcher struct {
+	Client      *http.Client
+	MaxBodySize int64
+}
+
+// Get は rawURL を取得し、上限までの本文と応答を返す。2xx 以外はエラー。
+func (f *Fetcher) Get(ctx context.Context, rawURL string, header http.Header) ([]byte, *http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	req.Header.Set("User-Agent", "go-tissue")
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	client := f.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return nil, res, fmt.Errorf("fetch %s: unexpected status %s", rawURL, res.Status)
+	}
+	limit := f.MaxBodySize
+	if limit == 0 {
+		limit = DefaultMaxBodySize
+	}
+	b, err := io.ReadAll(io.LimitReader(res.Body, limit))
+	if err != nil {
+		return nil, res, err
+	}
+	return b, res, nil
+}
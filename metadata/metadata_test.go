@@ -0,0 +1,276 @@
+package metadata
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fixtureServer はホスト名に関係なく全リクエストを handler で受けるクライアントを返す。
+func fixtureServer(t *testing.T, handler http.HandlerFunc) *http.Client {
+	t.Helper()
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	target, _ := url.Parse(srv.URL)
+	return &http.Client{Transport: rewriteTransport{target: target}}
+}
+
+type rewriteTransport struct {
+	target *url.URL
+}
+
+func (t rewriteTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	r := req.Clone(req.Context())
+	r.Header.Set("X-Original-Host", req.URL.Host)
+	r.URL.Scheme = t.target.Scheme
+	r.URL.Host = t.target.Host
+	res, err := http.DefaultTransport.RoundTrip(r)
+	if err == nil {
+		res.Request = req
+	}
+	return res, err
+}
+
+const ogpPage = `<!DOCTYPE html><html><head>
+<title>Fallback Title</title>
+<meta property="og:title" content="OGP &amp; Title">
+<meta property="og:description" content="desc">
+<meta property="og:image" content="/img/a.png">
+<meta name="twitter:title" content="Twitter Title">
+</head><body></body></html>`
+
+func TestHTMLResolver_OGP(t *testing.T) {
+	client := fixtureServer(t, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, ogpPage)
+	})
+	r := &HTMLResolver{Fetcher: &Fetcher{Client: client}}
+	meta, err := r.Resolve(context.Background(), "https://example.com/page")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &Metadata{URL: "https://example.com/page", Title: "OGP & Title", Description: "desc", Image: "https://example.com/img/a.png", Resolver: "html"}
+	if !reflect.DeepEqual(meta, want) {
+		t.Fatalf("got %+v, want %+v", meta, want)
+	}
+}
+
+func TestHTMLResolver_TwitterCardAndTitle(t *testing.T) {
+	client := fixtureServer(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/twitter":
+			fmt.Fprint(w, `<meta name='twitter:title' content='TW'><meta name="twitter:image" content="https://img.example/x.jpg">`)
+		default:
+			fmt.Fprint(w, "<html><head><title>\n  Plain\n  Title </title></head></html>")
+		}
+	})
+	r := &HTMLResolver{Fetcher: &Fetcher{Client: client}}
+	meta, err := r.Resolve(context.Background(), "https://example.com/twitter")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if meta.Title != "TW" || meta.Image != "https://img.example/x.jpg" {
+		t.Fatalf("unexpected twitter card: %+v", meta)
+	}
+	meta, err = r.Resolve(context.Background(), "https://example.com/plain")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if meta.Title != "Plain Title" {
+		t.Fatalf("unexpected title: %q", meta.Title)
+	}
+}
+
+func TestHTMLResolver_OEmbed(t *testing.T) {
+	client := fixtureServer(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/watch":
+			fmt.Fprint(w, `<link rel="alternate" type="application/json+oembed" href="/oembed?url=x">`)
+		case "/oembed":
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprint(w, `{"title":"Video","thumbnail_url":"https://img.example/v.jpg"}`)
+		default:
+			http.NotFound(w, r)
+		}
+	})
+	r := &HTMLResolver{Fetcher: &Fetcher{Client: client}}
+	meta, err := r.Resolve(context.Background(), "https://video.example/watch")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if meta.Title != "Video" || meta.Image != "https://img.example/v.jpg" {
+		t.Fatalf("unexpected oembed result: %+v", meta)
+	}
+}
+
+func TestHTMLResolver_MaxBodySize(t *testing.T) {
+	client := fixtureServer(t, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, strings.Repeat(" ", 1024)+`<meta property="og:title" content="late">`)
+	})
+	r := &HTMLResolver{Fetcher: &Fetcher{Client: client, MaxBodySize: 512}}
+	meta, err := r.Resolve(context.Background(), "https://example.com/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if meta.Title != "" {
+		t.Fatalf("content beyond the limit was read: %+v", meta)
+	}
+}
+
+func TestHTMLResolver_Errors(t *testing.T) {
+	client := fixtureServer(t, http.NotFound)
+	r := &HTMLResolver{Fetcher: &Fetcher{Client: client}}
+	if _, err := r.Resolve(context.Background(), "https://example.com/missing"); err == nil {
+		t.Fatal("expected error for 404")
+	}
+	if _, err := r.Resolve(context.Background(), "ftp://example.com/"); !errors.Is(err, ErrUnsupported) {
+		t.Fatalf("expected ErrUnsupported, got %v", err)
+	}
+}
+
+func TestPixivResolver(t *testing.T) {
+	client := fixtureServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Original-Host") != "www.pixiv.net" || r.URL.Path != "/ajax/illust/12345" || r.Header.Get("Referer") == "" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, `{"error":false,"message":"","body":{"illustTitle":"絵","illustComment":"line1<br />line2","urls":{"regular":"https://i.pximg.net/r.jpg"},"tags":{"tags":[{"tag":"オリジナル"},{"tag":"女の子"}]}}}`)
+	})
+	chain := &Chain{Sites: []SiteResolver{&PixivResolver{Fetcher: &Fetcher{Client: client}}}}
+	for _, link := range []string{
+		"https://www.pixiv.net/artworks/12345",
+		"https://www.pixiv.net/en/artworks/12345",
+		"https://www.pixiv.net/member_illust.php?mode=medium&illust_id=12345",
+	} {
+		meta, err := chain.Resolve(context.Background(), link)
+		if err != nil {
+			t.Fatalf("%s: %v", link, err)
+		}
+		if meta.Title != "絵" || meta.Description != "line1 line2" || meta.Image != "https://i.pximg.net/r.jpg" || meta.Resolver != "pixiv" {
+			t.Fatalf("%s: unexpected metadata: %+v", link, meta)
+		}
+		if !reflect.DeepEqual(meta.Tags, []string{"オリジナル", "女の子"}) {
+			t.Fatalf("%s: unexpected tags: %v", link, meta.Tags)
+		}
+	}
+}
+
+func TestDLsiteResolver(t *testing.T) {
+	client := fixtureServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if !strings.Contains(r.Header.Get("Cookie"), "adultchecked=1") {
+			fmt.Fprint(w, `<title>年齢認証</title>`)
+			return
+		}
+		fmt.Fprint(w, `<meta property="og:title" content="作品名 [サークル] | DLsite">
+<meta property="og:image" content="//img.dlsite.jp/work.jpg">
+<tr><th>ジャンル</th><td><div class="main_genre"><a href="/g/1">ボイス</a><a href="/g/2"> 癒し </a></div></td></tr>`)
+	})
+	r := &DLsiteResolver{Fetcher: &Fetcher{Client: client}}
+	link := "https://www.dlsite.com/maniax/work/=/product_id/RJ123456.html"
+	u, _ := url.Parse(link)
+	if !r.Match(u) {
+		t.Fatal("expected DLsite link to match")
+	}
+	meta, err := r.Resolve(context.Background(), link)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if meta.Title != "作品名 [サークル] | DLsite" || meta.Image != "https://img.dlsite.jp/work.jpg" {
+		t.Fatalf("unexpected metadata: %+v", meta)
+	}
+	if !reflect.DeepEqual(meta.Tags, []string{"ボイス", "癒し"}) {
+		t.Fatalf("unexpected tags: %v", meta.Tags)
+	}
+}
+
+func TestFanzaResolver(t *testing.T) {
+	client := fixtureServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if !strings.Contains(r.Header.Get("Cookie"), "age_check_done=1") {
+			fmt.Fprint(w, `<title>年齢認証 - FANZA</title>`)
+			return
+		}
+		fmt.Fprint(w, `<meta property="og:title" content="FANZA作品">`)
+	})
+	chain := &Chain{
+		Sites:    []SiteResolver{&FanzaResolver{Fetcher: &Fetcher{Client: client}}},
+		Fallback: &HTMLResolver{Fetcher: &Fetcher{Client: client}},
+	}
+	meta, err := chain.Resolve(context.Background(), "https://www.dmm.co.jp/digital/videoa/-/detail/=/cid=abc123/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if meta.Title != "FANZA作品" || meta.Resolver != "fanza" {
+		t.Fatalf("unexpected metadata: %+v", meta)
+	}
+	meta, err = chain.Resolve(context.Background(), "https://notdmm.co.jp/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if meta.Resolver != "html" {
+		t.Fatalf("expected fallback resolver, got %+v", meta)
+	}
+}
+
+type countingResolver struct {
+	calls atomic.Int32
+}
+
+func (r *countingResolver) Resolve(ctx context.Context, link string) (*Metadata, error) {
+	r.calls.Add(1)
+	return &Metadata{URL: link, Title: link, Tags: []string{"a"}}, nil
+}
+
+func TestCache(t *testing.T) {
+	inner := &countingResolver{}
+	cache := NewCache(inner, 2, time.Minute)
+	now := time.Now()
+	cache.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	meta, _ := cache.Resolve(ctx, "https://a.example/")
+	meta.Tags[0] = "modified"
+	meta, _ = cache.Resolve(ctx, "https://a.example/")
+	if inner.calls.Load() != 1 {
+		t.Fatalf("expected cache hit, calls=%d", inner.calls.Load())
+	}
+	if meta.Tags[0] != "a" {
+		t.Fatalf("cached entry was modified through the returned value: %v", meta.Tags)
+	}
+
+	_, _ = cache.Resolve(ctx, "https://b.example/")
+	_, _ = cache.Resolve(ctx, "https://c.example/") // a が追い出される
+	_, _ = cache.Resolve(ctx, "https://a.example/")
+	if inner.calls.Load() != 4 {
+		t.Fatalf("expected eviction of the oldest entry, calls=%d", inner.calls.Load())
+	}
+
+	now = now.Add(2 * time.Minute)
+	_, _ = cache.Resolve(ctx, "https://a.example/")
+	if inner.calls.Load() != 5 {
+		t.Fatalf("expected expired entry to be resolved again, calls=%d", inner.calls.Load())
+	}
+}
+
+func TestNew_Timeout(t *testing.T) {
+	client := fixtureServer(t, func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(2 * time.Second):
+		}
+	})
+	r := New(&Option{HTTPClient: client, Timeout: 50 * time.Millisecond, CacheSize: -1})
+	start := time.Now()
+	if _, err := r.Resolve(context.Background(), "https://example.com/slow"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+	if time.Since(start) > time.Second {
+		t.Fatal("timeout was not applied")
+	}
+}
@@ -0,0 +1,144 @@
+package metadata
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// PixivResolver は pixiv のイラストを pixiv の Ajax API で解決する。作品のタグも取得する。
+type PixivResolver struct {
+	Fetcher *Fetcher
+}
+
+var pixivArtworkPattern = regexp.MustCompile(`^/(?:[a-z]{2}/)?artworks/(\d+)`)
+
+func (r *PixivResolver) Match(u *url.URL) bool {
+	return pixivIllustID(u) != ""
+}
+
+func pixivIllustID(u *url.URL) string {
+	if u.Hostname() != "www.pixiv.net" && u.Hostname() != "pixiv.net" {
+		return ""
+	}
+	if m := pixivArtworkPattern.FindStringSubmatch(u.Path); m != nil {
+		return m[1]
+	}
+	if u.Path == "/member_illust.php" {
+		return u.Query().Get("illust_id")
+	}
+	return ""
+}
+
+func (r *PixivResolver) Resolve(ctx context.Context, link string) (*Metadata, error) {
+	u, err := parseLink(link)
+	if err != nil {
+		return nil, err
+	}
+	id := pixivIllustID(u)
+	if id == "" {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupported, link)
+	}
+	b, _, err := r.Fetcher.Get(ctx, "https://www.pixiv.net/ajax/illust/"+id, http.Header{"Referer": {"https://www.pixiv.net/"}})
+	if err != nil {
+		return nil, err
+	}
+	var res struct {
+		Error   bool   `json:"error"`
+		Message string `json:"message"`
+		Body    struct {
+			IllustTitle   string `json:"illustTitle"`
+			IllustComment string `json:"illustComment"`
+			URLs          struct {
+				Regular string `json:"regular"`
+			} `json:"urls"`
+			Tags struct {
+				Tags []struct {
+					Tag string `json:"tag"`
+				} `json:"tags"`
+			} `json:"tags"`
+		} `json:"body"`
+	}
+	if err := json.Unmarshal(b, &res); err != nil {
+		return nil, err
+	}
+	if res.Error {
+		return nil, errors.New("pixiv: " + res.Message)
+	}
+	meta := &Metadata{
+		URL:         link,
+		Title:       res.Body.IllustTitle,
+		Description: cleanText(stripTags(res.Body.IllustComment)),
+		Image:       res.Body.URLs.Regular,
+		Resolver:    "pixiv",
+	}
+	for _, t := range res.Body.Tags.Tags {
+		meta.Tags = append(meta.Tags, t.Tag)
+	}
+	return meta, nil
+}
+
+// DLsiteResolver は DLsite の作品ページを OGP とジャンル欄から解決する。ジャンルをタグとして返す。
+type DLsiteResolver struct {
+	Fetcher *Fetcher
+}
+
+var (
+	dlsiteGenrePattern = regexp.MustCompile(`(?is)<div class="main_genre">(.*?)</div>`)
+	anchorTextPattern  = regexp.MustCompile(`(?is)<a\b[^>]*>(.*?)</a>`)
+	tagPattern         = regexp.MustCompile(`(?s)<[^>]*>`)
+)
+
+func (r *DLsiteResolver) Match(u *url.URL) bool {
+	return u.Hostname() == "www.dlsite.com" && strings.Contains(u.Path, "/work/=/product_id/")
+}
+
+func (r *DLsiteResolver) Resolve(ctx context.Context, link string) (*Metadata, error) {
+	if _, err := parseLink(link); err != nil {
+		return nil, err
+	}
+	page, res, err := r.Fetcher.Get(ctx, link, http.Header{"Cookie": {"adultchecked=1"}})
+	if err != nil {
+		return nil, err
+	}
+	meta := parseHTML(string(page), res.Request.URL)
+	meta.URL = link
+	meta.Resolver = "dlsite"
+	if m := dlsiteGenrePattern.FindStringSubmatch(string(page)); m != nil {
+		for _, a := range anchorTextPattern.FindAllStringSubmatch(m[1], -1) {
+			if tag := cleanText(stripTags(a[1])); tag != "" {
+				meta.Tags = append(meta.Tags, tag)
+			}
+		}
+	}
+	return meta, nil
+}
+
+// FanzaResolver は FANZA (DMM) のページを年齢確認済みの Cookie を付けて取得し、OGP から解決する。
+type FanzaResolver struct {
+	Fetcher *Fetcher
+}
+
+func (r *FanzaResolver) Match(u *url.URL) bool {
+	host := u.Hostname()
+	return host == "dmm.co.jp" || strings.HasSuffix(host, ".dmm.co.jp")
+}
+
+func (r *FanzaResolver) Resolve(ctx context.Context, link string) (*Metadata, error) {
+	h := &HTMLResolver{Fetcher: r.Fetcher, Header: http.Header{"Cookie": {"age_check_done=1"}}}
+	meta, err := h.Resolve(ctx, link)
+	if err != nil {
+		return nil, err
+	}
+	meta.Resolver = "fanza"
+	return meta, nil
+}
+
+func stripTags(s string) string {
+	return tagPattern.ReplaceAllString(s, " ")
+}
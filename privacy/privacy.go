@@ -0,0 +1,300 @@
+// Package privacy は公開されているチェックイン・コレクションを条件で洗い出し、まとめて非公開にする。
+//
+// アカウントの IsProtected を外す前に、何が見えるようになるかを確認するためのもの。
+// Audit で Finding を集め、確認してから Apply で IsPrivate を立てる。
+package privacy
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	tissue "github.com/mohemohe/go-tissue"
+	"github.com/mohemohe/go-tissue/api"
+	"github.com/mohemohe/go-tissue/bulk"
+)
+
+// Client は *api.Client と *api.HybridClient が満たす、監査と非公開化に必要な操作。
+type Client interface {
+	bulk.Reader
+	UpdateCheckin(ctx context.Context, id int64, option *api.UpdateCheckinOption) (*tissue.Checkin, error)
+	UpdateCollection(ctx context.Context, id int64, option *api.UpdateCollectionOption) (*tissue.Collection, error)
+}
+
+// 対象の種類。
+const (
+	TargetCheckin    = "checkin"
+	TargetCollection = "collection"
+)
+
+// 該当した理由。
+const (
+	ReasonPublic       = "public"
+	ReasonTooSensitive = "too_sensitive"
+	ReasonDomain       = "domain"
+	ReasonKeyword      = "keyword"
+)
+
+// Rules は報告する公開エントリの条件。LoadFile で JSON ファイルから読み込める。
+type Rules struct {
+	// TooSensitive は過激フラグが付いた公開チェックインを報告する。
+	TooSensitive bool `json:"too_sensitive,omitempty"`
+	// Domains はリンク先のドメイン。サブドメインも含む。
+	Domains []string `json:"domains,omitempty"`
+	// Keywords はノート (コレクションはタイトルも) に含まれる語。大文字小文字は区別しない。
+	Keywords []string `json:"keywords,omitempty"`
+	// AllPublic は条件に関係なく、すべての公開エントリを報告する。
+	AllPublic bool `json:"all_public,omitempty"`
+}
+
+// LoadFile は path の JSON ファイルからルールを読み込む。
+func LoadFile(path string) (*Rules, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	r := &Rules{}
+	if err := json.Unmarshal(b, r); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return r, nil
+}
+
+// Finding は条件に該当した公開エントリ。
+type Finding struct {
+	Target string `json:"target"`
+	ID     int64  `json:"id"`
+	// Title はコレクションのタイトル。非公開にするときにそのまま送り直す。
+	Title string `json:"title,omitempty"`
+	Link  string `json:"link,omitempty"`
+	// Reasons は該当した理由。Details はその詳細 (一致したドメイン・語、コレクションならアイテムの ID) 。
+	Reasons []string `json:"reasons"`
+	Details []string `json:"details,omitempty"`
+}
+
+// Report は監査の結果。
+type Report struct {
+	PublicCheckins    int       `json:"public_checkins"`
+	PublicCollections int       `json:"public_collections"`
+	Findings          []Finding `json:"findings"`
+}
+
+// matcher は1件分の理由を重複なく集める。
+type matcher struct {
+	reasons []string
+	details []string
+}
+
+func (m *matcher) add(reason, detail string) {
+	if !contains(m.reasons, reason) {
+		m.reasons = append(m.reasons, reason)
+	}
+	if detail != "" && !contains(m.details, detail) {
+		m.details = append(m.details, detail)
+	}
+}
+
+func (r *Rules) matchLink(m *matcher, link, prefix string) {
+	if link == "" || len(r.Domains) == 0 {
+		return
+	}
+	u, err := url.Parse(link)
+	if err != nil {
+		return
+	}
+	if d := matchDomain(r.Domains, u.Hostname()); d != "" {
+		m.add(ReasonDomain, prefix+d)
+	}
+}
+
+func (r *Rules) matchText(m *matcher, text, prefix string) {
+	lower := strings.ToLower(text)
+	for _, k := range r.Keywords {
+		if k != "" && strings.Contains(lower, strings.ToLower(k)) {
+			m.add(ReasonKeyword, prefix+k)
+		}
+	}
+}
+
+// CheckCheckin は公開チェックイン c が条件に該当すれば Finding を返す。非公開なら nil。
+func (r *Rules) CheckCheckin(c tissue.Checkin) *Finding {
+	if c.IsPrivate {
+		return nil
+	}
+	m := &matcher{}
+	if r.AllPublic {
+		m.add(ReasonPublic, "")
+	}
+	if r.TooSensitive && c.IsTooSensitive {
+		m.add(ReasonTooSensitive, "")
+	}
+	r.matchLink(m, c.Link, "")
+	r.matchText(m, c.Note, "")
+	if len(m.reasons) == 0 {
+		return nil
+	}
+	return &Finding{Target: TargetCheckin, ID: c.ID, Link: c.Link, Reasons: m.reasons, Details: m.details}
+}
+
+// CheckCollection は公開コレクション c とそのアイテムが条件に該当すれば Finding を返す。非公開なら nil。
+func (r *Rules) CheckCollection(c tissue.Collection, items []tissue.CollectionItem) *Finding {
+	if c.IsPrivate {
+		return nil
+	}
+	m := &matcher{}
+	if r.AllPublic {
+		m.add(ReasonPublic, "")
+	}
+	r.matchText(m, c.Title, "title:")
+	for _, it := range items {
+		prefix := fmt.Sprintf("item %d:", it.ID)
+		r.matchLink(m, it.Link, prefix)
+		r.matchText(m, it.Note, prefix)
+	}
+	if len(m.reasons) == 0 {
+		return nil
+	}
+	return &Finding{Target: TargetCollection, ID: c.ID, Title: c.Title, Reasons: m.reasons, Details: m.details}
+}
+
+// Audit は user の全チェックインと全コレクション (とそのアイテム) を読み、条件に該当する公開エントリを返す。
+// ClientOption.Filter で隠れる (過激フラグ付きやミュートした) チェックインも監査する。
+func Audit(ctx context.Context, c Client, user string, r *Rules) (*Report, error) {
+	report := &Report{Findings: []Finding{}}
+	checkins, err := bulk.AllCheckins(ctx, c, user, nil)
+	if err != nil {
+		return nil, err
+	}
+	for _, ch := range checkins {
+		if !ch.IsPrivate {
+			report.PublicCheckins++
+		}
+		if f := r.CheckCheckin(ch); f != nil {
+			report.Findings = append(report.Findings, *f)
+		}
+	}
+	collections, err := bulk.AllCollections(ctx, c, user)
+	if err != nil {
+		return nil, err
+	}
+	for _, col := range collections {
+		if col.IsPrivate {
+			continue
+		}
+		report.PublicCollections++
+		var items []tissue.CollectionItem
+		if r.needsItems() {
+			if items, err = bulk.AllCollectionItems(ctx, c, col.ID); err != nil {
+				return nil, err
+			}
+		}
+		if f := r.CheckCollection(col, items); f != nil {
+			report.Findings = append(report.Findings, *f)
+		}
+	}
+	return report, nil
+}
+
+// needsItems はアイテムを読まないと判定できない条件があるかを返す。
+func (r *Rules) needsItems() bool {
+	return len(r.Domains) > 0 || len(r.Keywords) > 0
+}
+
+// Result は Apply で1件を非公開にした結果。
+type Result struct {
+	Target string `json:"target"`
+	bulk.ItemResult
+}
+
+type ApplyOption struct {
+	// Concurrency / Interval / MaxRetries は bulk.EachOption と同じ。
+	Concurrency int
+	Interval    time.Duration
+	MaxRetries  int
+	// OnResult は1件終わるたびに呼ばれる。複数の goroutine から同時には呼ばれない。
+	OnResult func(Result)
+}
+
+// Apply は findings をすべて非公開にする。チェックイン、コレクションの順に bulk.Each で並行して更新し、findings と同じ順で結果を返す。
+func Apply(ctx context.Context, c Client, findings []Finding, option *ApplyOption) []Result {
+	if option == nil {
+		option = &ApplyOption{}
+	}
+	private := true
+	titles := map[int64]string{}
+	var checkins, collections []int64
+	for _, f := range findings {
+		switch f.Target {
+		case TargetCheckin:
+			checkins = append(checkins, f.ID)
+		case TargetCollection:
+			collections = append(collections, f.ID)
+			titles[f.ID] = f.Title
+		}
+	}
+	results := map[string]bulk.ItemResult{}
+	each := func(target string, ids []int64, action func(ctx context.Context, id int64) error) {
+		rs := bulk.Each(ctx, ids, &bulk.EachOption{
+			Concurrency: option.Concurrency,
+			Interval:    option.Interval,
+			MaxRetries:  option.MaxRetries,
+			OnResult: func(r bulk.ItemResult) {
+				if option.OnResult != nil {
+					option.OnResult(Result{Target: target, ItemResult: r})
+				}
+			},
+		}, action)
+		for _, r := range rs {
+			results[key(target, r.ID)] = r
+		}
+	}
+	each(TargetCheckin, checkins, func(ctx context.Context, id int64) error {
+		_, err := c.UpdateCheckin(ctx, id, &api.UpdateCheckinOption{IsPrivate: &private})
+		return err
+	})
+	each(TargetCollection, collections, func(ctx context.Context, id int64) error {
+		// コレクションの更新は PUT なので、タイトルも送り直す
+		_, err := c.UpdateCollection(ctx, id, &api.UpdateCollectionOption{Title: titles[id], IsPrivate: true})
+		return err
+	})
+
+	list := make([]Result, 0, len(findings))
+	for _, f := range findings {
+		r, ok := results[key(f.Target, f.ID)]
+		if !ok {
+			r = bulk.ItemResult{ID: f.ID, Error: "unknown target: " + f.Target}
+		}
+		list = append(list, Result{Target: f.Target, ItemResult: r})
+	}
+	return list
+}
+
+func key(target string, id int64) string {
+	return target + ":" + strconv.FormatInt(id, 10)
+}
+
+// matchDomain は host が domains のいずれか (またはそのサブドメイン) なら、一致したドメインを返す。
+func matchDomain(domains []string, host string) string {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	for _, d := range domains {
+		d = strings.ToLower(strings.Trim(d, "."))
+		if d != "" && (host == d || strings.HasSuffix(host, "."+d)) {
+			return d
+		}
+	}
+	return ""
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
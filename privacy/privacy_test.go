@@ -0,0 +1,137 @@
+package privacy
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"testing"
+
+	tissue "github.com/mohemohe/go-tissue"
+	"github.com/mohemohe/go-tissue/api"
+)
+
+type fakeClient struct {
+	checkins    []tissue.Checkin
+	collections []tissue.Collection
+	items       map[int64][]tissue.CollectionItem
+
+	mu      sync.Mutex
+	updated map[string]interface{}
+}
+
+func page[T any](all []T, n, perPage int) []T {
+	start := (n - 1) * perPage
+	if start >= len(all) {
+		return nil
+	}
+	end := start + perPage
+	if end > len(all) {
+		end = len(all)
+	}
+	return all[start:end]
+}
+
+// UserCheckins は ClientOption.Filter で過激フラグ付きを隠したクライアントのように、Unfiltered でなければそれを取り除く。
+func (c *fakeClient) UserCheckins(ctx context.Context, name string, option *api.UserCheckinsOption) ([]tissue.Checkin, error) {
+	result := page(c.checkins, option.Page, option.PerPage)
+	if option.Unfiltered {
+		return result, nil
+	}
+	var visible []tissue.Checkin
+	for _, ch := range result {
+		if !ch.IsTooSensitive {
+			visible = append(visible, ch)
+		}
+	}
+	return visible, nil
+}
+
+func (c *fakeClient) UserCollections(ctx context.Context, name string, option *api.PageOption) ([]tissue.Collection, error) {
+	return page(c.collections, option.Page, option.PerPage), nil
+}
+
+func (c *fakeClient) ListCollectionItems(ctx context.Context, collectionID int64, option *api.PageOption) ([]tissue.CollectionItem, error) {
+	return page(c.items[collectionID], option.Page, option.PerPage), nil
+}
+
+func (c *fakeClient) UpdateCheckin(ctx context.Context, id int64, option *api.UpdateCheckinOption) (*tissue.Checkin, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.updated[key(TargetCheckin, id)] = *option.IsPrivate
+	return &tissue.Checkin{ID: id}, nil
+}
+
+func (c *fakeClient) UpdateCollection(ctx context.Context, id int64, option *api.UpdateCollectionOption) (*tissue.Collection, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.updated[key(TargetCollection, id)] = *option
+	return &tissue.Collection{ID: id}, nil
+}
+
+func TestAuditAndApply(t *testing.T) {
+	client := &fakeClient{
+		checkins: []tissue.Checkin{
+			{ID: 1, Link: "https://www.example.com/a"},
+			{ID: 2, IsTooSensitive: true},
+			{ID: 3, IsTooSensitive: true, IsPrivate: true},
+			{ID: 4, Note: "会社の昼休み"},
+			{ID: 5, Link: "https://example.org/"},
+		},
+		collections: []tissue.Collection{
+			{ID: 10, Title: "お気に入り"},
+			{ID: 11, Title: "会社", IsPrivate: true},
+			{ID: 12, Title: "その他"},
+		},
+		items: map[int64][]tissue.CollectionItem{
+			10: {{ID: 100, Link: "https://example.com/x"}},
+			12: {{ID: 120, Link: "https://example.org/"}},
+		},
+		updated: map[string]interface{}{},
+	}
+	rules := &Rules{TooSensitive: true, Domains: []string{"example.com"}, Keywords: []string{"会社"}}
+	report, err := Audit(context.Background(), client, "me", rules)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.PublicCheckins != 4 || report.PublicCollections != 2 {
+		t.Errorf("unexpected counts: %+v", report)
+	}
+	want := []Finding{
+		{Target: TargetCheckin, ID: 1, Link: "https://www.example.com/a", Reasons: []string{ReasonDomain}, Details: []string{"example.com"}},
+		{Target: TargetCheckin, ID: 2, Reasons: []string{ReasonTooSensitive}},
+		{Target: TargetCheckin, ID: 4, Reasons: []string{ReasonKeyword}, Details: []string{"会社"}},
+		{Target: TargetCollection, ID: 10, Title: "お気に入り", Reasons: []string{ReasonDomain}, Details: []string{"item 100:example.com"}},
+	}
+	if !reflect.DeepEqual(report.Findings, want) {
+		t.Errorf("unexpected findings:\n%+v\nwant\n%+v", report.Findings, want)
+	}
+
+	results := Apply(context.Background(), client, report.Findings, nil)
+	if len(results) != 4 || results[3].Target != TargetCollection || results[3].ID != 10 {
+		t.Errorf("unexpected results: %+v", results)
+	}
+	for _, r := range results {
+		if !r.OK {
+			t.Errorf("failed: %+v", r)
+		}
+	}
+	if got := client.updated[key(TargetCollection, 10)]; got != (api.UpdateCollectionOption{Title: "お気に入り", IsPrivate: true}) {
+		t.Errorf("unexpected collection update: %+v", got)
+	}
+	if len(client.updated) != 4 || client.updated[key(TargetCheckin, 2)] != true {
+		t.Errorf("unexpected updates: %+v", client.updated)
+	}
+}
+
+func TestRules_AllPublic(t *testing.T) {
+	rules := &Rules{AllPublic: true}
+	if f := rules.CheckCheckin(tissue.Checkin{ID: 1}); f == nil || !reflect.DeepEqual(f.Reasons, []string{ReasonPublic}) {
+		t.Errorf("unexpected finding: %+v", f)
+	}
+	if f := rules.CheckCheckin(tissue.Checkin{ID: 1, IsPrivate: true}); f != nil {
+		t.Errorf("private checkin must be ignored: %+v", f)
+	}
+	if f := (&Rules{}).CheckCollection(tissue.Collection{ID: 1}, nil); f != nil {
+		t.Errorf("empty rules must not match: %+v", f)
+	}
+}
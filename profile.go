@@ -0,0 +1,79 @@
+package go_tissue
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+)
+
+type UpdateProfileOption struct {
+	DisplayName *string
+	Bio         *string
+	URL         *string
+}
+
+type UpdatePrivacyOption struct {
+	IsProtected  *bool
+	PrivateLikes *bool
+}
+
+// UpdateProfile はプロフィール設定を変更し、変更後のユーザー情報を返す。nil のフィールドは変更しない。
+func (c *Client) UpdateProfile(ctx context.Context, option *UpdateProfileOption) (*User, error) {
+	if option == nil {
+		option = &UpdateProfileOption{}
+	}
+	return c.updateSetting(ctx, "/setting/profile", func(form url.Values) {
+		setFormString(form, "display_name", option.DisplayName)
+		setFormString(form, "bio", option.Bio)
+		setFormString(form, "url", option.URL)
+	})
+}
+
+// UpdatePrivacy はプライバシー設定を変更し、変更後のユーザー情報を返す。nil のフィールドは変更しない。
+func (c *Client) UpdatePrivacy(ctx context.Context, option *UpdatePrivacyOption) (*User, error) {
+	if option == nil {
+		option = &UpdatePrivacyOption{}
+	}
+	return c.updateSetting(ctx, "/setting/privacy", func(form url.Values) {
+		setFormBool(form, "is_protected", option.IsProtected)
+		setFormBool(form, "private_likes", option.PrivateLikes)
+	})
+}
+
+// updateSetting は設定ページのフォームを現在の値で埋め、update で書き換えてから送信する。
+func (c *Client) updateSetting(ctx context.Context, spath string, update func(form url.Values)) (*User, error) {
+	page, err := c.getPage(ctx, spath)
+	if err != nil {
+		return nil, err
+	}
+	form, err := currentForm(page, spath)
+	if err != nil {
+		return nil, err
+	}
+	update(form)
+	if _, err := c.submitForm(ctx, http.MethodPost, spath, form); err != nil {
+		return nil, err
+	}
+	me, err := c.Me(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &me.User, nil
+}
+
+func setFormString(form url.Values, name string, value *string) {
+	if value != nil {
+		form[name] = []string{*value}
+	}
+}
+
+// setFormBool はチェックボックスの値を設定する。ブラウザと同様に、外す場合は送信しない。
+func setFormBool(form url.Values, name string, value *bool) {
+	switch {
+	case value == nil:
+	case *value:
+		form[name] = []string{"1"}
+	default:
+		delete(form, name)
+	}
+}
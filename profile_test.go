@@ -0,0 +1,101 @@
+package go_tissue
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"net/http"
+	"testing"
+)
+
+// serveProfile はプロフィールとプライバシーの設定画面を f に足す。どちらも f.user を書き換える。
+func serveProfile(f *fakeTissue) {
+	f.handle("/setting/profile", func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		if r.Method == http.MethodPost {
+			f.user.DisplayName, f.user.Bio, f.user.URL = r.FormValue("display_name"), r.FormValue("bio"), r.FormValue("url")
+			http.Redirect(w, r, "/setting/profile", http.StatusFound)
+			return
+		}
+		fmt.Fprintf(w, `<form action="/logout" method="POST"><input type="hidden" name="_token" value="t"></form>`+
+			`<form action="%s/setting/profile" method="POST"><input type="hidden" name="_token" value="t">`+
+			`<input type="text" name="display_name" value="%s"><input type="email" name="email" value="alice@example.com">`+
+			`<textarea name="bio" rows="3">%s</textarea><input name="url" value="%s"><button type="submit">更新</button></form>`,
+			f.URL, html.EscapeString(f.user.DisplayName), html.EscapeString(f.user.Bio), html.EscapeString(f.user.URL))
+	})
+	f.handle("/setting/privacy", func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		if r.Method == http.MethodPost {
+			if r.FormValue("email") != "" {
+				http.Error(w, "unexpected field", http.StatusUnprocessableEntity)
+				return
+			}
+			f.user.IsProtected, f.user.PrivateLikes = r.Form.Has("is_protected"), r.Form.Has("private_likes")
+			http.Redirect(w, r, "/setting/privacy", http.StatusFound)
+			return
+		}
+		checked := map[bool]string{true: " checked"}
+		fmt.Fprintf(w, `<form action="/setting/privacy" method="POST">`+
+			`<input type="checkbox" name="is_protected" value="1"%s><input type="checkbox" name="accept_analytics" value="1" checked>`+
+			`<input type="checkbox" name="private_likes" value="1"%s></form>`,
+			checked[f.user.IsProtected], checked[f.user.PrivateLikes])
+	})
+}
+
+func TestClient_UpdateProfile(t *testing.T) {
+	f := newFakeTissue(t)
+	serveProfile(f)
+	client := newFakeClient(t, f, "secret")
+	ctx := context.Background()
+
+	bio := "line1\n<b>&line2</b>"
+	user, err := client.UpdateProfile(ctx, &UpdateProfileOption{Bio: &bio})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.Bio != bio || user.DisplayName != "Alice" {
+		t.Errorf("unexpected user: %+v", user)
+	}
+
+	// 指定しなかったフィールドは現在の値のまま送られる
+	url := "https://example.com"
+	user, err = client.UpdateProfile(ctx, &UpdateProfileOption{URL: &url})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.Bio != bio || user.URL != url || user.DisplayName != "Alice" {
+		t.Errorf("unexpected user: %+v", user)
+	}
+}
+
+func TestClient_UpdatePrivacy(t *testing.T) {
+	f := newFakeTissue(t)
+	serveProfile(f)
+	client := newFakeClient(t, f, "secret")
+	ctx := context.Background()
+
+	on, off := true, false
+	user, err := client.UpdatePrivacy(ctx, &UpdatePrivacyOption{IsProtected: &on})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !user.IsProtected || user.PrivateLikes {
+		t.Errorf("unexpected user: %+v", user)
+	}
+	user, err = client.UpdatePrivacy(ctx, &UpdatePrivacyOption{PrivateLikes: &on})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !user.IsProtected || !user.PrivateLikes {
+		t.Errorf("unexpected user: %+v", user)
+	}
+	user, err = client.UpdatePrivacy(ctx, &UpdatePrivacyOption{IsProtected: &off})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.IsProtected || !user.PrivateLikes {
+		t.Errorf("unexpected user: %+v", user)
+	}
+}
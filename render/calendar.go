@@ -0,0 +1,130 @@
+package render
+
+import (
+	"image"
+	"io"
+	"math"
+	"strconv"
+	"time"
+
+	tissue "github.com/mohemohe/go-tissue"
+)
+
+const dateLayout = "2006-01-02"
+
+type CalendarOption struct {
+	// Since, Until は描画する期間 (両端を含む)。Until の既定値は今日、Since の既定値は Until の1年前の翌日。
+	Since    time.Time
+	Until    time.Time
+	Palette  *Palette
+	CellSize int
+	CellGap  int
+	// Location は日付の境界に使うタイムゾーン。既定値は time.Local。
+	Location *time.Location
+}
+
+type calendarLayout struct {
+	since, until time.Time
+	palette      Palette
+	cell, gap    float64
+}
+
+func (o *CalendarOption) layout() calendarLayout {
+	if o == nil {
+		o = &CalendarOption{}
+	}
+	loc := o.Location
+	if loc == nil {
+		loc = time.Local
+	}
+	l := calendarLayout{
+		palette: o.Palette.orDefault(),
+		cell:    float64(o.CellSize),
+		gap:     float64(o.CellGap),
+	}
+	if l.cell <= 0 {
+		l.cell = 11
+	}
+	if l.gap <= 0 {
+		l.gap = 2
+	}
+	until := o.Until
+	if until.IsZero() {
+		until = time.Now()
+	}
+	l.until = truncateDay(until.In(loc))
+	if o.Since.IsZero() {
+		l.since = l.until.AddDate(-1, 0, 1)
+	} else {
+		l.since = truncateDay(o.Since.In(loc))
+	}
+	return l
+}
+
+func truncateDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// daysBetween は夏時間の切り替えを挟んでも正しい日数を返す。
+func daysBetween(a, b time.Time) int {
+	return int(math.Round(b.Sub(a).Hours() / 24))
+}
+
+func buildCalendar(counts []tissue.DailyCheckinCount, option *CalendarOption) *canvas {
+	l := option.layout()
+	byDate := make(map[string]int, len(counts))
+	max := 0
+	for _, c := range counts {
+		byDate[c.Date] += c.Count
+	}
+	for d := l.since; !d.After(l.until); d = d.AddDate(0, 0, 1) {
+		if n := byDate[d.Format(dateLayout)]; n > max {
+			max = n
+		}
+	}
+
+	const left, top = 28.0, 18.0
+	start := l.since.AddDate(0, 0, -int(l.since.Weekday()))
+	weeks := daysBetween(start, l.until)/7 + 1
+	step := l.cell + l.gap
+	c := &canvas{
+		width:      int(left + float64(weeks)*step + l.gap),
+		height:     int(top + 7*step + l.gap),
+		background: l.palette.Background,
+	}
+
+	for i, label := range []string{"", "Mon", "", "Wed", "", "Fri", ""} {
+		if label == "" {
+			continue
+		}
+		c.add(text{x: left - 4, y: top + float64(i)*step + l.cell - 1, size: l.cell - 1, anchor: "end", fill: l.palette.Text, value: label})
+	}
+
+	lastMonth := time.Month(0)
+	for d := l.since; !d.After(l.until); d = d.AddDate(0, 0, 1) {
+		x := left + float64(daysBetween(start, d)/7)*step
+		y := top + float64(d.Weekday())*step
+		if (d.Weekday() == time.Sunday || d.Equal(l.since)) && d.Month() != lastMonth {
+			c.add(text{x: x, y: top - 6, size: l.cell - 1, fill: l.palette.Text, value: d.Month().String()[:3]})
+			lastMonth = d.Month()
+		}
+		key := d.Format(dateLayout)
+		n := byDate[key]
+		c.add(rect{x: x, y: y, w: l.cell, h: l.cell, radius: 2, fill: l.palette.fill(n, max), title: key + ": " + strconv.Itoa(n)})
+	}
+	return c
+}
+
+// CalendarSVG は日次チェックイン数を GitHub 風のカレンダーヒートマップとして SVG で書き出す。
+func CalendarSVG(w io.Writer, counts []tissue.DailyCheckinCount, option *CalendarOption) error {
+	return buildCalendar(counts, option).writeSVG(w)
+}
+
+// CalendarImage は CalendarSVG と同じ図をラベルなしのラスタ画像として返す。
+func CalendarImage(counts []tissue.DailyCheckinCount, option *CalendarOption) image.Image {
+	return buildCalendar(counts, option).image()
+}
+
+func CalendarPNG(w io.Writer, counts []tissue.DailyCheckinCount, option *CalendarOption) error {
+	return buildCalendar(counts, option).writePNG(w)
+}
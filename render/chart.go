@@ -0,0 +1,160 @@
+package render
+
+import (
+	"image"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+
+	tissue "github.com/mohemohe/go-tissue"
+	"github.com/mohemohe/go-tissue/api"
+)
+
+type ChartOption struct {
+	Palette *Palette
+	// Size は時間帯チャートでは一辺の長さ、タグチャートでは横幅 (px)。
+	Size int
+	// Limit はタグチャートに描画する上位件数。既定値は 10。
+	Limit int
+}
+
+func (o *ChartOption) size(def int) float64 {
+	if o == nil || o.Size <= 0 {
+		return float64(def)
+	}
+	return float64(o.Size)
+}
+
+func (o *ChartOption) palette() Palette {
+	if o == nil {
+		return DefaultPalette
+	}
+	return o.Palette.orDefault()
+}
+
+func buildHourly(summaries []api.HourlyCheckinSummary, option *ChartOption) *canvas {
+	p := option.palette()
+	size := option.size(240)
+	var counts [24]int
+	max := 0
+	for _, s := range summaries {
+		if s.Hour < 0 || s.Hour > 23 {
+			continue
+		}
+		counts[s.Hour] += s.Count
+		if counts[s.Hour] > max {
+			max = counts[s.Hour]
+		}
+	}
+
+	c := &canvas{width: int(size), height: int(size), background: p.Background}
+	cx, cy := size/2, size/2
+	inner := size * 0.12
+	outer := size/2 - 20
+	const gap = 0.02
+	for h := 0; h < 24; h++ {
+		from := float64(h)/24*2*math.Pi - math.Pi/2 + gap
+		to := float64(h+1)/24*2*math.Pi - math.Pi/2 - gap
+		c.add(polygon{points: wedge(cx, cy, inner, outer, from, to), fill: p.Empty})
+		if counts[h] > 0 {
+			r := inner + (outer-inner)*float64(counts[h])/float64(max)
+			c.add(polygon{
+				points: wedge(cx, cy, inner, r, from, to),
+				fill:   p.fill(counts[h], max),
+				title:  strconv.Itoa(h) + "時: " + strconv.Itoa(counts[h]),
+			})
+		}
+	}
+	for _, h := range []int{0, 6, 12, 18} {
+		a := float64(h)/24*2*math.Pi - math.Pi/2
+		c.add(text{
+			x:      cx + (outer+10)*math.Cos(a),
+			y:      cy + (outer+10)*math.Sin(a) + 4,
+			size:   10,
+			anchor: "middle",
+			fill:   p.Text,
+			value:  strconv.Itoa(h),
+		})
+	}
+	return c
+}
+
+// wedge は中心 (cx, cy) の扇環を from から to (ラジアン) まで近似する多角形を返す。
+func wedge(cx, cy, inner, outer, from, to float64) []point {
+	const segments = 6
+	pts := make([]point, 0, (segments+1)*2)
+	for i := 0; i <= segments; i++ {
+		a := from + (to-from)*float64(i)/segments
+		pts = append(pts, point{cx + outer*math.Cos(a), cy + outer*math.Sin(a)})
+	}
+	for i := segments; i >= 0; i-- {
+		a := from + (to-from)*float64(i)/segments
+		pts = append(pts, point{cx + inner*math.Cos(a), cy + inner*math.Sin(a)})
+	}
+	return pts
+}
+
+// HourlySVG は時間帯別チェックイン数を24分割の放射状チャートとして SVG で書き出す。
+func HourlySVG(w io.Writer, summaries []api.HourlyCheckinSummary, option *ChartOption) error {
+	return buildHourly(summaries, option).writeSVG(w)
+}
+
+func HourlyImage(summaries []api.HourlyCheckinSummary, option *ChartOption) image.Image {
+	return buildHourly(summaries, option).image()
+}
+
+func HourlyPNG(w io.Writer, summaries []api.HourlyCheckinSummary, option *ChartOption) error {
+	return buildHourly(summaries, option).writePNG(w)
+}
+
+func buildTagBars(tags []tissue.TagCount, option *ChartOption) *canvas {
+	p := option.palette()
+	width := option.size(480)
+	limit := 10
+	if option != nil && option.Limit > 0 {
+		limit = option.Limit
+	}
+	sorted := make([]tissue.TagCount, len(tags))
+	copy(sorted, tags)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Count > sorted[j].Count })
+	if len(sorted) > limit {
+		sorted = sorted[:limit]
+	}
+	max := 0
+	if len(sorted) > 0 {
+		max = sorted[0].Count
+	}
+
+	const barHeight, barGap, pad, labelWidth, countWidth = 18.0, 6.0, 8.0, 140.0, 40.0
+	c := &canvas{
+		width:      int(width),
+		height:     int(pad*2 + float64(len(sorted))*(barHeight+barGap)),
+		background: p.Background,
+	}
+	barMax := width - pad*2 - labelWidth - countWidth
+	for i, t := range sorted {
+		y := pad + float64(i)*(barHeight+barGap)
+		w := 0.0
+		if max > 0 {
+			w = barMax * float64(t.Count) / float64(max)
+		}
+		c.add(text{x: pad + labelWidth - 6, y: y + barHeight - 5, size: 12, anchor: "end", fill: p.Text, value: t.Name})
+		c.add(rect{x: pad + labelWidth, y: y, w: w, h: barHeight, radius: 2, fill: p.fill(t.Count, max), title: t.Name + ": " + strconv.Itoa(t.Count)})
+		c.add(text{x: pad + labelWidth + w + 4, y: y + barHeight - 5, size: 12, fill: p.Text, value: strconv.Itoa(t.Count)})
+	}
+	return c
+}
+
+// TagBarsSVG はタグ使用回数の上位を横棒グラフとして SVG で書き出す。
+func TagBarsSVG(w io.Writer, tags []tissue.TagCount, option *ChartOption) error {
+	return buildTagBars(tags, option).writeSVG(w)
+}
+
+func TagBarsImage(tags []tissue.TagCount, option *ChartOption) image.Image {
+	return buildTagBars(tags, option).image()
+}
+
+func TagBarsPNG(w io.Writer, tags []tissue.TagCount, option *ChartOption) error {
+	return buildTagBars(tags, option).writePNG(w)
+}
@@ -0,0 +1,301 @@
+// Package render はチェックイン統計を SVG / PNG の図として描画する。
+//
+// 各チャートはまず矩形・多角形・文字列からなる図形列として組み立てられ、
+// それを SVG 文字列または image.RGBA へ書き出す。
+// PNG (ラスタ) 出力は標準ライブラリのみで描画するため、文字列ラベルは含まれない。
+package render
+
+import (
+	"bufio"
+	"fmt"
+	"html"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Palette はチャートの配色。
+type Palette struct {
+	// Background は背景色。nil の場合は透過。
+	Background color.Color
+	// Empty はカウント 0 のセルの色。
+	Empty color.Color
+	// Levels はカウントの少ない順に並べた塗り色。
+	Levels []color.Color
+	// Text はラベルの色。
+	Text color.Color
+}
+
+// DefaultPalette は Tissue のプロフィールページに近い配色。
+var DefaultPalette = Palette{
+	Background: color.RGBA{0xff, 0xff, 0xff, 0xff},
+	Empty:      color.RGBA{0xeb, 0xed, 0xf0, 0xff},
+	Levels: []color.Color{
+		color.RGBA{0xfd, 0xd0, 0xe0, 0xff},
+		color.RGBA{0xf9, 0x8a, 0xb4, 0xff},
+		color.RGBA{0xe8, 0x4a, 0x8a, 0xff},
+		color.RGBA{0xb8, 0x1d, 0x62, 0xff},
+	},
+	Text: color.RGBA{0x57, 0x60, 0x6a, 0xff},
+}
+
+func (p *Palette) orDefault() Palette {
+	if p == nil {
+		return DefaultPalette
+	}
+	r := *p
+	if r.Empty == nil {
+		r.Empty = DefaultPalette.Empty
+	}
+	if len(r.Levels) == 0 {
+		r.Levels = DefaultPalette.Levels
+	}
+	if r.Text == nil {
+		r.Text = DefaultPalette.Text
+	}
+	return r
+}
+
+// level は count を max に対する割合で Levels のインデックスに変換する。0 件は -1。
+func (p Palette) level(count, max int) int {
+	if count <= 0 || max <= 0 {
+		return -1
+	}
+	n := len(p.Levels)
+	idx := int(math.Ceil(float64(count)/float64(max)*float64(n))) - 1
+	if idx < 0 {
+		idx = 0
+	}
+	if idx >= n {
+		idx = n - 1
+	}
+	return idx
+}
+
+func (p Palette) fill(count, max int) color.Color {
+	if idx := p.level(count, max); idx >= 0 {
+		return p.Levels[idx]
+	}
+	return p.Empty
+}
+
+// ParseHexColor は "#rgb" / "#rrggbb" / "#rrggbbaa" 形式の色を解釈する。先頭の # は省略可。
+func ParseHexColor(s string) (color.NRGBA, error) {
+	h := strings.TrimPrefix(strings.TrimSpace(s), "#")
+	if len(h) == 3 {
+		h = string([]byte{h[0], h[0], h[1], h[1], h[2], h[2]})
+	}
+	if len(h) == 6 {
+		h += "ff"
+	}
+	if len(h) != 8 {
+		return color.NRGBA{}, fmt.Errorf("invalid color: %q", s)
+	}
+	v, err := strconv.ParseUint(h, 16, 32)
+	if err != nil {
+		return color.NRGBA{}, fmt.Errorf("invalid color: %q", s)
+	}
+	return color.NRGBA{R: uint8(v >> 24), G: uint8(v >> 16), B: uint8(v >> 8), A: uint8(v)}, nil
+}
+
+// ParseHexColors はカンマ区切りの色リストを解釈する。
+func ParseHexColors(s string) ([]color.Color, error) {
+	var result []color.Color
+	for _, part := range strings.Split(s, ",") {
+		if strings.TrimSpace(part) == "" {
+			continue
+		}
+		c, err := ParseHexColor(part)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, c)
+	}
+	return result, nil
+}
+
+type point struct {
+	X, Y float64
+}
+
+type shape interface {
+	writeSVG(w *bufio.Writer)
+	draw(img *image.RGBA)
+}
+
+type canvas struct {
+	width, height int
+	background    color.Color
+	shapes        []shape
+}
+
+func (c *canvas) add(s shape) {
+	c.shapes = append(c.shapes, s)
+}
+
+func (c *canvas) writeSVG(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`+"\n",
+		c.width, c.height, c.width, c.height)
+	if c.background != nil {
+		fmt.Fprintf(bw, `<rect width="%d" height="%d" fill="%s"/>`+"\n", c.width, c.height, svgColor(c.background))
+	}
+	for _, s := range c.shapes {
+		s.writeSVG(bw)
+	}
+	fmt.Fprintln(bw, "</svg>")
+	return bw.Flush()
+}
+
+func (c *canvas) image() *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, c.width, c.height))
+	if c.background != nil {
+		fillRect(img, 0, 0, float64(c.width), float64(c.height), c.background)
+	}
+	for _, s := range c.shapes {
+		s.draw(img)
+	}
+	return img
+}
+
+func (c *canvas) writePNG(w io.Writer) error {
+	return png.Encode(w, c.image())
+}
+
+type rect struct {
+	x, y, w, h float64
+	radius     float64
+	fill       color.Color
+	title      string
+}
+
+func (r rect) writeSVG(w *bufio.Writer) {
+	fmt.Fprintf(w, `<rect x="%s" y="%s" width="%s" height="%s"`, num(r.x), num(r.y), num(r.w), num(r.h))
+	if r.radius > 0 {
+		fmt.Fprintf(w, ` rx="%s" ry="%s"`, num(r.radius), num(r.radius))
+	}
+	fmt.Fprintf(w, ` fill="%s"`, svgColor(r.fill))
+	if r.title != "" {
+		fmt.Fprintf(w, `><title>%s</title></rect>`+"\n", html.EscapeString(r.title))
+		return
+	}
+	fmt.Fprintln(w, `/>`)
+}
+
+func (r rect) draw(img *image.RGBA) {
+	fillRect(img, r.x, r.y, r.w, r.h, r.fill)
+}
+
+type polygon struct {
+	points []point
+	fill   color.Color
+	title  string
+}
+
+func (p polygon) writeSVG(w *bufio.Writer) {
+	pts := make([]string, len(p.points))
+	for i, pt := range p.points {
+		pts[i] = num(pt.X) + "," + num(pt.Y)
+	}
+	fmt.Fprintf(w, `<polygon points="%s" fill="%s"`, strings.Join(pts, " "), svgColor(p.fill))
+	if p.title != "" {
+		fmt.Fprintf(w, `><title>%s</title></polygon>`+"\n", html.EscapeString(p.title))
+		return
+	}
+	fmt.Fprintln(w, `/>`)
+}
+
+// draw は偶奇規則のスキャンライン塗りつぶしで多角形を描く。
+func (p polygon) draw(img *image.RGBA) {
+	if len(p.points) < 3 {
+		return
+	}
+	minY, maxY := math.Inf(1), math.Inf(-1)
+	for _, pt := range p.points {
+		minY = math.Min(minY, pt.Y)
+		maxY = math.Max(maxY, pt.Y)
+	}
+	b := img.Bounds()
+	y0 := int(math.Max(math.Floor(minY), float64(b.Min.Y)))
+	y1 := int(math.Min(math.Ceil(maxY), float64(b.Max.Y)))
+	for y := y0; y < y1; y++ {
+		sy := float64(y) + 0.5
+		var xs []float64
+		for i := range p.points {
+			a, c := p.points[i], p.points[(i+1)%len(p.points)]
+			if (a.Y <= sy && c.Y > sy) || (c.Y <= sy && a.Y > sy) {
+				xs = append(xs, a.X+(sy-a.Y)/(c.Y-a.Y)*(c.X-a.X))
+			}
+		}
+		sort.Float64s(xs)
+		for i := 0; i+1 < len(xs); i += 2 {
+			fillRect(img, xs[i], float64(y), xs[i+1]-xs[i], 1, p.fill)
+		}
+	}
+}
+
+type text struct {
+	x, y   float64
+	size   float64
+	anchor string
+	fill   color.Color
+	value  string
+}
+
+func (t text) writeSVG(w *bufio.Writer) {
+	anchor := t.anchor
+	if anchor == "" {
+		anchor = "start"
+	}
+	fmt.Fprintf(w, `<text x="%s" y="%s" font-size="%s" font-family="sans-serif" text-anchor="%s" fill="%s">%s</text>`+"\n",
+		num(t.x), num(t.y), num(t.size), anchor, svgColor(t.fill), html.EscapeString(t.value))
+}
+
+// draw は何もしない。ラスタ出力では文字列を描画しない。
+func (t text) draw(*image.RGBA) {}
+
+func fillRect(img *image.RGBA, x, y, w, h float64, c color.Color) {
+	r := image.Rect(int(math.Round(x)), int(math.Round(y)), int(math.Round(x+w)), int(math.Round(y+h))).Intersect(img.Bounds())
+	if r.Empty() {
+		return
+	}
+	src := color.RGBAModel.Convert(c).(color.RGBA)
+	for py := r.Min.Y; py < r.Max.Y; py++ {
+		for px := r.Min.X; px < r.Max.X; px++ {
+			img.SetRGBA(px, py, blend(img.RGBAAt(px, py), src))
+		}
+	}
+}
+
+func blend(dst, src color.RGBA) color.RGBA {
+	if src.A == 0xff {
+		return src
+	}
+	a := uint32(src.A)
+	mix := func(d, s uint8) uint8 {
+		return uint8((uint32(s)*0xff + uint32(d)*(0xff-a)) / 0xff)
+	}
+	return color.RGBA{
+		R: mix(dst.R, src.R),
+		G: mix(dst.G, src.G),
+		B: mix(dst.B, src.B),
+		A: uint8(a + uint32(dst.A)*(0xff-a)/0xff),
+	}
+}
+
+func svgColor(c color.Color) string {
+	rgba := color.NRGBAModel.Convert(c).(color.NRGBA)
+	if rgba.A == 0xff {
+		return fmt.Sprintf("#%02x%02x%02x", rgba.R, rgba.G, rgba.B)
+	}
+	return fmt.Sprintf("#%02x%02x%02x%02x", rgba.R, rgba.G, rgba.B, rgba.A)
+}
+
+func num(f float64) string {
+	return strconv.FormatFloat(math.Round(f*100)/100, 'f', -1, 64)
+}
@@ -0,0 +1,82 @@
+package render
+
+import (
+	"bytes"
+	"image/png"
+	"strings"
+	"testing"
+	"time"
+
+	tissue "github.com/mohemohe/go-tissue"
+	"github.com/mohemohe/go-tissue/api"
+)
+
+func TestCalendarSVG(t *testing.T) {
+	until := time.Date(2024, 5, 31, 0, 0, 0, 0, time.UTC)
+	since := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	counts := []tissue.DailyCheckinCount{
+		{Date: "2024-05-03", Count: 1},
+		{Date: "2024-05-10", Count: 4},
+		{Date: "2024-06-01", Count: 99},
+	}
+	buf := &bytes.Buffer{}
+	if err := CalendarSVG(buf, counts, &CalendarOption{Since: since, Until: until, Location: time.UTC}); err != nil {
+		t.Fatal(err)
+	}
+	svg := buf.String()
+	if got := strings.Count(svg, "<title>"); got != 31 {
+		t.Errorf("unexpected cell count: %d", got)
+	}
+	if !strings.Contains(svg, "2024-05-10: 4") {
+		t.Error("count title not rendered")
+	}
+	if strings.Contains(svg, "2024-06-01") {
+		t.Error("date out of range rendered")
+	}
+	if !strings.Contains(svg, svgColor(DefaultPalette.Levels[3])) {
+		t.Error("max level color not used")
+	}
+}
+
+func TestHourlyPNG(t *testing.T) {
+	buf := &bytes.Buffer{}
+	err := HourlyPNG(buf, []api.HourlyCheckinSummary{{Hour: 0, Count: 3}, {Hour: 23, Count: 1}}, &ChartOption{Size: 120})
+	if err != nil {
+		t.Fatal(err)
+	}
+	img, err := png.Decode(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b := img.Bounds(); b.Dx() != 120 || b.Dy() != 120 {
+		t.Errorf("unexpected size: %v", b)
+	}
+}
+
+func TestTagBarsSVG_Limit(t *testing.T) {
+	buf := &bytes.Buffer{}
+	tags := []tissue.TagCount{{Name: "a", Count: 1}, {Name: "b", Count: 5}, {Name: "<c>", Count: 3}}
+	if err := TagBarsSVG(buf, tags, &ChartOption{Limit: 2}); err != nil {
+		t.Fatal(err)
+	}
+	svg := buf.String()
+	if !strings.Contains(svg, "b: 5") || !strings.Contains(svg, "&lt;c&gt;: 3") {
+		t.Error("top tags not rendered")
+	}
+	if strings.Contains(svg, "a: 1") {
+		t.Error("limit not applied")
+	}
+}
+
+func TestParseHexColor(t *testing.T) {
+	c, err := ParseHexColor("#f0a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.R != 0xff || c.G != 0x00 || c.B != 0xaa || c.A != 0xff {
+		t.Errorf("unexpected color: %v", c)
+	}
+	if _, err := ParseHexColor("zzz"); err == nil {
+		t.Error("expected error")
+	}
+}
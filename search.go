@@ -29,5 +29,5 @@ func (c *Client) SearchCheckins(ctx context.Context, option *SearchCheckinsOptio
 	if err := c.getJSON(ctx, "/api/search/checkins", query, &result); err != nil {
 		return nil, err
 	}
-	return result, nil
+	return FilterCheckins(c.option.Filter, result), nil
 }
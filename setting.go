@@ -0,0 +1,126 @@
+package go_tissue
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// 設定ページ (/setting/...) には API が無いため、ログイン済みのセッションで HTML を取得し、フォームを送信して操作する。
+// ページの構造に依存するので、サイトの変更で動かなくなる可能性がある。
+
+// getPage は spath の HTML を取得する。
+func (c *Client) getPage(ctx context.Context, spath string) (string, error) {
+	res, err := c.doRequest(ctx, http.MethodGet, spath, nil, nil, "")
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+	return readPage(res)
+}
+
+// submitForm はフォームを送信し、リダイレクト先のページの HTML を返す。
+// 入力エラーは Laravel が XHR に対して返す 422 として StatusError になる。
+func (c *Client) submitForm(ctx context.Context, method, spath string, form url.Values) (string, error) {
+	var body io.Reader
+	contentType := ""
+	if form != nil {
+		body = strings.NewReader(form.Encode())
+		contentType = "application/x-www-form-urlencoded"
+	}
+	res, err := c.doRequest(ctx, method, spath, nil, body, contentType)
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+	return readPage(res)
+}
+
+func readPage(res *http.Response) (string, error) {
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return "", readErrorResponse(res)
+	}
+	b, err := io.ReadAll(res.Body)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+var dataNamePattern = regexp.MustCompile(`data-id="([^"]+)"[^>]*?data-name="([^"]*)"|data-name="([^"]*)"[^>]*?data-id="([^"]+)"`)
+
+// dataNames は一覧ページの削除ボタンなどが持つ data-id / data-name 属性を、ID から名前への対応として返す。
+func dataNames(page string) map[string]string {
+	names := map[string]string{}
+	for _, m := range dataNamePattern.FindAllStringSubmatch(page, -1) {
+		if m[1] != "" {
+			names[m[1]] = html.UnescapeString(m[2])
+		} else {
+			names[m[4]] = html.UnescapeString(m[3])
+		}
+	}
+	return names
+}
+
+var (
+	formPattern     = regexp.MustCompile(`(?s)<form\b([^>]*)>(.*?)</form>`)
+	inputPattern    = regexp.MustCompile(`(?s)<input\b([^>]*)>`)
+	textareaPattern = regexp.MustCompile(`(?s)<textarea\b([^>]*)>(.*?)</textarea>`)
+	attrPattern     = regexp.MustCompile(`([a-zA-Z_:-]+)(?:\s*=\s*"([^"]*)")?`)
+)
+
+func parseAttrs(s string) map[string]string {
+	attrs := map[string]string{}
+	for _, m := range attrPattern.FindAllStringSubmatch(s, -1) {
+		attrs[strings.ToLower(m[1])] = html.UnescapeString(m[2])
+	}
+	return attrs
+}
+
+// currentForm は page 中で action が spath で終わるフォームの、現在の入力値を返す。
+// チェックボックスは checked のものだけを含めるので、そのまま送信すれば設定を変えない。
+func currentForm(page, spath string) (url.Values, error) {
+	for _, m := range formPattern.FindAllStringSubmatch(page, -1) {
+		action := parseAttrs(m[1])["action"]
+		if u, err := url.Parse(action); err != nil || !strings.HasSuffix(u.Path, spath) {
+			continue
+		}
+		values := url.Values{}
+		for _, in := range inputPattern.FindAllStringSubmatch(m[2], -1) {
+			attrs := parseAttrs(in[1])
+			name := attrs["name"]
+			if name == "" {
+				continue
+			}
+			switch strings.ToLower(attrs["type"]) {
+			case "checkbox", "radio":
+				if _, checked := attrs["checked"]; checked {
+					values.Add(name, defaultValue(attrs, "on"))
+				}
+			case "submit", "button", "file":
+			default:
+				values.Add(name, attrs["value"])
+			}
+		}
+		for _, ta := range textareaPattern.FindAllStringSubmatch(m[2], -1) {
+			if name := parseAttrs(ta[1])["name"]; name != "" {
+				// ブラウザと同様に開始タグ直後の改行1つは値に含めない
+				values.Add(name, html.UnescapeString(strings.TrimPrefix(strings.TrimPrefix(ta[2], "\r"), "\n")))
+			}
+		}
+		return values, nil
+	}
+	return nil, fmt.Errorf("form for %s not found in the settings page", spath)
+}
+
+func defaultValue(attrs map[string]string, def string) string {
+	if v, ok := attrs["value"]; ok {
+		return v
+	}
+	return def
+}
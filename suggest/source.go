@@ -0,0 +1,75 @@
+package suggest
+
+import (
+	"context"
+	"fmt"
+
+	tissue "github.com/mohemohe/go-tissue"
+	"github.com/mohemohe/go-tissue/api"
+)
+
+// APIClient は *api.Client と *api.HybridClient が満たす、Source に必要な操作。
+type APIClient interface {
+	UserCheckins(ctx context.Context, name string, option *api.UserCheckinsOption) ([]tissue.Checkin, error)
+	UserTagStats(ctx context.Context, name string, option *api.UserStatsPeriodOption) ([]tissue.TagCount, error)
+	UserLinkStats(ctx context.Context, name string, option *api.UserStatsPeriodOption) ([]api.LinkCount, error)
+	RecentTags(ctx context.Context) ([]string, error)
+}
+
+// NewAPISource は API トークン版 (またはハイブリッド) のクライアントを Source にする。
+func NewAPISource(c APIClient) Source {
+	return &apiSource{c: c}
+}
+
+type apiSource struct {
+	c APIClient
+}
+
+func (s *apiSource) UserCheckins(ctx context.Context, name string, page, perPage int) ([]tissue.Checkin, error) {
+	return s.c.UserCheckins(ctx, name, &api.UserCheckinsOption{Page: page, PerPage: perPage, Unfiltered: true})
+}
+
+func (s *apiSource) UserTagStats(ctx context.Context, name string) ([]tissue.TagCount, error) {
+	return s.c.UserTagStats(ctx, name, nil)
+}
+
+func (s *apiSource) UserLinkStats(ctx context.Context, name string) ([]api.LinkCount, error) {
+	return s.c.UserLinkStats(ctx, name, nil)
+}
+
+func (s *apiSource) RecentTags(ctx context.Context) ([]string, error) {
+	return s.c.RecentTags(ctx)
+}
+
+// NewScrapingSource はスクレイピング版のクライアントを Source にする。リンクの統計は無いので使わない。
+func NewScrapingSource(c *tissue.Client) Source {
+	return &scrapingSource{c: c}
+}
+
+type scrapingSource struct {
+	c *tissue.Client
+}
+
+func (s *scrapingSource) UserCheckins(ctx context.Context, name string, page, perPage int) ([]tissue.Checkin, error) {
+	result, err := s.c.UserCheckins(ctx, name, &tissue.UserCheckinsOption{Page: page, PerPage: perPage, Unfiltered: true})
+	if err != nil {
+		return nil, err
+	}
+	checkins := make([]tissue.Checkin, len(result))
+	for i, c := range result {
+		checkins[i] = c.Checkin
+	}
+	return checkins, nil
+}
+
+func (s *scrapingSource) UserTagStats(ctx context.Context, name string) ([]tissue.TagCount, error) {
+	return s.c.UserTagStats(ctx, name)
+}
+
+func (s *scrapingSource) UserLinkStats(ctx context.Context, name string) ([]api.LinkCount, error) {
+	return nil, fmt.Errorf("%s is not supported by scraping client", tissue.OpLinkStats)
+}
+
+func (s *scrapingSource) RecentTags(ctx context.Context) ([]string, error) {
+	return s.c.RecentTags(ctx)
+}
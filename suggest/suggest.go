@@ -0,0 +1,331 @@
+// Package suggest はユーザー自身の履歴とリンク先のメタデータから、チェックインに付けるタグの候補を作る。
+package suggest
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	tissue "github.com/mohemohe/go-tissue"
+	"github.com/mohemohe/go-tissue/api"
+	"github.com/mohemohe/go-tissue/metadata"
+)
+
+// Source は候補の材料となるユーザーの履歴。NewAPISource / NewScrapingSource でクライアントから作る。
+type Source interface {
+	// UserCheckins は取り除かずにそのページの全件を返す。perPage 未満なら最後のページとみなす。
+	UserCheckins(ctx context.Context, name string, page, perPage int) ([]tissue.Checkin, error)
+	UserTagStats(ctx context.Context, name string) ([]tissue.TagCount, error)
+	UserLinkStats(ctx context.Context, name string) ([]api.LinkCount, error)
+	RecentTags(ctx context.Context) ([]string, error)
+}
+
+// 候補に付く理由。
+const (
+	ReasonLink     = "link"     // 同じリンクのチェックインで使った
+	ReasonDomain   = "domain"   // 同じドメインのチェックインで使った
+	ReasonMetadata = "metadata" // リンク先のメタデータのタグ
+	ReasonNote     = "note"     // ノートに含まれている
+	ReasonRecent   = "recent"   // 最近使用した
+	ReasonFrequent = "frequent" // よく使う
+)
+
+// 理由ごとの重み。link / domain は該当するチェックインのうちそのタグを使った割合を掛ける。
+const (
+	weightLink     = 5.0
+	weightDomain   = 2.0
+	weightMetadata = 3.0
+	weightKnown    = 1.0 // メタデータのタグを過去にも使っている
+	weightNote     = 2.0
+	weightRecent   = 0.5
+	weightFrequent = 1.0 // 最も多く使ったタグで 1、使用回数に比例
+)
+
+const (
+	DefaultPages    = 3
+	DefaultMaxPages = 10
+	DefaultPerPage  = 100
+	DefaultLimit    = 10
+)
+
+type Suggestion struct {
+	Tag     string   `json:"tag"`
+	Score   float64  `json:"score"`
+	Reasons []string `json:"reasons"`
+}
+
+// Suggester は SuggestTags で候補を作る。Source と User は必須。
+type Suggester struct {
+	Source Source
+	// User は履歴を参照するユーザー名。
+	User string
+	// Resolver はリンク先のメタデータの取得に使う。nil ならメタデータを使わない。
+	Resolver metadata.Resolver
+	// Pages は同じリンク・ドメインを探すチェックインのページ数。0 なら DefaultPages。
+	Pages int
+	// MaxPages は UserLinkStats にリンクがあるのに見つからないとき、遡るページ数の上限。0 なら DefaultMaxPages。
+	MaxPages int
+	// PerPage は1ページ当たりの件数。0 なら DefaultPerPage。
+	PerPage int
+	// Limit は返す候補の上限。0 なら DefaultLimit、負なら無制限。
+	Limit int
+}
+
+// SuggestTags は link / note に付けるタグの候補をスコアの高い順に返す。
+// 履歴のチェックインの取得に失敗したときだけエラーを返し、その他の材料 (統計・最近のタグ・メタデータ) は取得できた分だけ使う。
+func (s *Suggester) SuggestTags(ctx context.Context, link, note string) ([]Suggestion, error) {
+	if s.Source == nil || s.User == "" {
+		return nil, errors.New("source and user are required")
+	}
+	b := newBuilder()
+
+	stats, _ := s.Source.UserTagStats(ctx, s.User)
+	recent, _ := s.Source.RecentTags(ctx)
+	for _, t := range stats {
+		b.know(t.Name)
+	}
+	for _, t := range recent {
+		b.know(t)
+	}
+
+	if link != "" {
+		if err := s.addHistory(ctx, b, link); err != nil {
+			return nil, err
+		}
+		if s.Resolver != nil {
+			if meta, err := s.Resolver.Resolve(ctx, link); err == nil {
+				for _, t := range meta.Tags {
+					known := b.known(t)
+					b.add(t, weightMetadata, ReasonMetadata)
+					if known {
+						b.add(t, weightKnown, ReasonMetadata)
+					}
+				}
+			}
+		}
+	}
+	if note != "" {
+		b.addNote(note)
+	}
+
+	for _, t := range recent {
+		b.add(t, weightRecent, ReasonRecent)
+	}
+	max := 0
+	for _, t := range stats {
+		if t.Count > max {
+			max = t.Count
+		}
+	}
+	for _, t := range stats {
+		if max > 0 && b.has(t.Name) {
+			b.add(t.Name, weightFrequent*float64(t.Count)/float64(max), ReasonFrequent)
+		}
+	}
+
+	limit := s.Limit
+	if limit == 0 {
+		limit = DefaultLimit
+	}
+	return b.result(limit), nil
+}
+
+// addHistory は同じリンク・ドメインの過去のチェックインで使ったタグを加える。
+func (s *Suggester) addHistory(ctx context.Context, b *builder, link string) error {
+	target, ok := linkKey(link)
+	if !ok {
+		return nil
+	}
+	pages := s.Pages
+	if pages == 0 {
+		pages = DefaultPages
+	}
+	// 同じリンクを使ったことがあるなら、見つかるまで MaxPages まで遡る
+	maxPages := pages
+	if s.linkUsed(ctx, target) {
+		maxPages = s.MaxPages
+		if maxPages == 0 {
+			maxPages = DefaultMaxPages
+		}
+	}
+	perPage := s.PerPage
+	if perPage == 0 {
+		perPage = DefaultPerPage
+	}
+
+	var sameLink, sameDomain []tissue.Checkin
+	for page := 1; page <= pages || page <= maxPages; page++ {
+		checkins, err := s.Source.UserCheckins(ctx, s.User, page, perPage)
+		if err != nil {
+			return err
+		}
+		for _, c := range checkins {
+			key, ok := linkKey(c.Link)
+			switch {
+			case !ok:
+			case key == target:
+				sameLink = append(sameLink, c)
+			case key.host == target.host:
+				sameDomain = append(sameDomain, c)
+			}
+		}
+		if len(checkins) < perPage || (page >= pages && len(sameLink) > 0) {
+			break
+		}
+	}
+	b.addShare(sameLink, weightLink, ReasonLink)
+	b.addShare(sameDomain, weightDomain, ReasonDomain)
+	return nil
+}
+
+// linkUsed は UserLinkStats に target のリンクがあるかを返す。取得できなければ false。
+func (s *Suggester) linkUsed(ctx context.Context, target linkID) bool {
+	links, err := s.Source.UserLinkStats(ctx, s.User)
+	if err != nil {
+		return false
+	}
+	for _, l := range links {
+		if key, ok := linkKey(l.Link); ok && key == target {
+			return true
+		}
+	}
+	return false
+}
+
+type linkID struct {
+	host string
+	path string
+}
+
+// linkKey はスキーム・www.・末尾の / ・フラグメントの違いを無視してリンクを比較するためのキーを返す。
+func linkKey(link string) (linkID, bool) {
+	u, err := url.Parse(strings.TrimSpace(link))
+	if err != nil || u.Host == "" {
+		return linkID{}, false
+	}
+	host := strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+	p := strings.TrimSuffix(u.EscapedPath(), "/")
+	if u.RawQuery != "" {
+		p += "?" + u.RawQuery
+	}
+	return linkID{host: host, path: p}, true
+}
+
+var hashtagPattern = regexp.MustCompile(`[#＃]([^\s#＃]+)`)
+
+type candidate struct {
+	tag     string
+	score   float64
+	reasons []string
+}
+
+// builder は大文字小文字を区別せずに候補を集める。表記はユーザーが過去に使ったものを優先する。
+type builder struct {
+	spelling   map[string]string
+	candidates map[string]*candidate
+}
+
+func newBuilder() *builder {
+	return &builder{spelling: map[string]string{}, candidates: map[string]*candidate{}}
+}
+
+func (b *builder) know(tag string) {
+	key := strings.ToLower(tag)
+	if _, ok := b.spelling[key]; !ok && tag != "" {
+		b.spelling[key] = tag
+	}
+}
+
+func (b *builder) known(tag string) bool {
+	_, ok := b.spelling[strings.ToLower(tag)]
+	return ok
+}
+
+func (b *builder) has(tag string) bool {
+	_, ok := b.candidates[strings.ToLower(tag)]
+	return ok
+}
+
+func (b *builder) add(tag string, score float64, reason string) {
+	tag = strings.TrimSpace(tag)
+	if tag == "" {
+		return
+	}
+	key := strings.ToLower(tag)
+	c, ok := b.candidates[key]
+	if !ok {
+		if s, ok := b.spelling[key]; ok {
+			tag = s
+		}
+		c = &candidate{tag: tag}
+		b.candidates[key] = c
+	}
+	c.score += score
+	for _, r := range c.reasons {
+		if r == reason {
+			return
+		}
+	}
+	c.reasons = append(c.reasons, reason)
+}
+
+// addShare は checkins のうち各タグを使ったものの割合に weight を掛けて加える。
+func (b *builder) addShare(checkins []tissue.Checkin, weight float64, reason string) {
+	if len(checkins) == 0 {
+		return
+	}
+	counts := map[string]int{}
+	var order []string
+	for _, c := range checkins {
+		seen := map[string]bool{}
+		for _, t := range c.Tags {
+			key := strings.ToLower(t)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			b.know(t)
+			if counts[key] == 0 {
+				order = append(order, t)
+			}
+			counts[key]++
+		}
+	}
+	for _, t := range order {
+		b.add(t, weight*float64(counts[strings.ToLower(t)])/float64(len(checkins)), reason)
+	}
+}
+
+// addNote はノート中のハッシュタグと、ノートに含まれる既知のタグを加える。
+func (b *builder) addNote(note string) {
+	for _, m := range hashtagPattern.FindAllStringSubmatch(note, -1) {
+		b.add(m[1], weightNote, ReasonNote)
+	}
+	lower := strings.ToLower(note)
+	for key, tag := range b.spelling {
+		if utf8.RuneCountInString(key) >= 2 && strings.Contains(lower, key) {
+			b.add(tag, weightNote, ReasonNote)
+		}
+	}
+}
+
+func (b *builder) result(limit int) []Suggestion {
+	result := make([]Suggestion, 0, len(b.candidates))
+	for _, c := range b.candidates {
+		result = append(result, Suggestion{Tag: c.tag, Score: c.score, Reasons: c.reasons})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Score != result[j].Score {
+			return result[i].Score > result[j].Score
+		}
+		return result[i].Tag < result[j].Tag
+	})
+	if limit > 0 && len(result) > limit {
+		result = result[:limit]
+	}
+	return result
+}
@@ -0,0 +1,146 @@
+package suggest
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	tissue "github.com/mohemohe/go-tissue"
+	"github.com/mohemohe/go-tissue/api"
+	"github.com/mohemohe/go-tissue/metadata"
+)
+
+type fakeSource struct {
+	checkins  []tissue.Checkin
+	stats     []tissue.TagCount
+	links     []api.LinkCount
+	recent    []string
+	pages     []int
+	failStats bool
+}
+
+func (s *fakeSource) UserCheckins(ctx context.Context, name string, page, perPage int) ([]tissue.Checkin, error) {
+	s.pages = append(s.pages, page)
+	start := (page - 1) * perPage
+	if start >= len(s.checkins) {
+		return nil, nil
+	}
+	end := start + perPage
+	if end > len(s.checkins) {
+		end = len(s.checkins)
+	}
+	return s.checkins[start:end], nil
+}
+
+func (s *fakeSource) UserTagStats(ctx context.Context, name string) ([]tissue.TagCount, error) {
+	if s.failStats {
+		return nil, errors.New("stats unavailable")
+	}
+	return s.stats, nil
+}
+
+func (s *fakeSource) UserLinkStats(ctx context.Context, name string) ([]api.LinkCount, error) {
+	return s.links, nil
+}
+
+func (s *fakeSource) RecentTags(ctx context.Context) ([]string, error) {
+	return s.recent, nil
+}
+
+type fakeResolver map[string]*metadata.Metadata
+
+func (r fakeResolver) Resolve(ctx context.Context, link string) (*metadata.Metadata, error) {
+	if m, ok := r[link]; ok {
+		return m, nil
+	}
+	return nil, metadata.ErrUnsupported
+}
+
+func tags(result []Suggestion) []string {
+	var t []string
+	for _, s := range result {
+		t = append(t, s.Tag)
+	}
+	return t
+}
+
+func TestSuggestTags_Ranking(t *testing.T) {
+	src := &fakeSource{
+		checkins: []tissue.Checkin{
+			{Link: "https://www.example.com/works/1/", Tags: []string{"Voice", "ASMR"}},
+			{Link: "http://example.com/works/1#top", Tags: []string{"voice"}},
+			{Link: "https://example.com/works/2", Tags: []string{"Manga"}},
+			{Link: "https://other.example/", Tags: []string{"Other"}},
+		},
+		stats:  []tissue.TagCount{{Name: "Voice", Count: 10}, {Name: "Other", Count: 5}, {Name: "癒し", Count: 2}},
+		recent: []string{"Recent"},
+	}
+	resolver := fakeResolver{"https://example.com/works/1": {Tags: []string{"voice", "New"}}}
+	s := &Suggester{Source: src, User: "me", Resolver: resolver, Limit: -1}
+	result, err := s.SuggestTags(context.Background(), "https://example.com/works/1", "今日は #夜 癒しが欲しい")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"Voice", "New", "ASMR", "癒し", "Manga", "夜", "Recent"}
+	if got := tags(result); !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v, want %v (%+v)", got, want, result)
+	}
+	if !reflect.DeepEqual(result[0].Reasons, []string{ReasonLink, ReasonMetadata, ReasonFrequent}) {
+		t.Fatalf("unexpected reasons: %v", result[0].Reasons)
+	}
+	// Voice: link 5 + metadata 3 + known 1 + frequent 1
+	if result[0].Score != 10 {
+		t.Fatalf("unexpected score: %v", result[0].Score)
+	}
+}
+
+func TestSuggestTags_Limit(t *testing.T) {
+	src := &fakeSource{recent: []string{"a", "b", "c"}}
+	result, err := (&Suggester{Source: src, User: "me", Limit: 2}).SuggestTags(context.Background(), "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := tags(result); !reflect.DeepEqual(got, []string{"a", "b"}) {
+		t.Fatalf("unexpected result: %v", got)
+	}
+}
+
+func TestSuggestTags_ScansDeeperForUsedLink(t *testing.T) {
+	var checkins []tissue.Checkin
+	for i := 0; i < 5; i++ {
+		checkins = append(checkins, tissue.Checkin{Link: "https://example.com/other", Tags: []string{"x"}})
+	}
+	checkins = append(checkins, tissue.Checkin{Link: "https://example.com/target", Tags: []string{"old"}})
+
+	src := &fakeSource{checkins: checkins}
+	s := &Suggester{Source: src, User: "me", Pages: 2, PerPage: 2}
+	result, _ := s.SuggestTags(context.Background(), "https://example.com/target", "")
+	if len(src.pages) != 2 || len(result) != 1 || result[0].Tag != "x" {
+		t.Fatalf("expected only the first 2 pages to be scanned: pages=%v result=%v", src.pages, tags(result))
+	}
+
+	src = &fakeSource{checkins: checkins, links: []api.LinkCount{{Link: "https://example.com/target", Count: 1}}}
+	s = &Suggester{Source: src, User: "me", Pages: 2, PerPage: 2}
+	result, _ = s.SuggestTags(context.Background(), "https://example.com/target", "")
+	if len(src.pages) != 3 || result[0].Tag != "old" {
+		t.Fatalf("expected to scan until the link is found: pages=%v result=%v", src.pages, tags(result))
+	}
+}
+
+func TestSuggestTags_ToleratesAuxiliaryErrors(t *testing.T) {
+	src := &fakeSource{
+		checkins:  []tissue.Checkin{{Link: "https://example.com/", Tags: []string{"a"}}},
+		failStats: true,
+	}
+	result, err := (&Suggester{Source: src, User: "me", Resolver: fakeResolver{}}).SuggestTags(context.Background(), "https://example.com/", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := tags(result); !reflect.DeepEqual(got, []string{"a"}) {
+		t.Fatalf("unexpected result: %v", got)
+	}
+	if _, err := (&Suggester{Source: src}).SuggestTags(context.Background(), "", ""); err == nil {
+		t.Fatal("expected error without user")
+	}
+}
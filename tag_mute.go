@@ -0,0 +1,77 @@
+package go_tissue
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// TagMute はサイトの設定で登録したタグのミュート。該当するチェックインはサイト上で IsMuted になる。
+type TagMute struct {
+	ID  string `json:"id"`
+	Tag string `json:"tag"`
+}
+
+var tagMuteIDPattern = regexp.MustCompile(`action="[^"]*/setting/filter/tags/([0-9]+)"`)
+
+// ListTagMutes は設定ページからタグのミュートの一覧を取得する。
+func (c *Client) ListTagMutes(ctx context.Context) ([]TagMute, error) {
+	page, err := c.getPage(ctx, "/setting/filter/tags")
+	if err != nil {
+		return nil, err
+	}
+	return parseTagMutes(page), nil
+}
+
+// MuteTag はタグをミュートに登録して返す。登録済みであれば既存のものを返す。
+func (c *Client) MuteTag(ctx context.Context, tag string) (*TagMute, error) {
+	tag = strings.TrimSpace(tag)
+	if tag == "" {
+		return nil, errors.New("tag is required")
+	}
+	mutes, err := c.ListTagMutes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range mutes {
+		if strings.EqualFold(m.Tag, tag) {
+			return &m, nil
+		}
+	}
+	page, err := c.submitForm(ctx, http.MethodPost, "/setting/filter/tags", url.Values{"tag_name": {tag}})
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range parseTagMutes(page) {
+		if strings.EqualFold(m.Tag, tag) {
+			return &m, nil
+		}
+	}
+	return nil, errors.New("registered tag mute not found in the settings page")
+}
+
+// UnmuteTag はタグのミュートを解除する。
+func (c *Client) UnmuteTag(ctx context.Context, id string) error {
+	if id == "" {
+		return errors.New("id is required")
+	}
+	_, err := c.submitForm(ctx, http.MethodDelete, "/setting/filter/tags/"+url.PathEscape(id), nil)
+	return err
+}
+
+func parseTagMutes(page string) []TagMute {
+	names := dataNames(page)
+	mutes := []TagMute{}
+	seen := map[string]bool{}
+	for _, m := range tagMuteIDPattern.FindAllStringSubmatch(page, -1) {
+		if seen[m[1]] {
+			continue
+		}
+		seen[m[1]] = true
+		mutes = append(mutes, TagMute{ID: m[1], Tag: names[m[1]]})
+	}
+	return mutes
+}
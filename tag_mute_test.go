@@ -0,0 +1,81 @@
+package go_tissue
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// serveTagMutes はタグのミュート設定画面を f に足す。解除したものは空文字列にして ID を詰めない。
+func serveTagMutes(f *fakeTissue) {
+	var mu sync.Mutex
+	var mutes []string
+	f.handle("/setting/filter/tags", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if r.Method == http.MethodPost {
+			mutes = append(mutes, r.FormValue("tag_name"))
+			http.Redirect(w, r, "/setting/filter/tags", http.StatusFound)
+			return
+		}
+		for i, tag := range mutes {
+			if tag != "" {
+				fmt.Fprintf(w, `<form action="/setting/filter/tags/%d" method="POST"><button data-id="%d" data-name="%s">解除</button></form>`,
+					i+1, i+1, html.EscapeString(tag))
+			}
+		}
+	})
+	f.handle("/setting/filter/tags/", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		id, _ := strconv.Atoi(strings.TrimPrefix(r.URL.Path, "/setting/filter/tags/"))
+		if r.Method != http.MethodDelete || id < 1 || id > len(mutes) || mutes[id-1] == "" {
+			http.NotFound(w, r)
+			return
+		}
+		mutes[id-1] = ""
+		http.Redirect(w, r, "/setting/filter/tags", http.StatusFound)
+	})
+}
+
+func TestClient_TagMutes(t *testing.T) {
+	f := newFakeTissue(t)
+	serveTagMutes(f)
+	client := newFakeClient(t, f, "secret")
+	ctx := context.Background()
+
+	first, err := client.MuteTag(ctx, "NTR")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.ID != "1" || first.Tag != "NTR" {
+		t.Errorf("unexpected mute: %+v", first)
+	}
+	// 登録済みのタグは送信せずに既存のものを返す
+	again, err := client.MuteTag(ctx, "ntr")
+	if err != nil || *again != *first {
+		t.Errorf("expected existing mute: %+v %v", again, err)
+	}
+	if _, err := client.MuteTag(ctx, "グロ & 注意"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := client.UnmuteTag(ctx, first.ID); err != nil {
+		t.Fatal(err)
+	}
+	mutes, err := client.ListTagMutes(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mutes) != 1 || mutes[0] != (TagMute{ID: "2", Tag: "グロ & 注意"}) {
+		t.Errorf("unexpected mutes: %+v", mutes)
+	}
+	if err := client.UnmuteTag(ctx, first.ID); !IsNotFound(err) {
+		t.Errorf("expected not found, got %v", err)
+	}
+}
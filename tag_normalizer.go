@@ -0,0 +1,27 @@
+package go_tissue
+
+// TagNormalizer は送信前にタグの表記を揃える。ClientOption.TagNormalizer に指定すると、
+// チェックインとコレクションアイテムの作成・更新で送るタグに適用される。実装は tagrule パッケージにある。
+type TagNormalizer interface {
+	NormalizeTags(tags []string) ([]string, error)
+}
+
+// NormalizeTags は n で tags を揃える。n が nil か tags が空ならそのまま返す。
+func NormalizeTags(n TagNormalizer, tags []string) ([]string, error) {
+	if n == nil || len(tags) == 0 {
+		return tags, nil
+	}
+	return n.NormalizeTags(tags)
+}
+
+// NormalizeTagsPtr は更新用の *[]string に NormalizeTags を適用する。nil はそのまま返す。
+func NormalizeTagsPtr(n TagNormalizer, tags *[]string) (*[]string, error) {
+	if n == nil || tags == nil {
+		return tags, nil
+	}
+	result, err := NormalizeTags(n, *tags)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
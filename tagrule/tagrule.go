@@ -0,0 +1,153 @@
+// Package tagrule はタグの表記ゆれ (別名・全角半角・大文字小文字) を揃えるルール。
+//
+// Rules は tissue.TagNormalizer を実装しているので、各クライアントの ClientOption.TagNormalizer に指定すると
+// チェックイン・コレクションアイテムの作成・更新で送るタグに適用される。
+package tagrule
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// MaxTagLength は API 仕様上のタグの最大文字数。
+const MaxTagLength = 255
+
+// Rules はタグを揃えるルール。LoadFile で JSON ファイルから読み込める。
+type Rules struct {
+	// Aliases は別名から正規の表記への対応。キーは他のルールで正規化してから比較する。
+	Aliases map[string]string `json:"aliases,omitempty"`
+	// NFKC は Unicode 正規化形式 KC にする (全角英数字 → 半角、半角カナ → 全角、① → 1、㍻ → 平成 など)。
+	NFKC bool `json:"nfkc,omitempty"`
+	// LowercaseASCII は ASCII の英字を小文字にする。
+	LowercaseASCII bool `json:"lowercase_ascii,omitempty"`
+	// Banned は付けないタグ。正規化後に比較して取り除く。
+	Banned []string `json:"banned,omitempty"`
+	// MaxLength はタグの最大文字数。0 なら MaxTagLength。
+	MaxLength int `json:"max_length,omitempty"`
+
+	once    sync.Once
+	aliases map[string]string
+	banned  map[string]bool
+}
+
+// LoadFile は path の JSON ファイルからルールを読み込む。
+func LoadFile(path string) (*Rules, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	r := &Rules{}
+	if err := json.Unmarshal(b, r); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return r, nil
+}
+
+// index は Aliases / Banned のキーを正規化した索引を作る。初回の利用時に一度だけ作るので、その後にフィールドを変えても反映されない。
+func (r *Rules) index() {
+	r.once.Do(func() {
+		r.aliases = map[string]string{}
+		for from, to := range r.Aliases {
+			r.aliases[r.fold(from)] = to
+		}
+		r.banned = map[string]bool{}
+		for _, t := range r.Banned {
+			r.banned[r.fold(t)] = true
+		}
+	})
+}
+
+func (r *Rules) fold(tag string) string {
+	tag = strings.TrimSpace(tag)
+	if r.NFKC {
+		tag = strings.TrimSpace(norm.NFKC.String(tag))
+	}
+	if r.LowercaseASCII {
+		tag = strings.Map(func(c rune) rune {
+			if c >= 'A' && c <= 'Z' {
+				return c + ('a' - 'A')
+			}
+			return c
+		}, tag)
+	}
+	return tag
+}
+
+// Normalize は tag にルールを適用した表記を返す。禁止タグかどうかは判定しない。
+func (r *Rules) Normalize(tag string) string {
+	r.index()
+	tag = r.fold(tag)
+	if to, ok := r.aliases[tag]; ok {
+		return to
+	}
+	return tag
+}
+
+func (r *Rules) isBanned(tag string) bool {
+	r.index()
+	return r.banned[r.fold(tag)]
+}
+
+func (r *Rules) maxLength() int {
+	if r.MaxLength > 0 {
+		return r.MaxLength
+	}
+	return MaxTagLength
+}
+
+// NormalizeTags は tags を正規化し、空・禁止・重複したタグを取り除く。最大文字数を超えるタグがあればエラーを返す。
+func (r *Rules) NormalizeTags(tags []string) ([]string, error) {
+	result := make([]string, 0, len(tags))
+	seen := map[string]bool{}
+	for _, t := range tags {
+		n := r.Normalize(t)
+		if n == "" || r.isBanned(n) || seen[n] {
+			continue
+		}
+		if utf8.RuneCountInString(n) > r.maxLength() {
+			return nil, fmt.Errorf("tag too long (max %d characters): %s", r.maxLength(), n)
+		}
+		seen[n] = true
+		result = append(result, n)
+	}
+	return result, nil
+}
+
+// Issue の種類。
+const (
+	IssueNormalize = "normalize" // 全角半角・大文字小文字が揃っていない
+	IssueAlias     = "alias"     // 別名
+	IssueBanned    = "banned"    // 禁止タグ
+	IssueTooLong   = "too_long"  // 最大文字数を超えている
+)
+
+// Issue はルールに合わないタグ。
+type Issue struct {
+	Tag  string `json:"tag"`
+	Kind string `json:"kind"`
+	// Suggested は Kind が normalize / alias のときの正しい表記。
+	Suggested string `json:"suggested,omitempty"`
+}
+
+// Check は tag がルールに合わなければ Issue を返す。合っていれば nil。
+func (r *Rules) Check(tag string) *Issue {
+	r.index()
+	folded := r.fold(tag)
+	switch {
+	case r.banned[folded]:
+		return &Issue{Tag: tag, Kind: IssueBanned}
+	case r.aliases[folded] != "" && r.aliases[folded] != tag:
+		return &Issue{Tag: tag, Kind: IssueAlias, Suggested: r.aliases[folded]}
+	case folded != tag && r.aliases[folded] == "":
+		return &Issue{Tag: tag, Kind: IssueNormalize, Suggested: folded}
+	case utf8.RuneCountInString(tag) > r.maxLength():
+		return &Issue{Tag: tag, Kind: IssueTooLong}
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package tagrule
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func testRules() *Rules {
+	return &Rules{
+		Aliases:        map[string]string{"きょにゅう": "巨乳", "ｂｉｇ": "巨乳"},
+		NFKC:           true,
+		LowercaseASCII: true,
+		Banned:         []string{"spam"},
+	}
+}
+
+func TestRules_NFKC(t *testing.T) {
+	r := &Rules{NFKC: true}
+	cases := map[string]string{
+		"ＡＳＭＲ１２３！":  "ASMR123!",
+		"ｶﾞﾝﾀﾞﾑ":    "ガンダム",
+		"ﾊﾟﾊﾟ":      "パパ",
+		"ｳﾞｧｲｵﾘﾝ":   "ヴァイオリン",
+		"ｱﾞ":        "ア゙",
+		"全角　空白":     "全角 空白",
+		"ひらがなは変えない": "ひらがなは変えない",
+		"①":         "1",
+		"㍻":         "平成",
+		"ﬁnal":      "final",
+	}
+	for in, want := range cases {
+		if got := r.Normalize(in); got != want {
+			t.Errorf("Normalize(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestRules_NormalizeTags(t *testing.T) {
+	r := testRules()
+	got, err := r.NormalizeTags([]string{" ＡＳＭＲ ", "asmr", "きょにゅう", "巨乳", "BIG", "Spam", "", "ｶﾞﾝﾀﾞﾑ"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"asmr", "巨乳", "ガンダム"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+
+	r = &Rules{MaxLength: 3}
+	if _, err := r.NormalizeTags([]string{"あいうえ"}); err == nil {
+		t.Fatal("expected error for too long tag")
+	}
+}
+
+func TestRules_Check(t *testing.T) {
+	r := testRules()
+	r.MaxLength = 5
+	cases := []struct {
+		tag  string
+		want *Issue
+	}{
+		{"巨乳", nil},
+		{"asmr", nil},
+		{"きょにゅう", &Issue{Tag: "きょにゅう", Kind: IssueAlias, Suggested: "巨乳"}},
+		{"ASMR", &Issue{Tag: "ASMR", Kind: IssueNormalize, Suggested: "asmr"}},
+		{"spam", &Issue{Tag: "spam", Kind: IssueBanned}},
+		{"toolongtag", &Issue{Tag: "toolongtag", Kind: IssueTooLong}},
+	}
+	for _, c := range cases {
+		if got := r.Check(c.tag); !reflect.DeepEqual(got, c.want) {
+			t.Errorf("Check(%q) = %+v, want %+v", c.tag, got, c.want)
+		}
+	}
+}
+
+func TestLoadFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tag-rules.json")
+	if err := os.WriteFile(path, []byte(`{"aliases":{"きょにゅう":"巨乳"},"nfkc":true,"banned":["x"]}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	r, err := LoadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !r.NFKC || r.Normalize("きょにゅう") != "巨乳" {
+		t.Fatalf("unexpected rules: %+v", r)
+	}
+}
@@ -0,0 +1,235 @@
+// Package timeexpr は CLI の --at / --since / --until に指定する日時の表現を解釈する。
+//
+// now や -30m のような瞬間を指すものと、yesterday や 2024-Q1 のような期間を指すものがあり、どちらも Range で返す。
+// --since には Start を、--until には End を使えば、期間を指す表現はその期間全体を含む。
+package timeexpr
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Range は表現が指す期間。瞬間を指す表現では Start と End が等しい。
+type Range struct {
+	Start time.Time
+	// End は期間の最後の時刻 (含む)。
+	End time.Time
+}
+
+func instant(t time.Time) Range {
+	return Range{Start: t, End: t}
+}
+
+// period は start から next の直前までの Range を返す。
+func period(start, next time.Time) Range {
+	return Range{Start: start, End: next.Add(-time.Nanosecond)}
+}
+
+var (
+	clockPattern    = regexp.MustCompile(`^(\d{1,2}):(\d{2})(?::(\d{2}))?$`)
+	quarterPattern  = regexp.MustCompile(`^(\d{4})-?[qQ]([1-4])$`)
+	offsetPattern   = regexp.MustCompile(`^([+-])((?:\d+[smhdw])+)$`)
+	offsetPart      = regexp.MustCompile(`(\d+)([smhdw])`)
+	agoPattern      = regexp.MustCompile(`^(\d+)\s*([a-z]+)\s+ago$`)
+	relativePattern = regexp.MustCompile(`^(last|this|next)\s+(week|month|quarter|year)$`)
+)
+
+// 日時として受け付ける形式。タイムゾーンの無いものは Parse の loc で解釈する。
+var (
+	zonedLayouts = []string{time.RFC3339Nano, "2006-01-02T15:04:05-0700", "2006-01-02 15:04:05-0700"}
+	localLayouts = []string{"2006-01-02T15:04:05", "2006-01-02T15:04", "2006-01-02 15:04:05", "2006-01-02 15:04",
+		"2006/01/02 15:04:05", "2006/01/02 15:04"}
+)
+
+// agoUnits は "30 minutes ago" の単位。
+var agoUnits = map[string]string{
+	"s": "s", "sec": "s", "secs": "s", "second": "s", "seconds": "s",
+	"m": "m", "min": "m", "mins": "m", "minute": "m", "minutes": "m",
+	"h": "h", "hour": "h", "hours": "h",
+	"d": "d", "day": "d", "days": "d",
+	"w": "w", "week": "w", "weeks": "w",
+	"month": "mo", "months": "mo",
+	"y": "y", "year": "y", "years": "y",
+}
+
+// aliases は日本語の表現を英語の表現に置き換える。
+var aliases = map[string]string{
+	"今": "now", "今日": "today", "昨日": "yesterday", "明日": "tomorrow",
+	"今週": "this week", "先週": "last week", "今月": "this month", "先月": "last month",
+	"今年": "this year", "去年": "last year", "昨年": "last year",
+}
+
+// Parse は s を now を基準に解釈する。日付の境界と、タイムゾーンの無い日時には loc (nil なら time.Local) を使う。
+//
+// 受け付ける表現:
+//   - now / today / yesterday / tomorrow (日付の後に 23:10 のような時刻を付けるとその瞬間)
+//   - 23:10 (今日のその時刻)
+//   - -30m / -1h30m / -2d / +1w (s / m / h / d / w の組み合わせ), 30 minutes ago / 2 days ago
+//   - last week / this month / next year など (週は月曜始まり, quarter も可)
+//   - 2024 / 2024-05 / 2024-05-01 / 2024-Q1 (その期間), 2024-05-01 23:10 (その瞬間)
+//   - RFC3339 と 2006-01-02T15:04:05+0900
+//   - 今日 / 昨日 / 今週 / 先週 / 今月 / 先月 / 今年 / 去年
+func Parse(s string, now time.Time, loc *time.Location) (Range, error) {
+	if loc == nil {
+		loc = time.Local
+	}
+	now = now.In(loc)
+	expr := strings.ToLower(strings.Join(strings.Fields(s), " "))
+	for ja, en := range aliases {
+		if expr == ja || strings.HasPrefix(expr, ja+" ") {
+			expr = en + expr[len(ja):]
+			break
+		}
+	}
+	if expr == "" {
+		return Range{}, fmt.Errorf("empty time expression")
+	}
+	if expr == "now" {
+		return instant(now), nil
+	}
+	if r, ok, err := parseDay(expr, now, loc); ok {
+		return r, err
+	}
+	if m := clockPattern.FindStringSubmatch(expr); m != nil {
+		return atClock(today(now), m)
+	}
+	if m := offsetPattern.FindStringSubmatch(expr); m != nil {
+		t := now
+		for _, part := range offsetPart.FindAllStringSubmatch(m[2], -1) {
+			n, _ := strconv.Atoi(part[1])
+			if m[1] == "-" {
+				n = -n
+			}
+			t = shift(t, n, part[2])
+		}
+		return instant(t), nil
+	}
+	if m := agoPattern.FindStringSubmatch(expr); m != nil {
+		unit, ok := agoUnits[m[2]]
+		if !ok {
+			return Range{}, fmt.Errorf("unknown unit %q in %q", m[2], s)
+		}
+		n, _ := strconv.Atoi(m[1])
+		return instant(shift(now, -n, unit)), nil
+	}
+	if m := relativePattern.FindStringSubmatch(expr); m != nil {
+		delta := map[string]int{"last": -1, "this": 0, "next": 1}[m[1]]
+		return relative(now, m[2], delta), nil
+	}
+	if m := quarterPattern.FindStringSubmatch(expr); m != nil {
+		year, _ := strconv.Atoi(m[1])
+		q, _ := strconv.Atoi(m[2])
+		start := time.Date(year, time.Month(3*(q-1)+1), 1, 0, 0, 0, 0, loc)
+		return period(start, start.AddDate(0, 3, 0)), nil
+	}
+	if t, err := time.ParseInLocation("2006", expr, loc); err == nil {
+		return period(t, t.AddDate(1, 0, 0)), nil
+	}
+	if t, err := time.ParseInLocation("2006-01", expr, loc); err == nil {
+		return period(t, t.AddDate(0, 1, 0)), nil
+	}
+	for _, layout := range []string{"2006-01-02", "2006/01/02"} {
+		if t, err := time.ParseInLocation(layout, expr, loc); err == nil {
+			return period(t, t.AddDate(0, 0, 1)), nil
+		}
+	}
+	// 大文字小文字を区別する形式 (T, Z) は元の文字列で試す
+	raw := strings.TrimSpace(s)
+	for _, layout := range zonedLayouts {
+		if t, err := time.Parse(layout, raw); err == nil {
+			return instant(t.In(loc)), nil
+		}
+	}
+	for _, layout := range localLayouts {
+		if t, err := time.ParseInLocation(layout, raw, loc); err == nil {
+			return instant(t), nil
+		}
+	}
+	return Range{}, fmt.Errorf("unrecognized time expression: %q", s)
+}
+
+// parseDay は today / yesterday / tomorrow と、その後ろに時刻の付いたものを解釈する。
+func parseDay(expr string, now time.Time, loc *time.Location) (Range, bool, error) {
+	word, clock, _ := strings.Cut(expr, " ")
+	var day time.Time
+	switch word {
+	case "today":
+		day = today(now)
+	case "yesterday":
+		day = today(now).AddDate(0, 0, -1)
+	case "tomorrow":
+		day = today(now).AddDate(0, 0, 1)
+	default:
+		return Range{}, false, nil
+	}
+	if clock == "" {
+		return period(day, day.AddDate(0, 0, 1)), true, nil
+	}
+	m := clockPattern.FindStringSubmatch(clock)
+	if m == nil {
+		return Range{}, true, fmt.Errorf("invalid time %q in %q", clock, expr)
+	}
+	r, err := atClock(day, m)
+	return r, true, err
+}
+
+func today(now time.Time) time.Time {
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+}
+
+// atClock は day の clockPattern に一致した時刻を返す。
+func atClock(day time.Time, m []string) (Range, error) {
+	hour, _ := strconv.Atoi(m[1])
+	minute, _ := strconv.Atoi(m[2])
+	second := 0
+	if m[3] != "" {
+		second, _ = strconv.Atoi(m[3])
+	}
+	if hour > 23 || minute > 59 || second > 59 {
+		return Range{}, fmt.Errorf("invalid time %q", m[0])
+	}
+	return instant(time.Date(day.Year(), day.Month(), day.Day(), hour, minute, second, 0, day.Location())), nil
+}
+
+// shift は t を n 単位ずらす。日以上は暦で数えるので、夏時間の切り替えを挟んでも同じ時刻になる。
+func shift(t time.Time, n int, unit string) time.Time {
+	switch unit {
+	case "s":
+		return t.Add(time.Duration(n) * time.Second)
+	case "m":
+		return t.Add(time.Duration(n) * time.Minute)
+	case "h":
+		return t.Add(time.Duration(n) * time.Hour)
+	case "d":
+		return t.AddDate(0, 0, n)
+	case "w":
+		return t.AddDate(0, 0, 7*n)
+	case "mo":
+		return t.AddDate(0, n, 0)
+	case "y":
+		return t.AddDate(n, 0, 0)
+	}
+	return t
+}
+
+// relative は now を含む週・月・四半期・年から delta 個ずらした期間を返す。週は月曜始まり。
+func relative(now time.Time, unit string, delta int) Range {
+	day := today(now)
+	switch unit {
+	case "week":
+		start := day.AddDate(0, 0, -(int(day.Weekday())+6)%7+7*delta)
+		return period(start, start.AddDate(0, 0, 7))
+	case "month":
+		start := time.Date(day.Year(), day.Month()+time.Month(delta), 1, 0, 0, 0, 0, day.Location())
+		return period(start, start.AddDate(0, 1, 0))
+	case "quarter":
+		q := (int(day.Month()) - 1) / 3
+		start := time.Date(day.Year(), time.Month(3*(q+delta)+1), 1, 0, 0, 0, 0, day.Location())
+		return period(start, start.AddDate(0, 3, 0))
+	}
+	start := time.Date(day.Year()+delta, 1, 1, 0, 0, 0, 0, day.Location())
+	return period(start, start.AddDate(1, 0, 0))
+}
@@ -0,0 +1,76 @@
+package timeexpr
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	jst := time.FixedZone("JST", 9*60*60)
+	// 2024-05-15 (水) 12:34:56 JST
+	now := time.Date(2024, 5, 15, 12, 34, 56, 0, jst)
+	at := func(y int, mo time.Month, d, h, mi, s int) time.Time {
+		return time.Date(y, mo, d, h, mi, s, 0, jst)
+	}
+	day := func(y int, mo time.Month, d int) time.Time { return at(y, mo, d, 0, 0, 0) }
+	last := func(t time.Time) time.Time { return t.Add(-time.Nanosecond) }
+
+	cases := []struct {
+		in         string
+		start, end time.Time
+	}{
+		{"now", now, now},
+		{"today", day(2024, 5, 15), last(day(2024, 5, 16))},
+		{"yesterday", day(2024, 5, 14), last(day(2024, 5, 15))},
+		{"yesterday 23:10", at(2024, 5, 14, 23, 10, 0), at(2024, 5, 14, 23, 10, 0)},
+		{"昨日 23:10", at(2024, 5, 14, 23, 10, 0), at(2024, 5, 14, 23, 10, 0)},
+		{"8:05:30", at(2024, 5, 15, 8, 5, 30), at(2024, 5, 15, 8, 5, 30)},
+		{"-30m", at(2024, 5, 15, 12, 4, 56), at(2024, 5, 15, 12, 4, 56)},
+		{"-1h30m", at(2024, 5, 15, 11, 4, 56), at(2024, 5, 15, 11, 4, 56)},
+		{"-2d", at(2024, 5, 13, 12, 34, 56), at(2024, 5, 13, 12, 34, 56)},
+		{"30 minutes ago", at(2024, 5, 15, 12, 4, 56), at(2024, 5, 15, 12, 4, 56)},
+		{"1 month ago", at(2024, 4, 15, 12, 34, 56), at(2024, 4, 15, 12, 34, 56)},
+		{"last week", day(2024, 5, 6), last(day(2024, 5, 13))},
+		{"this week", day(2024, 5, 13), last(day(2024, 5, 20))},
+		{"先月", day(2024, 4, 1), last(day(2024, 5, 1))},
+		{"last quarter", day(2024, 1, 1), last(day(2024, 4, 1))},
+		{"Last  Year", day(2023, 1, 1), last(day(2024, 1, 1))},
+		{"2024-05-01", day(2024, 5, 1), last(day(2024, 5, 2))},
+		{"2024-02", day(2024, 2, 1), last(day(2024, 3, 1))},
+		{"2024", day(2024, 1, 1), last(day(2025, 1, 1))},
+		{"2024-Q1", day(2024, 1, 1), last(day(2024, 4, 1))},
+		{"2023q4", day(2023, 10, 1), last(day(2024, 1, 1))},
+		{"2024-05-01 23:10", at(2024, 5, 1, 23, 10, 0), at(2024, 5, 1, 23, 10, 0)},
+		{"2024-05-01T14:10:00Z", at(2024, 5, 1, 23, 10, 0), at(2024, 5, 1, 23, 10, 0)},
+		{"2024-05-01T23:10:00+0900", at(2024, 5, 1, 23, 10, 0), at(2024, 5, 1, 23, 10, 0)},
+	}
+	for _, c := range cases {
+		r, err := Parse(c.in, now, jst)
+		if err != nil {
+			t.Errorf("%q: %v", c.in, err)
+			continue
+		}
+		if !r.Start.Equal(c.start) || !r.End.Equal(c.end) {
+			t.Errorf("%q = %v .. %v, want %v .. %v", c.in, r.Start, r.End, c.start, c.end)
+		}
+	}
+
+	for _, in := range []string{"", "soon", "yesterday 25:00", "3 fortnights ago", "2024-13"} {
+		if _, err := Parse(in, now, jst); err == nil {
+			t.Errorf("%q: expected error", in)
+		}
+	}
+}
+
+func TestParse_Location(t *testing.T) {
+	// 日付の境界は loc で決まる
+	now := time.Date(2024, 5, 15, 20, 0, 0, 0, time.UTC)
+	tokyo := time.FixedZone("JST", 9*60*60)
+	r, err := Parse("today", now, tokyo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := time.Date(2024, 5, 16, 0, 0, 0, 0, tokyo); !r.Start.Equal(want) {
+		t.Errorf("start = %v, want %v", r.Start, want)
+	}
+}
@@ -0,0 +1,79 @@
+package go_tissue
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+)
+
+// TimestampLayout は Tissue が日時の送受信に使う形式 (例: 2020-07-21T19:19:19+0900)。
+const TimestampLayout = "2006-01-02T15:04:05-0700"
+
+var timestampLayouts = []string{
+	time.RFC3339Nano,
+	TimestampLayout,
+	"2006-01-02T15:04:05.999999999-0700",
+}
+
+// Timestamp は Tissue の日時表現。RFC3339 とコロンなしのオフセット (+0900) の両方を受け付け、
+// null はゼロ値として扱う。エンコード時は TimestampLayout を使い、ゼロ値は null になる。
+type Timestamp struct {
+	time.Time
+}
+
+// NewTimestamp は t を Timestamp に包んだポインタを返す。リクエストオプションの指定に使う。
+func NewTimestamp(t time.Time) *Timestamp {
+	return &Timestamp{Time: t}
+}
+
+// ParseTimestamp は Timestamp が受け付けるいずれかの形式の文字列を解釈する。
+func ParseTimestamp(s string) (Timestamp, error) {
+	for _, layout := range timestampLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return Timestamp{Time: t}, nil
+		}
+	}
+	return Timestamp{}, fmt.Errorf("invalid timestamp: %q", s)
+}
+
+// UnmarshalText は ParseTimestamp と同じ形式を受け付け、空文字列はゼロ値にする。
+// time.Time から昇格したものを上書きするので、encoding.TextUnmarshaler として使われてもコロンなしのオフセットを読める。
+func (t *Timestamp) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		*t = Timestamp{}
+		return nil
+	}
+	parsed, err := ParseTimestamp(string(text))
+	if err != nil {
+		return err
+	}
+	*t = parsed
+	return nil
+}
+
+// MarshalText は TimestampLayout で書き出す。ゼロ値は空文字列になる。
+func (t Timestamp) MarshalText() ([]byte, error) {
+	if t.IsZero() {
+		return []byte{}, nil
+	}
+	return []byte(t.Format(TimestampLayout)), nil
+}
+
+func (t *Timestamp) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte("null")) {
+		*t = Timestamp{}
+		return nil
+	}
+	if len(data) < 2 || data[0] != '"' || data[len(data)-1] != '"' {
+		return fmt.Errorf("invalid timestamp: %s", data)
+	}
+	return t.UnmarshalText(data[1 : len(data)-1])
+}
+
+func (t Timestamp) MarshalJSON() ([]byte, error) {
+	if t.IsZero() {
+		return []byte("null"), nil
+	}
+	text, _ := t.MarshalText()
+	return []byte(`"` + string(text) + `"`), nil
+}
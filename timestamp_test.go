@@ -0,0 +1,87 @@
+package go_tissue
+
+import (
+	"encoding/json"
+	"flag"
+	"testing"
+	"time"
+)
+
+func TestTimestamp_UnmarshalJSON(t *testing.T) {
+	jst := time.FixedZone("", 9*60*60)
+	want := time.Date(2020, 7, 21, 19, 19, 19, 0, jst)
+	for _, in := range []string{
+		`"2020-07-21T19:19:19+0900"`,
+		`"2020-07-21T19:19:19+09:00"`,
+		`"2020-07-21T10:19:19Z"`,
+		`"2020-07-21T19:19:19.000000+0900"`,
+	} {
+		var ts Timestamp
+		if err := json.Unmarshal([]byte(in), &ts); err != nil {
+			t.Errorf("%s: %v", in, err)
+			continue
+		}
+		if !ts.Equal(want) {
+			t.Errorf("%s: got %v", in, ts.Time)
+		}
+	}
+
+	var c Checkin
+	if err := json.Unmarshal([]byte(`{"checked_in_at":null}`), &c); err != nil {
+		t.Fatal(err)
+	}
+	if !c.CheckedInAt.IsZero() {
+		t.Errorf("null should decode to zero: %v", c.CheckedInAt)
+	}
+
+	var ts Timestamp
+	if err := json.Unmarshal([]byte(`"yesterday"`), &ts); err == nil {
+		t.Error("expected error for invalid timestamp")
+	}
+}
+
+func TestTimestamp_MarshalJSON(t *testing.T) {
+	jst := time.FixedZone("", 9*60*60)
+	b, err := json.Marshal(&CreateCheckinOption{CheckedInAt: NewTimestamp(time.Date(2020, 7, 21, 19, 19, 19, 0, jst))})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var m map[string]interface{}
+	if err := json.Unmarshal(b, &m); err != nil {
+		t.Fatal(err)
+	}
+	if m["checked_in_at"] != "2020-07-21T19:19:19+0900" {
+		t.Errorf("unexpected encoding: %s", b)
+	}
+
+	b, err = json.Marshal(UserCheckin{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(b, &m); err != nil {
+		t.Fatal(err)
+	}
+	if m["previous_checked_in_at"] != nil {
+		t.Errorf("zero timestamp should encode to null: %s", b)
+	}
+}
+
+func TestTimestamp_Text(t *testing.T) {
+	// flag.TextVar などの encoding.TextUnmarshaler 経由でもコロンなしのオフセットを受け付ける
+	var ts Timestamp
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.TextVar(&ts, "at", Timestamp{}, "")
+	if err := fs.Parse([]string{"-at", "2020-07-21T19:19:19+0900"}); err != nil {
+		t.Fatal(err)
+	}
+	text, err := ts.MarshalText()
+	if err != nil || string(text) != "2020-07-21T19:19:19+0900" {
+		t.Errorf("MarshalText = %q, %v", text, err)
+	}
+	if text, _ := (Timestamp{}).MarshalText(); len(text) != 0 {
+		t.Errorf("zero timestamp should encode to empty text: %q", text)
+	}
+	if err := ts.UnmarshalText([]byte("yesterday")); err == nil {
+		t.Error("expected error for invalid timestamp")
+	}
+}
@@ -1,7 +1,5 @@
 package go_tissue
 
-import "time"
-
 // NumericString は JSON の文字列・数値のどちらとしてエンコードされていても
 // 受け入れ可能な数値表現。値は文字列として保持される。
 type NumericString string
@@ -59,7 +57,7 @@ type Information struct {
 	Category  string    `json:"category"`
 	Pinned    bool      `json:"pinned"`
 	Title     string    `json:"title"`
-	CreatedAt time.Time `json:"created_at"`
+	CreatedAt Timestamp `json:"created_at"`
 }
 
 type DailyCheckinCount struct {
@@ -74,7 +72,7 @@ type TagCount struct {
 
 type Checkin struct {
 	ID                 int64     `json:"id"`
-	CheckedInAt        time.Time `json:"checked_in_at"`
+	CheckedInAt        Timestamp `json:"checked_in_at"`
 	Note               string    `json:"note"`
 	Link               string    `json:"link"`
 	Tags               []string  `json:"tags"`
@@ -90,8 +88,8 @@ type Checkin struct {
 
 type UserCheckin struct {
 	Checkin
-	CheckinInterval     int64  `json:"checkin_interval"`
-	PreviousCheckedInAt string `json:"previous_checked_in_at"`
+	CheckinInterval     int64     `json:"checkin_interval"`
+	PreviousCheckedInAt Timestamp `json:"previous_checked_in_at"`
 }
 
 type Collection struct {
@@ -101,7 +99,7 @@ type Collection struct {
 	User      User      `json:"user"`
 	Title     string    `json:"title"`
 	IsPrivate bool      `json:"is_private"`
-	UpdatedAt time.Time `json:"updated_at"`
+	UpdatedAt Timestamp `json:"updated_at"`
 }
 
 type CollectionItem struct {
@@ -10,6 +10,8 @@ type UserCheckinsOption struct {
 	Page    int
 	PerPage int
 	HasLink *bool
+	// Unfiltered が true なら ClientOption.Filter を適用しない。
+	Unfiltered bool
 }
 
 func (c *Client) UserCheckins(ctx context.Context, user string, option *UserCheckinsOption) ([]UserCheckin, error) {
@@ -29,5 +31,8 @@ func (c *Client) UserCheckins(ctx context.Context, user string, option *UserChec
 	if err := c.getJSON(ctx, "/api/users/"+user+"/checkins", query, &result); err != nil {
 		return nil, err
 	}
-	return result, nil
+	if option != nil && option.Unfiltered {
+		return result, nil
+	}
+	return filterUserCheckins(c.option.Filter, result), nil
 }
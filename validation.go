@@ -0,0 +1,233 @@
+package go_tissue
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// API 仕様 (doc/openapi.json) 上の入力の上限。
+const (
+	MaxNoteLength          = 500
+	MaxLinkLength          = 2000
+	MaxTagLength           = 255
+	MaxTitleLength         = 255
+	MaxCollectionItemTags  = 40
+	defaultValidationError = "The given data was invalid."
+)
+
+// Violation は1つのフィールドの入力エラー。
+type Violation struct {
+	Message string `json:"message"`
+	Field   string `json:"field"`
+}
+
+// ValidationError はサーバーの ValidationError と同じ形の入力エラー。
+// 各オプションの Validate() が返すほか、サーバーの 422 応答も StatusError.ValidationError() でこの形にできる。
+type ValidationError struct {
+	Message    string      `json:"message"`
+	Violations []Violation `json:"violations,omitempty"`
+}
+
+func (e *ValidationError) Error() string {
+	if len(e.Violations) == 0 {
+		return e.Message
+	}
+	messages := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		messages[i] = v.Message
+	}
+	return e.Message + " (" + strings.Join(messages, "; ") + ")"
+}
+
+// Validator は Violation を集める。各オプションの Validate() はこれで API 仕様の制約を満たしているかを調べ、
+// 違反があれば *ValidationError を返す。
+type Validator struct {
+	Violations []Violation
+}
+
+func (v *Validator) add(field, format string, args ...interface{}) {
+	v.Violations = append(v.Violations, Violation{Message: fmt.Sprintf(format, args...), Field: field})
+}
+
+// Required は value が空ならエラーにする。
+func (v *Validator) Required(field, value string) {
+	if strings.TrimSpace(value) == "" {
+		v.add(field, "The %s field is required.", field)
+	}
+}
+
+// MaxLength は value が max 文字を超えていたらエラーにする。
+func (v *Validator) MaxLength(field, value string, max int) {
+	if utf8.RuneCountInString(value) > max {
+		v.add(field, "The %s may not be greater than %d characters.", field, max)
+	}
+}
+
+// Link は value が http / https の URL でないか、MaxLinkLength を超えていたらエラーにする。空は許す。
+func (v *Validator) Link(field, value string) {
+	if value == "" {
+		return
+	}
+	v.MaxLength(field, value, MaxLinkLength)
+	u, err := url.Parse(value)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		v.add(field, "The %s format is invalid (http or https URL).", field)
+	}
+}
+
+// Tags はタグごとの長さと、maxItems (0 なら制限なし) を超える数をエラーにする。
+func (v *Validator) Tags(field string, tags []string, maxItems int) {
+	if maxItems > 0 && len(tags) > maxItems {
+		v.add(field, "The %s may not have more than %d items.", field, maxItems)
+	}
+	for i, t := range tags {
+		v.MaxLength(field+"."+strconv.Itoa(i), t, MaxTagLength)
+	}
+}
+
+// NotFuture は t が未来の日時ならエラーにする。nil は許す。
+func (v *Validator) NotFuture(field string, t *Timestamp) {
+	if t != nil && t.After(time.Now()) {
+		v.add(field, "The %s must be a date before or equal to now.", field)
+	}
+}
+
+// Err は集めた Violation があれば *ValidationError を、無ければ nil を返す。
+func (v *Validator) Err() error {
+	if len(v.Violations) == 0 {
+		return nil
+	}
+	return &ValidationError{Message: defaultValidationError, Violations: v.Violations}
+}
+
+// ValidationError は 422 応答の本文を *ValidationError にする。422 でないか、読めない形なら nil。
+// API の {"status": 422, "error": {...}}、ValidationError そのもの、Laravel の {"message", "errors": {field: [...]}} を読める。
+func (e *StatusError) ValidationError() *ValidationError {
+	if e.StatusCode != http.StatusUnprocessableEntity {
+		return nil
+	}
+	type body struct {
+		Message    string              `json:"message"`
+		Violations []json.RawMessage   `json:"violations"`
+		Errors     map[string][]string `json:"errors"`
+	}
+	var wrapped struct {
+		body
+		Error *body `json:"error"`
+	}
+	if err := json.Unmarshal([]byte(e.Body), &wrapped); err != nil {
+		return nil
+	}
+	b := wrapped.body
+	if wrapped.Error != nil {
+		b = *wrapped.Error
+	}
+	if b.Message == "" && len(b.Violations) == 0 && len(b.Errors) == 0 {
+		return nil
+	}
+	result := &ValidationError{Message: b.Message}
+	for _, raw := range b.Violations {
+		var v Violation
+		if err := json.Unmarshal(raw, &v); err != nil {
+			// 本文が文字列だけのもの
+			_ = json.Unmarshal(raw, &v.Message)
+		}
+		result.Violations = append(result.Violations, v)
+	}
+	for field, messages := range b.Errors {
+		for _, m := range messages {
+			result.Violations = append(result.Violations, Violation{Message: m, Field: field})
+		}
+	}
+	return result
+}
+
+// AsValidationError は err が Validate() の結果か 422 応答なら、その *ValidationError を返す。
+func AsValidationError(err error) (*ValidationError, bool) {
+	var ve *ValidationError
+	if errors.As(err, &ve) {
+		return ve, true
+	}
+	var se *StatusError
+	if errors.As(err, &se) {
+		if ve := se.ValidationError(); ve != nil {
+			return ve, true
+		}
+	}
+	return nil, false
+}
+
+func (o *CreateCheckinOption) Validate() error {
+	v := &Validator{}
+	v.NotFuture("checked_in_at", o.CheckedInAt)
+	v.Tags("tags", o.Tags, 0)
+	v.Link("link", o.Link)
+	v.MaxLength("note", o.Note, MaxNoteLength)
+	return v.Err()
+}
+
+func (o *UpdateCheckinOption) Validate() error {
+	v := &Validator{}
+	v.NotFuture("checked_in_at", o.CheckedInAt)
+	if o.Tags != nil {
+		v.Tags("tags", *o.Tags, 0)
+	}
+	if o.Link != nil {
+		v.Link("link", *o.Link)
+	}
+	if o.Note != nil {
+		v.MaxLength("note", *o.Note, MaxNoteLength)
+	}
+	return v.Err()
+}
+
+func (o *CreateCollectionOption) Validate() error {
+	v := &Validator{}
+	v.Required("title", o.Title)
+	v.MaxLength("title", o.Title, MaxTitleLength)
+	return v.Err()
+}
+
+// Validate はタイトルを必須とする。更新は PUT なので、変えないときも送り直す必要がある。
+func (o *UpdateCollectionOption) Validate() error {
+	v := &Validator{}
+	v.Required("title", o.Title)
+	v.MaxLength("title", o.Title, MaxTitleLength)
+	return v.Err()
+}
+
+// Validate はリンクを必須とし、タグは MaxCollectionItemTags 個までにする。
+func (o *CreateCollectionItemOption) Validate() error {
+	v := &Validator{}
+	v.Required("link", o.Link)
+	v.Link("link", o.Link)
+	v.MaxLength("note", o.Note, MaxNoteLength)
+	v.Tags("tags", o.Tags, MaxCollectionItemTags)
+	return v.Err()
+}
+
+func (o *UpdateCollectionItemOption) Validate() error {
+	v := &Validator{}
+	if o.Note != nil {
+		v.MaxLength("note", *o.Note, MaxNoteLength)
+	}
+	if o.Tags != nil {
+		v.Tags("tags", *o.Tags, MaxCollectionItemTags)
+	}
+	return v.Err()
+}
+
+// validate は SkipValidation でなければ o.Validate() を呼ぶ。
+func (c *Client) validate(o interface{ Validate() error }) error {
+	if c.option.SkipValidation {
+		return nil
+	}
+	return o.Validate()
+}
@@ -0,0 +1,123 @@
+package go_tissue
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func violationFields(err error) []string {
+	var ve *ValidationError
+	if !errors.As(err, &ve) {
+		return nil
+	}
+	fields := make([]string, len(ve.Violations))
+	for i, v := range ve.Violations {
+		fields[i] = v.Field
+	}
+	return fields
+}
+
+func TestCreateCheckinOption_Validate(t *testing.T) {
+	ok := &CreateCheckinOption{Link: "https://example.com/", Note: strings.Repeat("あ", MaxNoteLength), Tags: []string{"a"}}
+	if err := ok.Validate(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	future := NewTimestamp(time.Now().Add(time.Hour))
+	ng := &CreateCheckinOption{
+		CheckedInAt: future,
+		Link:        "ftp://example.com/",
+		Note:        strings.Repeat("a", MaxNoteLength+1),
+		Tags:        []string{"ok", strings.Repeat("t", MaxTagLength+1)},
+	}
+	got := strings.Join(violationFields(ng.Validate()), ",")
+	if want := "checked_in_at,tags.1,link,note"; got != want {
+		t.Errorf("fields = %s, want %s", got, want)
+	}
+}
+
+func TestUpdateOptions_Validate(t *testing.T) {
+	long := strings.Repeat("a", MaxLinkLength)
+	link := "https://example.com/" + long
+	if got := strings.Join(violationFields((&UpdateCheckinOption{Link: &link}).Validate()), ","); got != "link" {
+		t.Errorf("fields = %s", got)
+	}
+	if err := (&UpdateCheckinOption{}).Validate(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if got := strings.Join(violationFields((&UpdateCollectionOption{ID: 1}).Validate()), ","); got != "title" {
+		t.Errorf("fields = %s", got)
+	}
+	tags := make([]string, MaxCollectionItemTags+1)
+	for i := range tags {
+		tags[i] = "t"
+	}
+	if got := strings.Join(violationFields((&UpdateCollectionItemOption{Tags: &tags}).Validate()), ","); got != "tags" {
+		t.Errorf("fields = %s", got)
+	}
+	if got := strings.Join(violationFields((&CreateCollectionItemOption{CollectionID: 1}).Validate()), ","); got != "link" {
+		t.Errorf("fields = %s", got)
+	}
+}
+
+func TestStatusError_ValidationError(t *testing.T) {
+	cases := map[string]struct {
+		body   string
+		fields []string
+	}{
+		"api":     {`{"status":422,"error":{"message":"invalid","violations":["The link format is invalid."]}}`, []string{""}},
+		"schema":  {`{"message":"invalid","violations":[{"message":"too long","field":"note"}]}`, []string{"note"}},
+		"laravel": {`{"message":"invalid","errors":{"title":["required"]}}`, []string{"title"}},
+	}
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			err := error(&StatusError{StatusCode: http.StatusUnprocessableEntity, Status: "422", Body: c.body})
+			ve, ok := AsValidationError(err)
+			if !ok || ve.Message != "invalid" || len(ve.Violations) != len(c.fields) {
+				t.Fatalf("unexpected result: %+v", ve)
+			}
+			for i, f := range c.fields {
+				if ve.Violations[i].Field != f || ve.Violations[i].Message == "" {
+					t.Errorf("violation %d: %+v", i, ve.Violations[i])
+				}
+			}
+		})
+	}
+	if _, ok := AsValidationError(&StatusError{StatusCode: http.StatusInternalServerError, Body: `{"message":"x"}`}); ok {
+		t.Error("500 should not be a validation error")
+	}
+}
+
+func TestClient_ValidateBeforeRequest(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusUnprocessableEntity)
+	}))
+	defer server.Close()
+	ctx := context.Background()
+
+	client, err := NewClient(&ClientOption{BaseURL: server.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = client.CreateCollection(ctx, &CreateCollectionOption{})
+	if _, ok := err.(*ValidationError); !ok || requests != 0 {
+		t.Fatalf("err = %v, requests = %d", err, requests)
+	}
+
+	client, err = NewClient(&ClientOption{BaseURL: server.URL, SkipValidation: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 検証を省くとそのまま送られる
+	_, err = client.CreateCollection(ctx, &CreateCollectionOption{})
+	if _, ok := err.(*ValidationError); ok || requests == 0 {
+		t.Fatalf("err = %v, requests = %d", err, requests)
+	}
+}
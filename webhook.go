@@ -0,0 +1,76 @@
+package go_tissue
+
+import (
+	"context"
+	"errors"
+	"html"
+	"net/http"
+	"net/url"
+	"regexp"
+)
+
+// Webhook はチェックイン用の Incoming Webhook。URL に POST すると発行したユーザーでチェックインされる。
+type Webhook struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	URL  string `json:"url"`
+}
+
+var webhookURLPattern = regexp.MustCompile(`https?://[^"'<>\s]*/webhooks/checkin/([A-Za-z0-9_-]+)`)
+
+// ListWebhooks は設定ページから Webhook の一覧を取得する。
+func (c *Client) ListWebhooks(ctx context.Context) ([]Webhook, error) {
+	page, err := c.getPage(ctx, "/setting/webhooks")
+	if err != nil {
+		return nil, err
+	}
+	return parseWebhooks(page), nil
+}
+
+// CreateWebhook は name という名前の Webhook を発行して返す。
+func (c *Client) CreateWebhook(ctx context.Context, name string) (*Webhook, error) {
+	if name == "" {
+		return nil, errors.New("name is required")
+	}
+	before, err := c.ListWebhooks(ctx)
+	if err != nil {
+		return nil, err
+	}
+	exists := map[string]bool{}
+	for _, w := range before {
+		exists[w.ID] = true
+	}
+	page, err := c.submitForm(ctx, http.MethodPost, "/setting/webhooks", url.Values{"name": {name}})
+	if err != nil {
+		return nil, err
+	}
+	for _, w := range parseWebhooks(page) {
+		if !exists[w.ID] {
+			return &w, nil
+		}
+	}
+	return nil, errors.New("created webhook not found in the settings page (the limit may have been reached)")
+}
+
+// DeleteWebhook は Webhook を削除する。
+func (c *Client) DeleteWebhook(ctx context.Context, id string) error {
+	if id == "" {
+		return errors.New("id is required")
+	}
+	_, err := c.submitForm(ctx, http.MethodDelete, "/setting/webhooks/"+url.PathEscape(id), nil)
+	return err
+}
+
+func parseWebhooks(page string) []Webhook {
+	names := dataNames(page)
+	webhooks := []Webhook{}
+	seen := map[string]bool{}
+	for _, m := range webhookURLPattern.FindAllStringSubmatch(page, -1) {
+		if seen[m[1]] {
+			continue
+		}
+		seen[m[1]] = true
+		webhooks = append(webhooks, Webhook{ID: m[1], Name: names[m[1]], URL: html.UnescapeString(m[0])})
+	}
+	return webhooks
+}
@@ -0,0 +1,86 @@
+package go_tissue
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// serveWebhooks は Webhook の設定画面を f に足す。
+func serveWebhooks(f *fakeTissue) {
+	var mu sync.Mutex
+	var webhooks []string
+	f.handle("/setting/webhooks", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			if r.Header.Get("X-XSRF-TOKEN") != "xsrf/token" {
+				http.Error(w, "CSRF token mismatch", 419)
+				return
+			}
+			mu.Lock()
+			webhooks = append(webhooks, fmt.Sprintf("hook%d:%s", len(webhooks)+1, r.FormValue("name")))
+			mu.Unlock()
+			http.Redirect(w, r, "/setting/webhooks", http.StatusFound)
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		for _, hook := range webhooks {
+			id, name, _ := strings.Cut(hook, ":")
+			fmt.Fprintf(w, `<input value="%s/webhooks/checkin/%s" readonly><button data-id="%s" data-name="%s">削除</button>`,
+				f.URL, id, id, html.EscapeString(name))
+		}
+	})
+	f.handle("/setting/webhooks/", func(w http.ResponseWriter, r *http.Request) {
+		id := strings.TrimPrefix(r.URL.Path, "/setting/webhooks/")
+		mu.Lock()
+		defer mu.Unlock()
+		for i, hook := range webhooks {
+			if strings.HasPrefix(hook, id+":") && r.Method == http.MethodDelete {
+				webhooks = append(webhooks[:i], webhooks[i+1:]...)
+				http.Redirect(w, r, "/setting/webhooks", http.StatusFound)
+				return
+			}
+		}
+		http.NotFound(w, r)
+	})
+}
+
+func TestClient_Webhooks(t *testing.T) {
+	f := newFakeTissue(t)
+	serveWebhooks(f)
+	client := newFakeClient(t, f, "secret")
+	ctx := context.Background()
+
+	first, err := client.CreateWebhook(ctx, "phone")
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := client.CreateWebhook(ctx, "pc & tablet")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.ID != "hook1" || first.Name != "phone" || first.URL != f.URL+"/webhooks/checkin/hook1" {
+		t.Errorf("unexpected webhook: %+v", first)
+	}
+	if second.ID != "hook2" || second.Name != "pc & tablet" {
+		t.Errorf("unexpected webhook: %+v", second)
+	}
+
+	if err := client.DeleteWebhook(ctx, first.ID); err != nil {
+		t.Fatal(err)
+	}
+	webhooks, err := client.ListWebhooks(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(webhooks) != 1 || webhooks[0] != *second {
+		t.Errorf("unexpected webhooks: %+v", webhooks)
+	}
+	if err := client.DeleteWebhook(ctx, "missing"); !IsNotFound(err) {
+		t.Errorf("expected not found, got %v", err)
+	}
+}